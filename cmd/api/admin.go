@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+)
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// handleGetLogLevel returns the current minimum log level
+func handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, LogLevelResponse{Level: strings.ToLower(logger.GetLevel().String())})
+}
+
+// handleSetLogLevel changes the minimum log level at runtime
+func handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &apperror.ValidationError{Field: "body", Message: "invalid JSON"}
+		http.Error(w, err.Error(), apperror.HTTPStatusCode(err))
+		return
+	}
+	if err := logger.SetLevel(req.Level); err != nil {
+		err = &apperror.ValidationError{Field: "level", Message: err.Error()}
+		http.Error(w, err.Error(), apperror.HTTPStatusCode(err))
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Log level changed to %s", logger.GetLevel())
+	writeJSON(w, LogLevelResponse{Level: strings.ToLower(logger.GetLevel().String())})
+}
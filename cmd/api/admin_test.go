@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/pkg/twitterx"
+)
+
+func TestAdminEndpointsNeedAPIKeys(t *testing.T) {
+	client, err := twitterx.New()
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	router := mux.NewRouter()
+	registerAPIRoutes(router.PathPrefix("/api").Subrouter(), client, nil, linkBase{}, trackers{})
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"level":"debug"}`)),
+		httptest.NewRequest(http.MethodGet, "/api/admin/usage", nil),
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s %s: expected 404 without API keys, got %d", r.Method, r.URL.Path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/config"
+)
+
+// newAuthenticator loads the API keys from the configuration. It returns
+// nil when no keys are configured, which leaves the API open.
+func newAuthenticator(cfg *config.Config) (*auth.Authenticator, error) {
+	var keys []auth.Key
+	if cfg.APIKeys != "" {
+		parsed, err := auth.ParseKeys([]byte(cfg.APIKeys))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, parsed...)
+	}
+	if cfg.APIKeysFile != "" {
+		loaded, err := auth.LoadKeysFile(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, loaded...)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	return auth.New(keys, auth.AnonymousTier{
+		Enabled:    cfg.AnonymousAccess,
+		Scopes:     []auth.Scope{auth.ScopeRead},
+		RateLimit:  cfg.AnonymousRateLimit,
+		DailyQuota: cfg.AnonymousDailyQuota,
+		TrustProxy: cfg.TrustProxy,
+	})
+}
+
+// makeUsageHandler returns the request counters of every API key
+func makeUsageHandler(authenticator *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, authenticator.Usage())
+	}
+}
+
+// clientKey identifies the client of a request for rate limiting, see
+// auth.ClientKey and auth.ClientIP
+func clientKey(trustProxy bool) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return auth.ClientKey(r.Context(), auth.ClientIP(r, trustProxy))
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"twitterx-api/internal/auth"
+)
+
+func TestClientKeyTrustsValidatedKeysOnly(t *testing.T) {
+	authenticator, err := auth.New([]auth.Key{{Name: "ops", Key: "secret", Scopes: []auth.Scope{auth.ScopeRead}}},
+		auth.AnonymousTier{Enabled: true, Scopes: []auth.Scope{auth.ScopeRead}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	var got string
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = clientKey(false)(r)
+	}))
+
+	for key, want := range map[string]string{"secret": "key:ops", "": "ip:192.0.2.1"} {
+		got = ""
+		r := httptest.NewRequest(http.MethodGet, "/api/users/jack", nil)
+		r.Header.Set("X-API-Key", key)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if got != want {
+			t.Fatalf("key %q: expected client %q, got %q", key, want, got)
+		}
+	}
+
+	// Without authentication a made-up key is just an anonymous request
+	r := httptest.NewRequest(http.MethodGet, "/api/users/jack", nil)
+	r.Header.Set("X-API-Key", "made-up")
+	if got := clientKey(false)(r); got != "ip:192.0.2.1" {
+		t.Fatalf("expected a made-up key to be keyed on the IP, got %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/logger"
+)
+
+// writeCachedJSON encodes v with a strong ETag and a Cache-Control max-age of
+// maxAge, answering 304 Not Modified when the client already has the same
+// representation. A non-zero lastModified is sent as Last-Modified.
+func writeCachedJSON(w http.ResponseWriter, r *http.Request, v any, maxAge time.Duration, lastModified time.Time) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		logger.ErrorContext(r.Context(), "Error encoding response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	writeCached(w, r, "application/json", &buf, maxAge, lastModified)
+}
+
+// writeCached writes body like writeCachedJSON, with any content type
+func writeCached(w http.ResponseWriter, r *http.Request, contentType string, body *bytes.Buffer, maxAge time.Duration, lastModified time.Time) {
+	etag := computeETag(body.Bytes())
+	header := w.Header()
+	header.Set("ETag", etag)
+	if credentialed(r) {
+		header.Set("Cache-Control", privateCacheControl(maxAge))
+		header.Add("Vary", "Authorization, X-API-Key")
+	} else {
+		header.Set("Cache-Control", cacheControl(maxAge))
+	}
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(body.Len()))
+	if _, err := body.WriteTo(w); err != nil {
+		logger.DebugContext(r.Context(), "Error writing response: %v", err)
+	}
+}
+
+// computeETag returns a strong entity tag over the encoded body
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// cacheControl returns the Cache-Control value for a resource cached for maxAge
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+	return "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
+
+// privateCacheControl is cacheControl for responses shared caches must not
+// store
+func privateCacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "private, no-cache"
+	}
+	return "private, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+}
+
+// credentialed reports whether r went through authentication or carried
+// credentials, so its response may depend on who asked
+func credentialed(r *http.Request) bool {
+	return auth.FromContext(r.Context()) != nil || auth.KeyFromRequest(r) != "" || r.Header.Get("Authorization") != ""
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since
+// when the client sent no entity tags
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have a one second resolution
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether the If-None-Match list contains etag, using
+// the weak comparison required for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/auth"
+)
+
+func TestCachedResponsesArePrivateWithCredentials(t *testing.T) {
+	authenticator, err := auth.New([]auth.Key{{Name: "ops", Key: "secret", Scopes: []auth.Scope{auth.ScopeRead}}},
+		auth.AnonymousTier{Enabled: true, Scopes: []auth.Scope{auth.ScopeRead}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeCachedJSON(w, r, map[string]string{"screen_name": "jack"}, time.Minute, time.Time{})
+	})
+
+	for _, tc := range []struct {
+		name    string
+		handler http.Handler
+		key     string
+		want    string
+		vary    string
+	}{
+		{"open", handler, "", "public, max-age=60", ""},
+		{"open with a key", handler, "secret", "private, max-age=60", "Authorization, X-API-Key"},
+		{"anonymous", authenticator.Middleware(handler), "", "private, max-age=60", "Authorization, X-API-Key"},
+		{"keyed", authenticator.Middleware(handler), "secret", "private, max-age=60", "Authorization, X-API-Key"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/users/jack", nil)
+		if tc.key != "" {
+			r.Header.Set("X-API-Key", tc.key)
+		}
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, r)
+		if got := rec.Header().Get("Cache-Control"); got != tc.want {
+			t.Fatalf("%s: expected Cache-Control %q, got %q", tc.name, tc.want, got)
+		}
+		if got := rec.Header().Get("Vary"); got != tc.vary {
+			t.Fatalf("%s: expected Vary %q, got %q", tc.name, tc.vary, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/config"
+	"twitterx-api/internal/embed"
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+// embedPolicy lets cards load images and inline styles, and nothing else
+const embedPolicy = "default-src 'none'; img-src https: http: data:; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'"
+
+// makeEmbedHandler serves GET /embed/{id}, a tweet rendered as a
+// standalone HTML card for iframes
+func makeEmbedHandler(client *twitterx.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		opts, err := embed.ParseOptions(r.URL.Query())
+		if err == nil && !opts.Fits() {
+			err = &apperror.ValidationError{Field: "maxwidth", Message: fmt.Sprintf("must be at least %d", embed.MinWidth)}
+		}
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+
+		tweet, err := client.GetTweet(r.Context(), "i", id)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error fetching tweet %s for embed: %v", id, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := embed.Render(&buf, tweet, opts); err != nil {
+			logger.ErrorContext(r.Context(), "Error rendering embed of tweet %s: %v", id, err)
+			http.Error(w, "Failed to render tweet", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", embedPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		writeCached(w, r, "text/html; charset=utf-8", &buf, client.TweetCacheTTL(), time.Time{})
+	}
+}
+
+// makeOEmbedHandler serves GET /api/oembed?url=, the oEmbed description of
+// a tweet link. Links in the response point to the base URL of links.
+func makeOEmbedHandler(client *twitterx.Client, links linkBase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if format := query.Get("format"); format != "" && format != "json" {
+			// Required by the oEmbed spec for unsupported formats
+			http.Error(w, "Only the json format is supported", http.StatusNotImplemented)
+			return
+		}
+		if query.Get("url") == "" {
+			apperror.WriteHTTPError(w, &apperror.ValidationError{Field: "url", Message: "required"})
+			return
+		}
+		ref, err := twitterx.ParseTweetRef(query.Get("url"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		opts, err := embed.ParseOptions(query)
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		if !opts.Fits() {
+			// Required by the oEmbed spec when no response fits in maxwidth
+			http.Error(w, fmt.Sprintf("Cards are at least %d pixels wide", embed.MinWidth), http.StatusNotImplemented)
+			return
+		}
+		maxHeight := 0
+		if value := query.Get("maxheight"); value != "" {
+			if maxHeight, err = strconv.Atoi(value); err != nil || maxHeight <= 0 {
+				apperror.WriteHTTPError(w, &apperror.ValidationError{Field: "maxheight", Message: "must be a positive integer"})
+				return
+			}
+		}
+
+		tweet, err := client.GetTweet(r.Context(), ref.Username, ref.ID)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error fetching tweet %s for oEmbed: %v", ref.ID, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+
+		baseURL, err := links.resolve(r)
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		ttl := client.TweetCacheTTL()
+		writeCachedJSON(w, r, embed.NewOEmbed(tweet, baseURL, opts, maxHeight, ttl), ttl, time.Time{})
+	}
+}
+
+// linkBase builds the base URL of the absolute links in responses
+type linkBase struct {
+	// publicURL is used when set
+	publicURL string
+	// allowedHosts are the request hosts links may point to otherwise,
+	// besides loopback ones
+	allowedHosts []string
+	// trustProxy takes the scheme from X-Forwarded-Proto
+	trustProxy bool
+}
+
+func newLinkBase(cfg *config.Config) linkBase {
+	return linkBase{publicURL: cfg.PublicURL, allowedHosts: cfg.AllowedHosts, trustProxy: cfg.TrustProxy}
+}
+
+// loopbackOnly reports whether links can only be built for loopback hosts
+func (b linkBase) loopbackOnly() bool {
+	return b.publicURL == "" && len(b.allowedHosts) == 0
+}
+
+// resolve returns the public URL, or else the scheme and host the client
+// used to reach the server. The host must be allowed, so that a forged Host
+// header can't plant links in cached responses.
+func (b linkBase) resolve(r *http.Request) (string, error) {
+	if b.publicURL != "" {
+		return b.publicURL, nil
+	}
+	hostname := r.Host
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		hostname = host
+	}
+	hostname = strings.Trim(hostname, "[]")
+	ip := net.ParseIP(hostname)
+	if !strings.EqualFold(hostname, "localhost") && (ip == nil || !ip.IsLoopback()) &&
+		!slices.ContainsFunc(b.allowedHosts, func(allowed string) bool { return strings.EqualFold(allowed, hostname) }) {
+		return "", &apperror.ValidationError{Field: "Host", Message: "unknown host, set PUBLIC_URL or ALLOWED_HOSTS"}
+	}
+	scheme := "http"
+	if r.TLS != nil || (b.trustProxy && r.Header.Get("X-Forwarded-Proto") == "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host, nil
+}
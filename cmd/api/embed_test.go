@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/embed"
+)
+
+func newEmbedServer(t *testing.T, links linkBase) *httptest.Server {
+	t.Helper()
+	const tweet = `{"code":200,"message":"OK","tweet":{"id":"20","url":"https://x.com/jack/status/20","text":"just setting up my twttr","author":{"name":"jack","screen_name":"jack"}}}`
+	client := newFixtureClient(t, map[string]string{"/jack/status/20": tweet, "/i/status/20": tweet})
+	router := mux.NewRouter()
+	registerAPIRoutes(router.PathPrefix("/api").Subrouter(), client, nil, links, trackers{})
+	router.Handle("/embed/{id:[0-9]+}", makeEmbedHandler(client)).Methods("GET")
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOEmbed(t *testing.T) {
+	server := newEmbedServer(t, linkBase{publicURL: "https://tx.example.com"})
+
+	resp, err := http.Get(server.URL + "/api/oembed?url=https://x.com/jack/status/20&maxwidth=400&theme=dark")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	var o embed.OEmbed
+	if err := json.NewDecoder(resp.Body).Decode(&o); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if o.Type != "rich" || o.AuthorName != "jack" || o.Width != 400 || o.ProviderURL != "https://tx.example.com/" {
+		t.Fatalf("unexpected oEmbed %+v", o)
+	}
+	if !strings.Contains(o.HTML, `src="https://tx.example.com/embed/20?maxwidth=400&amp;theme=dark"`) {
+		t.Fatalf("unexpected html %s", o.HTML)
+	}
+
+	for query, status := range map[string]int{
+		"":                                              http.StatusBadRequest,
+		"?url=https://example.com":                      http.StatusBadRequest,
+		"?url=https://x.com/jack/status/404":            http.StatusNotFound,
+		"?url=20&format=xml":                            http.StatusNotImplemented,
+		"?url=20&theme=sepia":                           http.StatusBadRequest,
+		"?url=https://x.com/jack/status/20":             http.StatusOK,
+		"?url=https://x.com/i/web/status/20":            http.StatusOK,
+		"?url=https://x.com/jack/status/20&maxheight=0": http.StatusBadRequest,
+		"?url=20&maxwidth=100":                          http.StatusNotImplemented,
+	} {
+		resp, err := http.Get(server.URL + "/api/oembed" + query)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("%q: expected status %d, got %d", query, status, resp.StatusCode)
+		}
+	}
+}
+
+func TestOEmbedUsesRequestHost(t *testing.T) {
+	oembed := func(links linkBase, host string) (int, string) {
+		t.Helper()
+		server := newEmbedServer(t, links)
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/oembed?url=20", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		defer resp.Body.Close()
+		var o embed.OEmbed
+		json.NewDecoder(resp.Body).Decode(&o)
+		return resp.StatusCode, o.ProviderURL
+	}
+
+	allowed := []string{"embed.example.com"}
+	if _, url := oembed(linkBase{allowedHosts: allowed, trustProxy: true}, "embed.example.com"); url != "https://embed.example.com/" {
+		t.Fatalf("unexpected provider URL %s", url)
+	}
+	// The scheme of the proxy is only taken from a trusted one
+	if _, url := oembed(linkBase{allowedHosts: allowed}, "embed.example.com:8080"); url != "http://embed.example.com:8080/" {
+		t.Fatalf("unexpected provider URL %s", url)
+	}
+	if status, _ := oembed(linkBase{allowedHosts: allowed, trustProxy: true}, "evil.example.com"); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown host, got %d", status)
+	}
+}
+
+func TestEmbedCard(t *testing.T) {
+	server := newEmbedServer(t, linkBase{})
+
+	resp, err := http.Get(server.URL + "/embed/20?theme=dark")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Fatalf("unexpected Content-Security-Policy %q", csp)
+	}
+
+	// Cards are cached like tweets
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/embed/20?theme=dark", nil)
+	req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	cached, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	cached.Body.Close()
+	if cached.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", cached.StatusCode)
+	}
+
+	narrow, err := http.Get(server.URL + "/embed/20?maxwidth=100")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	narrow.Body.Close()
+	if narrow.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a card narrower than the minimum, got %d", narrow.StatusCode)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/export"
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+// exportRoute is the template of the export route, whose budget is
+// EXPORT_TIMEOUT rather than REQUEST_TIMEOUT
+const exportRoute = "/api/users/{username}/export"
+
+const (
+	// exportConcurrency is how many tweets of a page are fetched in parallel
+	exportConcurrency = 4
+	// exportPageReserve is the part of the request budget left for a page;
+	// with less remaining, the export stops and returns a cursor
+	exportPageReserve = 5 * time.Second
+)
+
+// Trailers sent after the exported rows
+const (
+	// exportCursorTrailer resumes the export when more pages remain
+	exportCursorTrailer = "X-Export-Cursor"
+	// exportRowsTrailer is the number of rows in the response
+	exportRowsTrailer = "X-Export-Rows"
+	// exportErrorTrailer explains why the export stopped early
+	exportErrorTrailer = "X-Export-Error"
+)
+
+// makeExportHandler streams the timeline of a user as flattened rows. Rows
+// are written page by page as they are fetched. When the request budget
+// runs low or an upstream fails, the file ends with a cursor to continue
+// from, also sent as a trailer; rows of a page cut short may be repeated.
+func makeExportHandler(client *twitterx.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		username := mux.Vars(r)["username"]
+		query := r.URL.Query()
+
+		formatName := query.Get("format")
+		if formatName == "" {
+			formatName = string(export.FormatJSONL)
+		}
+		format, err := export.ParseFormat(formatName)
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		since, err := parseTimeParam("since", query.Get("since"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		until, err := parseTimeParam("until", query.Get("until"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		cursor, err := decodeExportCursor(query.Get("cursor"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+
+		// The first page is fetched before answering so that a missing user
+		// is reported with a status code
+		timeline, err := client.GetTimelinePage(ctx, username, cursor)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error exporting tweets of %s: %v", username, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", format.ContentType())
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": username + "." + string(format)}))
+		header.Set("Cache-Control", "no-store")
+		header.Set("Trailer", exportCursorTrailer+", "+exportRowsTrailer+", "+exportErrorTrailer)
+		w.WriteHeader(http.StatusOK)
+
+		writer, err := export.NewWriter(w, format, true)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error creating %s writer: %v", format, err)
+			return
+		}
+		rc := http.NewResponseController(w)
+
+		rows := 0
+		for {
+			page := newExportCutOff(timeline, since)
+			ids := filterExportIDs(timeline.TweetIDs, since, until, page)
+			err = client.HydrateTweets(ctx, username, ids, exportConcurrency, func(id string, tweet *twitterx.Tweet, err error) error {
+				if twitterx.IsNotFound(err) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				createdAt := tweet.CreatedAt.Time
+				page.see(id, createdAt)
+				if !since.IsZero() && createdAt.Before(since) {
+					return nil
+				}
+				if !until.IsZero() && !createdAt.Before(until) {
+					return nil
+				}
+				rows++
+				return writer.Write(export.FromTweet(tweet))
+			})
+			if err == nil {
+				err = writer.Flush()
+			}
+			if err != nil {
+				break
+			}
+			if err := rc.Flush(); err != nil {
+				logger.DebugContext(ctx, "Error flushing export: %v", err)
+			}
+
+			next := timeline.NextCursor
+			if next == "" || next == cursor || page.reached() {
+				cursor = ""
+				break
+			}
+			cursor = next
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < exportPageReserve {
+				break
+			}
+			if timeline, err = client.GetTimelinePage(ctx, username, cursor); err != nil {
+				break
+			}
+		}
+
+		var token string
+		if cursor != "" {
+			token = encodeExportCursor(cursor)
+		}
+		if closeErr := writer.Close(token); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Export of %s stopped after %d rows: %v", username, rows, err)
+			header.Set(exportErrorTrailer, err.Error())
+		}
+		if token != "" {
+			header.Set(exportCursorTrailer, token)
+		}
+		header.Set(exportRowsTrailer, strconv.Itoa(rows))
+		logger.DebugContext(ctx, "Exported %d rows of %s", rows, username)
+	}
+}
+
+// exportCutOff tells whether a timeline page is past since. Timelines are
+// newest first, so a page whose tweets by the user are all older than since
+// ends the export. Retweets don't count, as their IDs date the original
+// tweet rather than the retweet, and neither does a page without tweets by
+// the user.
+type exportCutOff struct {
+	since       time.Time
+	retweets    map[string]bool
+	recent, old bool
+}
+
+func newExportCutOff(timeline *twitterx.Timeline, since time.Time) *exportCutOff {
+	c := &exportCutOff{since: since, retweets: make(map[string]bool)}
+	for _, tweet := range timeline.Tweets {
+		if tweet.RetweetedBy != "" {
+			c.retweets[tweet.ID] = true
+		}
+	}
+	return c
+}
+
+// see records the creation time of the tweet id of the page
+func (c *exportCutOff) see(id string, createdAt time.Time) {
+	switch {
+	case c.since.IsZero() || c.retweets[id]:
+	case createdAt.Before(c.since):
+		c.old = true
+	default:
+		c.recent = true
+	}
+}
+
+// reached reports whether the page ends the export
+func (c *exportCutOff) reached() bool {
+	return c.old && !c.recent
+}
+
+// filterExportIDs drops the tweets whose ID shows they were posted outside
+// [since, until), passing their time to page. Tweets without a timestamp in
+// the ID are kept and checked once fetched.
+func filterExportIDs(ids []string, since, until time.Time, page *exportCutOff) []string {
+	var kept []string
+	for _, id := range ids {
+		if createdAt, ok := twitterx.TweetTime(id); ok {
+			page.see(id, createdAt)
+			if !since.IsZero() && createdAt.Before(since) {
+				continue
+			}
+			if !until.IsZero() && !createdAt.Before(until) {
+				continue
+			}
+		}
+		kept = append(kept, id)
+	}
+	return kept
+}
+
+// parseTimeParam parses an RFC 3339 time or a date (midnight UTC)
+func parseTimeParam(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, &apperror.ValidationError{Field: field, Message: "must be a date (2006-01-02) or an RFC 3339 time"}
+}
+
+// encodeExportCursor wraps a Nitter cursor into an opaque token
+func encodeExportCursor(cursor string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+func decodeExportCursor(token string) (string, error) {
+	cursor, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", &apperror.ValidationError{Field: "cursor", Message: "invalid cursor"}
+	}
+	return string(cursor), nil
+}
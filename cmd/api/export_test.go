@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/pkg/twitterx"
+)
+
+// tweetID returns a tweet ID posted at the given date
+func tweetID(date string) string {
+	t, _ := time.Parse(time.DateOnly, date)
+	return strconv.FormatInt((t.UnixMilli()-1288834974657)<<22, 10)
+}
+
+// exportPages is a three page timeline of jack
+var exportPages = [][]string{
+	{tweetID("2024-03-03"), tweetID("2024-03-02")},
+	{tweetID("2024-03-01"), tweetID("2024-02-01")},
+	{tweetID("2023-01-01")},
+}
+
+// newExportServer serves pages as the timeline of jack through fake
+// upstreams, followed by an empty page, and returns the URL of the export
+// endpoint and the number of Nitter requests. IDs prefixed with "rt:" are
+// retweets.
+func newExportServer(t *testing.T, budget time.Duration, pages [][]string) (string, *int) {
+	t.Helper()
+	nitterCalls := 0
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nitterCalls++
+		page := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			page, _ = strconv.Atoi(strings.TrimPrefix(cursor, "c"))
+			page--
+		}
+		if page < len(pages) {
+			w.Header().Set("Min-Id", "c"+strconv.Itoa(page+2))
+		}
+		fmt.Fprint(w, "<rss><channel>")
+		if page < len(pages) {
+			for _, id := range pages[page] {
+				if id, ok := strings.CutPrefix(id, "rt:"); ok {
+					fmt.Fprintf(w, "<item><title>RT by @jack: hi</title><guid>https://nitter.net/ev/status/%s#m</guid></item>", id)
+					continue
+				}
+				fmt.Fprintf(w, "<item><guid>https://nitter.net/jack/status/%s#m</guid></item>", id)
+			}
+		}
+		fmt.Fprint(w, "</channel></rss>")
+	}))
+	t.Cleanup(nitter.Close)
+
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		createdAt, _ := twitterx.TweetTime(id)
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"tweet","created_at":%q,"author":{"screen_name":"jack"}}}`,
+			id, createdAt.Format("Mon Jan 02 15:04:05 -0700 2006"))
+	}))
+	t.Cleanup(fx.Close)
+
+	client, err := twitterx.New(twitterx.WithNitterInstances(nitter.URL), twitterx.WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	router := mux.NewRouter()
+	// The default budget would cut every export short
+	router.Use(requestBudget(time.Millisecond, map[string]time.Duration{exportRoute: budget}))
+	registerAPIRoutes(router.PathPrefix("/api").Subrouter(), client, nil, linkBase{}, trackers{})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server.URL + "/api/users/jack/export", &nitterCalls
+}
+
+// getExport returns the exported lines and the trailers
+func getExport(t *testing.T, url string) ([]string, http.Header) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("GET %s: status %d: %s", url, resp.StatusCode, body)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, resp.Trailer
+}
+
+func TestExportFiltersByDate(t *testing.T) {
+	url, nitterCalls := newExportServer(t, 0, exportPages)
+
+	lines, trailer := getExport(t, url+"?format=csv&since=2024-02-15&until=2024-03-03")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "id,url,created_at") {
+		t.Fatalf("expected a header and 2 rows, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], tweetID("2024-03-02")+",") || !strings.HasPrefix(lines[2], tweetID("2024-03-01")+",") {
+		t.Fatalf("unexpected rows %q", lines)
+	}
+	if trailer.Get("X-Export-Rows") != "2" || trailer.Get("X-Export-Cursor") != "" {
+		t.Fatalf("unexpected trailers %v", trailer)
+	}
+	// The third page has only tweets older than since, which ends the
+	// export before the last page
+	if *nitterCalls != 3 {
+		t.Fatalf("expected 3 Nitter calls, got %d", *nitterCalls)
+	}
+}
+
+func TestExportCutOffIgnoresRetweets(t *testing.T) {
+	url, nitterCalls := newExportServer(t, 0, [][]string{
+		{tweetID("2024-03-03")},
+		{"rt:" + tweetID("2023-06-01")},
+		{tweetID("2024-03-01")},
+		{tweetID("2023-01-01")},
+	})
+
+	lines, _ := getExport(t, url+"?format=csv&since=2024-02-15")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], tweetID("2024-03-01")+",") {
+		t.Fatalf("expected the tweets after a page of old retweets, got %q", lines)
+	}
+	if *nitterCalls != 4 {
+		t.Fatalf("expected 4 Nitter calls, got %d", *nitterCalls)
+	}
+}
+
+func TestExportResumesWithCursor(t *testing.T) {
+	// A budget below the page reserve stops after every page
+	url, _ := newExportServer(t, exportPageReserve/2, exportPages)
+
+	var ids []string
+	cursor := ""
+	for range 5 {
+		lines, trailer := getExport(t, url+"?cursor="+cursor)
+		var last struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		if len(lines) > 0 {
+			json.Unmarshal([]byte(lines[len(lines)-1]), &last)
+		}
+		if last.NextCursor != trailer.Get("X-Export-Cursor") {
+			t.Fatalf("expected the cursor of the trailer as the last record, got %q", lines)
+		}
+		if last.NextCursor != "" {
+			lines = lines[:len(lines)-1]
+		}
+		for _, line := range lines {
+			ids = append(ids, line[strings.Index(line, `"id":"`)+6:][:19])
+		}
+		if cursor = last.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(ids) != 5 || ids[0] != tweetID("2024-03-03") || ids[4] != tweetID("2023-01-01") {
+		t.Fatalf("unexpected exported IDs %v", ids)
+	}
+}
+
+func TestExportValidation(t *testing.T) {
+	url, _ := newExportServer(t, 0, exportPages)
+	for _, query := range []string{"?format=xml", "?since=yesterday", "?cursor=%25"} {
+		resp, err := http.Get(url + query)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	url, _ := newExportServer(t, 0, exportPages)
+	resp, err := http.Get(url + "?format=parquet")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Header.Get("Content-Type") != "application/vnd.apache.parquet" || !strings.HasPrefix(string(body), "PAR1") || !strings.HasSuffix(string(body), "PAR1") {
+		t.Fatalf("expected a Parquet file, got %s with %d bytes", resp.Header.Get("Content-Type"), len(body))
+	}
+	if resp.Trailer.Get("X-Export-Rows") != "5" {
+		t.Fatalf("unexpected trailers %v", resp.Trailer)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"twitterx-api/pkg/twitterx"
+)
+
+// newFixtureClient returns a client whose FxTwitter upstream answers with
+// fixtures, response bodies by path, and NOT_FOUND for any other path
+func newFixtureClient(t *testing.T, fixtures map[string]string) *twitterx.Client {
+	t.Helper()
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"code":404,"message":"NOT_FOUND"}`
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(fx.Close)
+
+	client, err := twitterx.New(twitterx.WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	return client
+}
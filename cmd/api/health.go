@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+
+	"twitterx-api/internal/config"
+	"twitterx-api/internal/health"
+	"twitterx-api/internal/metrics"
+	"twitterx-api/pkg/twitterx"
+)
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// newHealthChecker probes every configured Nitter instance and FxTwitter
+func newHealthChecker(cfg *config.Config, client *twitterx.Client, m metrics.Recorder) *health.Checker {
+	var checks []health.Check
+	for _, dep := range client.Dependencies(cfg.HealthProbeUser) {
+		checks = append(checks, health.Check{
+			Name:         dep.Name,
+			Group:        dep.Group,
+			Probe:        dep.Probe,
+			CircuitState: dep.CircuitState,
+		})
+	}
+	return health.NewChecker(cfg.HealthCacheTTL, cfg.HealthProbeTimeout, m, checks...)
+}
+
+// handleHealthz reports that the process is alive
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, HealthResponse{Status: "ok"})
+}
+
+// makeReadyzHandler reports whether the upstreams can serve traffic, with
+// a breakdown per dependency
+func makeReadyzHandler(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Report(r.Context())
+		if !report.Ready() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		writeJSON(w, report)
+	}
+}
@@ -1,103 +1,128 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/config"
+	"twitterx-api/internal/graphql"
+	"twitterx-api/internal/grpcserver"
 	"twitterx-api/internal/logger"
-	"twitterx-api/internal/service"
+	"twitterx-api/internal/metrics"
+	"twitterx-api/internal/models"
+	"twitterx-api/internal/openapi"
+	"twitterx-api/internal/ratelimit"
+	"twitterx-api/internal/render"
+	"twitterx-api/internal/tracing"
+	"twitterx-api/pkg/twitterx"
 )
 
-type TweetsResponse struct {
-	Username string   `json:"username"`
-	TweetIDs []string `json:"tweet_ids"`
-}
-
-func makeGetUserTweetsHandler(nitterService *service.NitterService) http.HandlerFunc {
+func makeGetUserTweetsHandler(client *twitterx.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		username := vars["username"]
 
-		logger.Debug("Fetching tweets for user: %s", username)
+		logger.DebugContext(r.Context(), "Fetching tweets for user: %s", username)
 
 		// Fetch tweet IDs from Nitter
-		tweetIDs, err := nitterService.GetUserTweetIDs(username)
+		timeline, err := client.GetTimeline(r.Context(), username)
 		if err != nil {
-			logger.Error("Error fetching tweets for user %s: %v", username, err)
-			http.Error(w, err.Error(), apperror.HTTPStatusCode(err))
+			logger.ErrorContext(r.Context(), "Error fetching tweets for user %s: %v", username, err)
+			apperror.WriteHTTPError(w, err)
 			return
 		}
 
-		logger.Debug("Found %d tweets for user: %s", len(tweetIDs), username)
+		logger.DebugContext(r.Context(), "Found %d tweets for user: %s", len(timeline.TweetIDs), username)
 
-		response := TweetsResponse{
+		response := models.TweetsResponse{
 			Username: username,
-			TweetIDs: tweetIDs,
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			logger.Error("Error encoding response: %v", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
+			TweetIDs: timeline.TweetIDs,
+			Tweets:   timeline.Tweets,
 		}
+		writeCachedJSON(w, r, response, client.TimelineCacheTTL(), timeline.LastModified)
 	}
 }
 
-func makeGetTweetHandler(fxTwitterService *service.FxTwitterService) http.HandlerFunc {
+func makeGetTweetHandler(client *twitterx.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		username := vars["username"]
 		tweetID := vars["id"]
 
-		logger.Debug("Fetching tweet %s for user: %s", tweetID, username)
+		logger.DebugContext(r.Context(), "Fetching tweet %s for user: %s", tweetID, username)
+
+		// ?format= renders the tweet instead of returning JSON
+		var format render.Format
+		if name := r.URL.Query().Get("format"); name != "" && name != "json" {
+			var err error
+			if format, err = render.ParseFormat(name); err != nil {
+				apperror.WriteHTTPError(w, err)
+				return
+			}
+		}
 
 		// Fetch tweet data from FxTwitter API
-		tweetData, err := fxTwitterService.GetTweetData(username, tweetID)
+		tweet, err := client.GetTweet(r.Context(), username, tweetID)
 		if err != nil {
-			logger.Error("Error fetching tweet %s for user %s: %v", tweetID, username, err)
-			http.Error(w, err.Error(), apperror.HTTPStatusCode(err))
+			logger.ErrorContext(r.Context(), "Error fetching tweet %s for user %s: %v", tweetID, username, err)
+			apperror.WriteHTTPError(w, err)
 			return
 		}
 
-		logger.Debug("Successfully fetched tweet %s", tweetID)
-
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(tweetData); err != nil {
-			logger.Error("Error encoding response: %v", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		logger.DebugContext(r.Context(), "Successfully fetched tweet %s", tweetID)
+		if format != "" {
+			if format == render.FormatHTML {
+				w.Header().Set("Content-Security-Policy", embedPolicy)
+			}
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			writeCached(w, r, format.ContentType(), bytes.NewBufferString(render.Render(tweet, format)), client.TweetCacheTTL(), time.Time{})
 			return
 		}
+		response := models.FxTwitterResponse{Code: http.StatusOK, Message: "OK", Tweet: tweet}
+		writeCachedJSON(w, r, response, client.TweetCacheTTL(), time.Time{})
 	}
 }
 
-func makeGetUserHandler(fxTwitterService *service.FxTwitterService) http.HandlerFunc {
+func makeGetUserHandler(client *twitterx.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		username := vars["username"]
 
-		logger.Debug("Fetching user data for: %s", username)
+		logger.DebugContext(r.Context(), "Fetching user data for: %s", username)
 
-		// Fetch user data from FxTwitter API
-		userData, err := fxTwitterService.GetUserData(username)
+		// Fetch user data from FxTwitter API, and the pinned tweet from Nitter
+		user, err := client.GetUserWithPinnedTweet(r.Context(), username)
 		if err != nil {
-			logger.Error("Error fetching user %s: %v", username, err)
-			http.Error(w, err.Error(), apperror.HTTPStatusCode(err))
+			logger.ErrorContext(r.Context(), "Error fetching user %s: %v", username, err)
+			apperror.WriteHTTPError(w, err)
 			return
 		}
 
-		logger.Debug("Successfully fetched user data for: %s", username)
+		logger.DebugContext(r.Context(), "Successfully fetched user data for: %s", username)
+		response := models.FxTwitterUserResponse{Code: http.StatusOK, Message: "OK", User: user}
+		writeCachedJSON(w, r, response, client.UserCacheTTL(), time.Time{})
+	}
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(userData); err != nil {
-			logger.Error("Error encoding response: %v", err)
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
-		}
+// writeJSON encodes v as the JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Error encoding response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -105,30 +130,132 @@ func serveIndex(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filepath.Join("public", "index.html"))
 }
 
-func serveProfile(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, filepath.Join("public", "profile.html"))
-}
-
 func main() {
-	// Get Nitter URL from environment
-	nitterURL := os.Getenv("NITTER_URL")
-	if nitterURL == "" {
-		logger.Fatal("NITTER_URL environment variable is required")
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatal("%v", err)
+	}
+
+	if err := logger.Configure(logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat}); err != nil {
+		logger.Fatal("Invalid logger configuration: %v", err)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracesExporter,
+		ServiceName: cfg.ServiceName,
+	})
+	if err != nil {
+		logger.Fatal("Invalid tracing configuration: %v", err)
 	}
 
-	// Initialize Nitter service
-	nitterService := service.NewNitterService(nitterURL)
+	// Initialize metrics
+	registry := metrics.NewRegistry()
 
-	// Initialize FxTwitter service
-	fxTwitterService := service.NewFxTwitterService()
+	// Initialize the upstream client. All upstream calls share one pooled
+	// transport, are retried and guarded by circuit breakers.
+	client, err := twitterx.New(
+		twitterx.WithNitterInstances(cfg.NitterURLs...),
+		twitterx.WithMetrics(registry),
+		twitterx.WithTransport(twitterx.TransportConfig{
+			Proxy:               cfg.UpstreamProxy,
+			UserAgent:           cfg.UpstreamUserAgent,
+			MaxIdleConns:        cfg.UpstreamMaxIdleConns,
+			MaxIdleConnsPerHost: cfg.UpstreamMaxIdleConnsPerHost,
+			IdleConnTimeout:     cfg.UpstreamIdleConnTimeout,
+			Compression:         cfg.UpstreamCompression,
+			MaxResponseSize:     int64(cfg.UpstreamMaxResponseSize),
+		}),
+		twitterx.WithRetryPolicy(twitterx.RetryPolicy{
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+		}),
+		twitterx.WithCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerOpenTimeout),
+		twitterx.WithTweetCacheTTL(cfg.TweetCacheTTL),
+		twitterx.WithUserCacheTTL(cfg.UserCacheTTL),
+		twitterx.WithTimelineCacheTTL(cfg.TimelineCacheTTL),
+		twitterx.WithNitterConcurrency(cfg.NitterMaxConcurrency, cfg.UpstreamMaxQueue, cfg.UpstreamQueueTimeout),
+		twitterx.WithFxTwitterConcurrency(cfg.FxTwitterMaxConcurrency, cfg.UpstreamMaxQueue, cfg.UpstreamQueueTimeout),
+	)
+	if err != nil {
+		logger.Fatal("Invalid upstream transport configuration: %v", err)
+	}
+	defer client.Close()
+
+	// Background trackers of the watched accounts
+	tracked, err := newTrackers(cfg, client)
+	if err != nil {
+		logger.Fatal("Invalid tracker configuration: %v", err)
+	}
+	defer tracked.Close()
+
+	// Initialize API key authentication
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		logger.Fatal("Invalid API key configuration: %v", err)
+	}
+	if authenticator == nil {
+		logger.Warn("No API keys configured, the API is open to everyone")
+	}
 
 	// Setup router
 	router := mux.NewRouter()
+	router.Use(requestTracing)
+	router.Use(requestLogging)
+	router.Use(requestMetrics(registry))
+	router.Use(requestBudget(cfg.RequestTimeout, map[string]time.Duration{exportRoute: cfg.ExportTimeout}))
+
+	// API documentation, open to everyone
+	router.Handle("/api/openapi.json", openapi.Handler()).Methods("GET")
+	router.Handle("/docs", openapi.DocsHandler()).Methods("GET")
 
 	// API endpoints
-	router.HandleFunc("/api/users/{username}/tweets/{id}", makeGetTweetHandler(fxTwitterService)).Methods("GET")
-	router.HandleFunc("/api/users/{username}/tweets", makeGetUserTweetsHandler(nitterService)).Methods("GET")
-	router.HandleFunc("/api/users/{username}", makeGetUserHandler(fxTwitterService)).Methods("GET")
+	links := newLinkBase(cfg)
+	if links.loopbackOnly() {
+		logger.Warn("Neither PUBLIC_URL nor ALLOWED_HOSTS is set: oEmbed links and crawler previews only work on loopback hosts")
+	}
+	// The limiter is shared with gRPC, so clients can't double their rate
+	var limiter *ratelimit.Keyed
+	var rateLimit []mux.MiddlewareFunc
+	if cfg.RateLimitRPS > 0 {
+		limiter = ratelimit.NewKeyed(func() *ratelimit.Bucket {
+			return ratelimit.NewBucket(cfg.RateLimitRPS, cfg.RateLimitBurst)
+		}, 10*time.Minute)
+		rateLimit = append(rateLimit, ratelimit.Middleware(limiter, clientKey(cfg.TrustProxy)))
+	}
+	// Authentication runs first, so clients are limited by validated key
+	var guards []mux.MiddlewareFunc
+	if authenticator != nil {
+		guards = append(guards, authenticator.Middleware)
+	}
+	guards = append(guards, rateLimit...)
+	api := router.PathPrefix("/api").Subrouter()
+	api.Use(guards...)
+	registerAPIRoutes(api, client, authenticator, links, tracked)
+
+	// GraphQL endpoint, rate limited and authenticated like /api
+	graphqlHandler, err := graphql.NewHandler(client)
+	if err != nil {
+		logger.Fatal("Invalid GraphQL schema: %v", err)
+	}
+	gql := router.Path("/graphql").Subrouter()
+	gql.Use(guards...)
+	gql.Handle("", auth.Require(auth.ScopeRead)(graphqlHandler)).Methods("GET", "POST")
+
+	// Embeddable tweet cards, for the iframes of oEmbed responses. Viewers
+	// of the embedding page have no key, so like the UI routes the cards
+	// are public and only rate limited.
+	embeds := router.PathPrefix("/embed").Subrouter()
+	embeds.Use(rateLimit...)
+	embeds.Handle("/{id:[0-9]+}", makeEmbedHandler(client)).Methods("GET")
+
+	// Health endpoints
+	router.HandleFunc("/healthz", handleHealthz).Methods("GET")
+	router.HandleFunc("/readyz", makeReadyzHandler(newHealthChecker(cfg, client, registry))).Methods("GET")
+
+	// Metrics endpoint
+	router.Handle("/metrics", registry).Methods("GET")
 
 	// Static files
 	staticFileServer := http.FileServer(http.Dir(filepath.Join("public", "static")))
@@ -136,13 +263,78 @@ func main() {
 
 	// UI routes
 	router.HandleFunc("/", serveIndex).Methods("GET")
-	router.HandleFunc("/{username}", serveProfile).Methods("GET")
+	ui := router.NewRoute().Subrouter()
+	ui.Use(rateLimit...)
+	ui.HandleFunc("/{username}", makeProfileHandler(client, links)).Methods("GET")
+	ui.HandleFunc("/{username}/status/{id:[0-9]+}", makeStatusHandler(client, links)).Methods("GET")
+
+	// Cancelled on shutdown so in-flight upstream calls stop with the server
+	baseCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	port := ":8080"
-	logger.Info("Server starting on http://127.0.0.1%s", port)
-	logger.Info("Using Nitter instance: %s", nitterURL)
-	logger.Info("Debug mode: %v", logger.IsDebugEnabled())
-	if err := http.ListenAndServe(port, router); err != nil {
+	if len(cfg.TrackUsers) > 0 {
+		logger.Info("Tracking %s every %s", strings.Join(cfg.TrackUsers, ", "), cfg.TrackInterval)
+		tracked.run(baseCtx)
+	}
+
+	server := &http.Server{
+		Addr:        cfg.Port,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	// gRPC API on its own port, sharing the client with the REST API
+	var grpcServer *grpcserver.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCPort)
+		if err != nil {
+			logger.Fatal("gRPC listen error: %v", err)
+		}
+		grpcServer = grpcserver.New(client,
+			grpcserver.WithAuthenticator(authenticator),
+			grpcserver.WithRateLimiter(limiter),
+			grpcserver.WithRequestTimeout(cfg.RequestTimeout),
+			grpcserver.WithDeletions(tracked.deletions),
+		)
+		go func() {
+			logger.Info("gRPC server starting on 127.0.0.1%s", cfg.GRPCPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Fatal("gRPC server error: %v", err)
+			}
+		}()
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-baseCtx.Done()
+		logger.Info("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		grpcDone := make(chan struct{})
+		go func() {
+			defer close(grpcDone)
+			if grpcServer != nil {
+				grpcServer.Shutdown(shutdownCtx)
+			}
+		}()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Server shutdown error: %v", err)
+		}
+		<-grpcDone
+	}()
+
+	logger.Info("Server starting on http://127.0.0.1%s", cfg.Port)
+	logger.Info("Using Nitter instances: %s", strings.Join(cfg.NitterURLs, ", "))
+	logger.Info("Log level: %s", logger.GetLevel())
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatal("Server error: %v", err)
 	}
+	<-shutdownDone
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error("Failed to flush traces: %v", err)
+	}
 }
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apiv2"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/embed"
+	"twitterx-api/internal/export"
+	"twitterx-api/internal/models"
+	"twitterx-api/internal/openapi"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+)
+
+// responseTypes maps every documented operation to the Go type its handler
+// encodes on success, as JSON or one value per JSON line
+var responseTypes = map[string]reflect.Type{
+	"GET /api/users/{username}":             reflect.TypeFor[models.FxTwitterUserResponse](),
+	"GET /api/users/{username}/export":      reflect.TypeFor[export.Row](),
+	"GET /api/oembed":                       reflect.TypeFor[embed.OEmbed](),
+	"GET /api/users/{username}/tweets":      reflect.TypeFor[models.TweetsResponse](),
+	"GET /api/users/{username}/tweets/{id}": reflect.TypeFor[models.FxTwitterResponse](),
+	"GET /api/admin/log-level":              reflect.TypeFor[LogLevelResponse](),
+	"PUT /api/admin/log-level":              reflect.TypeFor[LogLevelResponse](),
+	"GET /api/admin/usage":                  reflect.TypeFor[[]auth.Usage](),
+	"GET /api/users/{username}/history":     reflect.TypeFor[tracker.History](),
+	"GET /api/users/{username}/stats":       reflect.TypeFor[tracker.Series](),
+	"GET /api/users/{username}/deleted":     reflect.TypeFor[tracker.Deletions](),
+	"GET /api/tweets/{id}/metrics":          reflect.TypeFor[tracker.TweetMetrics](),
+	"GET /api/v2/users/{username}":          reflect.TypeFor[apiv2.User](),
+	"GET /api/v2/users/{username}/timeline": reflect.TypeFor[apiv2.Timeline](),
+	"GET /api/v2/tweets/{id}":               reflect.TypeFor[apiv2.Tweet](),
+}
+
+type specDoc struct {
+	OpenAPI    string                              `json:"openapi"`
+	Paths      map[string]map[string]specOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *specSchema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 any                    `json:"type"`
+	Properties           map[string]*specSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	Items                *specSchema            `json:"items"`
+	AdditionalProperties *specSchema            `json:"additionalProperties"`
+}
+
+// types returns the JSON types allowed by the schema
+func (s *specSchema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, v := range t {
+			types = append(types, v.(string))
+		}
+		return types
+	}
+	return nil
+}
+
+func loadSpec(t *testing.T) *specDoc {
+	t.Helper()
+	var doc specDoc
+	if err := json.Unmarshal(openapi.Spec(), &doc); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Fatalf("expected OpenAPI 3.1.0, got %q", doc.OpenAPI)
+	}
+	return &doc
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	doc := loadSpec(t)
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	client, err := twitterx.New()
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	// Admin endpoints are only registered with API keys
+	authenticator, err := auth.New([]auth.Key{{Name: "ops", Key: "secret", Scopes: []auth.Scope{auth.ScopeAdmin}}}, auth.AnonymousTier{})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	registerAPIRoutes(api, client, authenticator, linkBase{}, trackers{})
+
+	var registered []string
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			registered = append(registered, method+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking routes: %v", err)
+	}
+
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	if !slices.Equal(registered, documented) {
+		t.Fatalf("routes and OpenAPI paths differ\nregistered: %v\ndocumented: %v", registered, documented)
+	}
+}
+
+func TestOpenAPISchemasMatchModels(t *testing.T) {
+	doc := loadSpec(t)
+	checked := make(map[string]bool)
+
+	for operation, typ := range responseTypes {
+		method, path, _ := strings.Cut(operation, " ")
+		op, ok := doc.Paths[path][strings.ToLower(method)]
+		if !ok {
+			t.Errorf("%s is not documented", operation)
+			continue
+		}
+		content := op.Responses["200"].Content
+		schema := content["application/json"].Schema
+		if schema == nil {
+			schema = content["application/x-ndjson"].Schema
+		}
+		if schema == nil {
+			t.Errorf("%s: no JSON schema for 200", operation)
+			continue
+		}
+		checkSchema(t, doc, operation, schema, typ, checked)
+	}
+
+	for name := range doc.Components.Schemas {
+		if !checked[name] {
+			t.Errorf("schema %s is not used by any response", name)
+		}
+	}
+}
+
+var (
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+	timeType      = reflect.TypeFor[time.Time]()
+)
+
+// checkSchema verifies that schema describes the JSON encoding of typ
+func checkSchema(t *testing.T, doc *specDoc, where string, schema *specSchema, typ reflect.Type, checked map[string]bool) {
+	t.Helper()
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := doc.Components.Schemas[name]
+		if !ok {
+			t.Errorf("%s: unknown schema %s", where, schema.Ref)
+			return
+		}
+		if checked[name] {
+			return
+		}
+		checked[name] = true
+		checkSchema(t, doc, name, resolved, typ, checked)
+		return
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	want := jsonType(typ)
+	if !slices.Contains(schema.types(), want) {
+		t.Errorf("%s: expected type %s for %s, got %v", where, want, typ, schema.Type)
+		return
+	}
+
+	switch want {
+	case "array":
+		if schema.Items == nil {
+			t.Errorf("%s: array without items", where)
+			return
+		}
+		checkSchema(t, doc, where+"[]", schema.Items, typ.Elem(), checked)
+	case "object":
+		if typ.Kind() == reflect.Map {
+			if schema.AdditionalProperties != nil {
+				checkSchema(t, doc, where+"{}", schema.AdditionalProperties, typ.Elem(), checked)
+			}
+			return
+		}
+		checkStruct(t, doc, where, schema, typ, checked)
+	}
+}
+
+// checkStruct compares the properties and required fields of schema with
+// the JSON fields of a struct
+func checkStruct(t *testing.T, doc *specDoc, where string, schema *specSchema, typ reflect.Type, checked map[string]bool) {
+	t.Helper()
+	var names, required []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		names = append(names, name)
+		if !omitempty {
+			required = append(required, name)
+		}
+
+		prop, ok := schema.Properties[name]
+		if !ok {
+			t.Errorf("%s: field %s is not documented", where, name)
+			continue
+		}
+		if field.Type.Kind() == reflect.Pointer && !omitempty && prop.Ref == "" && !slices.Contains(prop.types(), "null") {
+			t.Errorf("%s.%s: nullable field must allow null", where, name)
+		}
+		checkSchema(t, doc, where+"."+name, prop, field.Type, checked)
+	}
+
+	for name := range schema.Properties {
+		if !slices.Contains(names, name) {
+			t.Errorf("%s: documented property %s does not exist", where, name)
+		}
+	}
+	sort.Strings(required)
+	documented := slices.Clone(schema.Required)
+	sort.Strings(documented)
+	if !slices.Equal(required, documented) {
+		t.Errorf("%s: required fields differ, model %v, schema %v", where, required, documented)
+	}
+}
+
+// jsonType returns the JSON Schema type encoding/json produces for typ
+func jsonType(typ reflect.Type) string {
+	if typ == timeType || typ.Implements(marshalerType) {
+		return "string"
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
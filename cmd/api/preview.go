@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/preview"
+	"twitterx-api/pkg/twitterx"
+)
+
+// serveSPA serves the profile page of the single-page UI
+func serveSPA(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, filepath.Join("public", "profile.html"))
+}
+
+// makeProfileHandler serves GET /{username}: OpenGraph tags of the profile
+// for crawlers, the UI for everyone else
+func makeProfileHandler(client *twitterx.Client, links linkBase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "User-Agent")
+		if !preview.IsCrawler(r.UserAgent()) {
+			serveSPA(w, r)
+			return
+		}
+
+		baseURL, err := links.resolve(r)
+		if err != nil {
+			previewFailed(w, r, err)
+			return
+		}
+		username := mux.Vars(r)["username"]
+		user, err := client.GetUser(r.Context(), username)
+		if err != nil {
+			previewFailed(w, r, err)
+			return
+		}
+		pageURL := baseURL + "/" + url.PathEscape(user.ScreenName)
+		writePreview(w, r, preview.UserPage(user, pageURL), client.UserCacheTTL())
+	}
+}
+
+// makeStatusHandler serves GET /{username}/status/{id}: OpenGraph tags of
+// the tweet for crawlers, the profile UI for everyone else
+func makeStatusHandler(client *twitterx.Client, links linkBase) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "User-Agent")
+		if !preview.IsCrawler(r.UserAgent()) {
+			serveSPA(w, r)
+			return
+		}
+
+		baseURL, err := links.resolve(r)
+		if err != nil {
+			previewFailed(w, r, err)
+			return
+		}
+		vars := mux.Vars(r)
+		tweet, err := client.GetTweet(r.Context(), vars["username"], vars["id"])
+		if err != nil {
+			previewFailed(w, r, err)
+			return
+		}
+		pageURL := baseURL + "/" + url.PathEscape(vars["username"]) + "/status/" + vars["id"]
+		oembedURL := baseURL + "/api/oembed?" + url.Values{"url": {pageURL}}.Encode()
+		writePreview(w, r, preview.TweetPage(tweet, pageURL, oembedURL), client.TweetCacheTTL())
+	}
+}
+
+func writePreview(w http.ResponseWriter, r *http.Request, page *preview.Page, maxAge time.Duration) {
+	var buf bytes.Buffer
+	if err := preview.Render(&buf, page); err != nil {
+		logger.ErrorContext(r.Context(), "Error rendering preview: %v", err)
+		http.Error(w, "Failed to render preview", http.StatusInternalServerError)
+		return
+	}
+	writeCached(w, r, "text/html; charset=utf-8", &buf, maxAge, time.Time{})
+}
+
+// previewFailed answers a crawler whose page could not be described: a
+// missing user or tweet is a 404, so no unfurl is shown, and other errors
+// fall back to the UI. Only upstream failures are logged as errors: an
+// unknown host, when neither PUBLIC_URL nor ALLOWED_HOSTS lists it, was
+// reported at startup and would otherwise be logged on every crawler hit.
+func previewFailed(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case twitterx.IsNotFound(err):
+		http.NotFound(w, r)
+		return
+	case isClientError(err):
+		logger.DebugContext(r.Context(), "No preview: %v", err)
+	default:
+		logger.ErrorContext(r.Context(), "Error building preview: %v", err)
+	}
+	serveSPA(w, r)
+}
+
+// isClientError reports whether err is due to the request rather than an
+// upstream
+func isClientError(err error) bool {
+	switch twitterx.ErrorClass(err) {
+	case apperror.ClassValidation, apperror.ClassCanceled:
+		return true
+	}
+	return false
+}
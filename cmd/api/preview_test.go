@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/logger"
+)
+
+const slackbot = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+
+func newPreviewServer(t *testing.T, links linkBase) *httptest.Server {
+	t.Helper()
+	client := newFixtureClient(t, map[string]string{
+		"/jack":           `{"code":200,"message":"OK","user":{"screen_name":"jack","name":"jack","followers":42}}`,
+		"/jack/status/20": `{"code":200,"message":"OK","tweet":{"id":"20","text":"just setting up my twttr","author":{"name":"jack","screen_name":"jack"}}}`,
+	})
+	router := mux.NewRouter()
+	router.HandleFunc("/{username}", makeProfileHandler(client, links)).Methods("GET")
+	router.HandleFunc("/{username}/status/{id:[0-9]+}", makeStatusHandler(client, links)).Methods("GET")
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getPreview(t *testing.T, url, userAgent string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestPreviewForCrawlers(t *testing.T) {
+	server := newPreviewServer(t, linkBase{publicURL: "https://tx.example.com"})
+
+	resp, body := getPreview(t, server.URL+"/jack", slackbot)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `<meta property="og:title" content="jack (@jack)">`) {
+		t.Fatalf("unexpected profile preview %d: %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Vary") != "User-Agent" {
+		t.Fatalf("expected Vary: User-Agent, got %q", resp.Header.Get("Vary"))
+	}
+
+	resp, body = getPreview(t, server.URL+"/jack/status/20", slackbot)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `<meta property="og:url" content="https://tx.example.com/jack/status/20">`) {
+		t.Fatalf("unexpected tweet preview %d: %s", resp.StatusCode, body)
+	}
+	if !strings.Contains(body, `href="https://tx.example.com/api/oembed?url=https%3A%2F%2Ftx.example.com%2Fjack%2Fstatus%2F20"`) {
+		t.Fatalf("tweet preview lacks the oEmbed link: %s", body)
+	}
+
+	// No unfurl for missing tweets
+	resp, _ = getPreview(t, server.URL+"/jack/status/404", slackbot)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestPreviewUnknownHostIsNotAnError(t *testing.T) {
+	var logs bytes.Buffer
+	if err := logger.Configure(logger.Config{Output: &logs, Level: "debug"}); err != nil {
+		t.Fatalf("logger.Configure: %v", err)
+	}
+	t.Cleanup(func() { logger.Configure(logger.Config{}) })
+	server := newPreviewServer(t, linkBase{})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/jack/status/20", nil)
+	req.Header.Set("User-Agent", slackbot)
+	req.Host = "evil.example.com"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "og:title") {
+		t.Fatalf("expected no preview for an unknown host, got %s", body)
+	}
+	if !strings.Contains(logs.String(), "level=DEBUG") || strings.Contains(logs.String(), "level=ERROR") {
+		t.Fatalf("expected the unknown host logged at debug only, got %q", logs.String())
+	}
+}
+
+func TestPreviewSkipsBrowsers(t *testing.T) {
+	server := newPreviewServer(t, linkBase{publicURL: "https://tx.example.com"})
+
+	resp, body := getPreview(t, server.URL+"/jack/status/20", "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	if strings.Contains(body, "og:title") {
+		t.Fatalf("browsers must get the UI, got %s", body)
+	}
+	if resp.Header.Get("Vary") != "User-Agent" {
+		t.Fatalf("expected Vary: User-Agent, got %q", resp.Header.Get("Vary"))
+	}
+}
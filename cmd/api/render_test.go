@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGetTweetFormats(t *testing.T) {
+	server := newEmbedServer(t, linkBase{})
+
+	tests := []struct {
+		format      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"text", http.StatusOK, "text/plain; charset=utf-8", "just setting up my twttr"},
+		{"md", http.StatusOK, "text/markdown; charset=utf-8", "just setting up my twttr\n"},
+		{"html", http.StatusOK, "text/html; charset=utf-8", "<p>just setting up my twttr</p>\n"},
+		{"json", http.StatusOK, "application/json", ""},
+		{"pdf", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + "/api/users/jack/tweets/20?format=" + tt.format)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.format, tt.status, resp.StatusCode)
+			continue
+		}
+		if tt.contentType != "" && resp.Header.Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: unexpected content type %q", tt.format, resp.Header.Get("Content-Type"))
+		}
+		if tt.body != "" && string(body) != tt.body {
+			t.Errorf("%s: unexpected body %q", tt.format, body)
+		}
+	}
+}
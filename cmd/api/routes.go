@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/auth"
+	"twitterx-api/pkg/twitterx"
+)
+
+// registerAPIRoutes adds the /api endpoints to api. Every route registered
+// here must be described in internal/openapi/openapi.json.
+func registerAPIRoutes(api *mux.Router, client *twitterx.Client, authenticator *auth.Authenticator, links linkBase, tracked trackers) {
+	requireRead := auth.Require(auth.ScopeRead)
+	requireStream := auth.Require(auth.ScopeStream)
+	requireAdmin := auth.Require(auth.ScopeAdmin)
+
+	api.Handle("/users/{username}/tweets/{id}", requireRead(makeGetTweetHandler(client))).Methods("GET")
+	api.Handle("/users/{username}/tweets", requireRead(makeGetUserTweetsHandler(client))).Methods("GET")
+	api.Handle("/users/{username}/export", requireStream(makeExportHandler(client))).Methods("GET")
+	api.Handle("/users/{username}", requireRead(makeGetUserHandler(client))).Methods("GET")
+	api.Handle("/users/{username}/history", requireRead(makeHistoryHandler(tracked.profiles))).Methods("GET")
+	api.Handle("/users/{username}/stats", requireRead(makeStatsHandler(tracked.profiles))).Methods("GET")
+	api.Handle("/users/{username}/deleted", requireRead(makeDeletedHandler(tracked.deletions))).Methods("GET")
+	api.Handle("/tweets/{id}/metrics", requireRead(makeTweetMetricsHandler(tracked.engagement))).Methods("GET")
+	api.Handle("/oembed", requireRead(makeOEmbedHandler(client, links))).Methods("GET")
+
+	// Unified schema, see internal/apiv2
+	registerV2Routes(api.PathPrefix("/v2").Subrouter(), client)
+
+	// Admin endpoints, only with API keys
+	if authenticator != nil {
+		api.Handle("/admin/log-level", requireAdmin(http.HandlerFunc(handleGetLogLevel))).Methods("GET")
+		api.Handle("/admin/log-level", requireAdmin(http.HandlerFunc(handleSetLogLevel))).Methods("PUT")
+		api.Handle("/admin/usage", requireAdmin(makeUsageHandler(authenticator))).Methods("GET")
+	}
+}
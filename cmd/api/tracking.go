@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/config"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+)
+
+// trackers are the background jobs following the watched accounts. Their
+// endpoints answer 404 for accounts that are not watched.
+type trackers struct {
+	profiles *tracker.ProfileTracker
+	// engagement is only set when TRACK_ENGAGEMENT opts in
+	engagement *tracker.EngagementTracker
+	// deletions is only set when TRACK_DELETIONS opts in
+	deletions *tracker.DeletionTracker
+}
+
+// newTrackers creates the trackers of the accounts of cfg.TrackUsers. They
+// are all nil when no account is watched.
+func newTrackers(cfg *config.Config, client *twitterx.Client) (trackers, error) {
+	var t trackers
+	if len(cfg.TrackUsers) == 0 {
+		return t, nil
+	}
+	opts := []tracker.Option{
+		tracker.WithInterval(cfg.TrackInterval),
+		tracker.WithRetention(cfg.TrackRetention),
+		tracker.WithDataDir(cfg.TrackDataDir),
+	}
+	var err error
+	if t.profiles, err = tracker.NewProfileTracker(client, cfg.TrackUsers, opts...); err != nil {
+		return t, err
+	}
+	if cfg.TrackEngagement {
+		if t.engagement, err = tracker.NewEngagementTracker(client, cfg.TrackUsers, opts...); err != nil {
+			t.Close()
+			return t, err
+		}
+	}
+	if cfg.TrackDeletions {
+		if t.deletions, err = tracker.NewDeletionTracker(client, cfg.TrackUsers, opts...); err != nil {
+			t.Close()
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// run runs the trackers until ctx is done
+func (t trackers) run(ctx context.Context) {
+	go t.profiles.Run(ctx)
+	go t.engagement.Run(ctx)
+	go t.deletions.Run(ctx)
+}
+
+// Close closes the journals of the trackers
+func (t trackers) Close() error {
+	return errors.Join(t.profiles.Close(), t.engagement.Close(), t.deletions.Close())
+}
+
+// makeHistoryHandler serves the change log of a tracked profile
+func makeHistoryHandler(profiles *tracker.ProfileTracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mux.Vars(r)["username"]
+		since, err := parseTimeParam("since", r.URL.Query().Get("since"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		history, err := profiles.History(username, since)
+		if err != nil {
+			logger.DebugContext(r.Context(), "No history for %s: %v", username, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		writeCachedJSON(w, r, history, 0, time.Time{})
+	}
+}
+
+// makeStatsHandler serves the downsampled series of a counter of a tracked
+// profile
+func makeStatsHandler(profiles *tracker.ProfileTracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mux.Vars(r)["username"]
+		query := r.URL.Query()
+
+		metric := query.Get("metric")
+		if metric == "" {
+			metric = tracker.MetricFollowers
+		}
+		since, err := parseTimeParam("since", query.Get("since"))
+		if err != nil {
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		points := tracker.DefaultMaxPoints
+		if value := query.Get("points"); value != "" {
+			if points, err = strconv.Atoi(value); err != nil {
+				apperror.WriteHTTPError(w, &apperror.ValidationError{Field: "points", Message: "must be an integer"})
+				return
+			}
+		}
+
+		series, err := profiles.Stats(username, metric, since, points)
+		if err != nil {
+			logger.DebugContext(r.Context(), "No %s stats for %s: %v", metric, username, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		writeCachedJSON(w, r, series, 0, time.Time{})
+	}
+}
+
+// makeTweetMetricsHandler serves the engagement curves of a tracked tweet
+func makeTweetMetricsHandler(engagement *tracker.EngagementTracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		metrics, err := engagement.Metrics(id)
+		if err != nil {
+			logger.DebugContext(r.Context(), "No metrics for tweet %s: %v", id, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		writeCachedJSON(w, r, metrics, 0, time.Time{})
+	}
+}
+
+// makeDeletedHandler serves the deleted tweets of a watched account
+func makeDeletedHandler(deletions *tracker.DeletionTracker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mux.Vars(r)["username"]
+		deleted, err := deletions.Deleted(username)
+		if err != nil {
+			logger.DebugContext(r.Context(), "No deletions for %s: %v", username, err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		writeCachedJSON(w, r, deleted, 0, time.Time{})
+	}
+}
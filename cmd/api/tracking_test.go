@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/tracker"
+)
+
+// newTrackingServer tracks jack, whose profile was snapshotted once and who
+// deleted no tweet yet
+func newTrackingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	client := newFixtureClient(t, map[string]string{
+		"/jack": `{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12","name":"jack","followers":42}}`,
+	})
+
+	profiles, err := tracker.NewProfileTracker(client, []string{"jack"})
+	if err != nil {
+		t.Fatalf("NewProfileTracker: %v", err)
+	}
+	profiles.Snapshot(context.Background())
+	deletions, err := tracker.NewDeletionTracker(client, []string{"jack"})
+	if err != nil {
+		t.Fatalf("NewDeletionTracker: %v", err)
+	}
+
+	router := mux.NewRouter()
+	registerAPIRoutes(router.PathPrefix("/api").Subrouter(), client, nil, linkBase{}, trackers{profiles: profiles, deletions: deletions})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTrackingEndpoints(t *testing.T) {
+	server := newTrackingServer(t)
+
+	resp, err := http.Get(server.URL + "/api/users/jack/history")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var history tracker.History
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || history.Current["name"] != "jack" || history.TrackedSince == nil {
+		t.Fatalf("unexpected history %d %+v, %v", resp.StatusCode, history, err)
+	}
+
+	resp, err = http.Get(server.URL + "/api/users/JACK/stats?metric=followers&since=2024-01-01")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var series tracker.Series
+	err = json.NewDecoder(resp.Body).Decode(&series)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || len(series.Points) != 1 || series.Points[0].Value != 42 {
+		t.Fatalf("unexpected series %d %+v, %v", resp.StatusCode, series, err)
+	}
+
+	resp, err = http.Get(server.URL + "/api/users/jack/deleted")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	var deleted tracker.Deletions
+	err = json.NewDecoder(resp.Body).Decode(&deleted)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || deleted.Username != "jack" || deleted.Tweets == nil {
+		t.Fatalf("unexpected deletions %d %+v, %v", resp.StatusCode, deleted, err)
+	}
+
+	for path, want := range map[string]int{
+		"/api/users/ev/history":                 http.StatusNotFound,
+		"/api/users/ev/deleted":                 http.StatusNotFound,
+		"/api/users/ev/stats":                   http.StatusNotFound,
+		"/api/tweets/20/metrics":                http.StatusNotFound,
+		"/api/users/jack/stats?metric=bananas":  http.StatusBadRequest,
+		"/api/users/jack/stats?points=many":     http.StatusBadRequest,
+		"/api/users/jack/history?since=someday": http.StatusBadRequest,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: expected status %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apiv2"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+// v2HydrateConcurrency bounds the parallel FxTwitter calls of a timeline page
+const v2HydrateConcurrency = 8
+
+// registerV2Routes adds the /api/v2 endpoints to v2. Unlike /api, they
+// answer errors as JSON.
+func registerV2Routes(v2 *mux.Router, client *twitterx.Client) {
+	requireRead := auth.Require(auth.ScopeRead)
+
+	v2.Handle("/users/{username}", requireRead(makeV2UserHandler(client))).Methods("GET")
+	v2.Handle("/users/{username}/timeline", requireRead(makeV2TimelineHandler(client))).Methods("GET")
+	v2.Handle("/tweets/{id}", requireRead(makeV2TweetHandler(client))).Methods("GET")
+}
+
+// writeV2Error writes err in the error schema of v2
+func writeV2Error(w http.ResponseWriter, err error) {
+	apperror.WriteJSONError(w, err, apiv2.NewError(err))
+}
+
+func makeV2UserHandler(client *twitterx.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mux.Vars(r)["username"]
+		user, err := client.GetUserWithPinnedTweet(r.Context(), username)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error fetching user %s: %v", username, err)
+			writeV2Error(w, err)
+			return
+		}
+		writeCachedJSON(w, r, apiv2.FromUser(user), client.UserCacheTTL(), time.Time{})
+	}
+}
+
+func makeV2TweetHandler(client *twitterx.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ref, err := twitterx.ParseTweetRef(mux.Vars(r)["id"])
+		if err == nil && ref.ID != mux.Vars(r)["id"] {
+			err = &apperror.ValidationError{Field: "id", Message: "not a tweet ID"}
+		}
+		if err != nil {
+			writeV2Error(w, err)
+			return
+		}
+		tweet, err := client.GetTweet(r.Context(), ref.Username, ref.ID)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Error fetching tweet %s: %v", ref.ID, err)
+			writeV2Error(w, err)
+			return
+		}
+		writeCachedJSON(w, r, apiv2.FromTweet(tweet), client.TweetCacheTTL(), time.Time{})
+	}
+}
+
+// makeV2TimelineHandler serves a timeline page with every tweet of the feed
+// merged with its FxTwitter details. Tweets FxTwitter fails to return keep
+// the fields of the feed rather than failing the page.
+func makeV2TimelineHandler(client *twitterx.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		username := mux.Vars(r)["username"]
+		timeline, err := client.GetTimelinePage(ctx, username, r.URL.Query().Get("cursor"))
+		if err != nil {
+			logger.ErrorContext(ctx, "Error fetching timeline of %s: %v", username, err)
+			writeV2Error(w, err)
+			return
+		}
+
+		items := timeline.Tweets
+		ids := make([]string, len(items))
+		for i := range items {
+			ids[i] = items[i].ID
+		}
+		tweets := make([]*apiv2.Tweet, 0, len(items))
+		err = client.HydrateTweets(ctx, username, ids, v2HydrateConcurrency, func(id string, tweet *twitterx.Tweet, err error) error {
+			item := &items[len(tweets)]
+			switch {
+			case err == nil:
+				tweets = append(tweets, apiv2.Merge(item, tweet))
+			case ctx.Err() != nil:
+				return err
+			default:
+				logger.DebugContext(ctx, "Keeping the feed version of tweet %s: %v", id, err)
+				createdAt, ok := twitterx.TweetTime(id)
+				if !ok {
+					createdAt = item.PublishedAt
+				}
+				tweets = append(tweets, apiv2.FromTimelineTweet(item, createdAt))
+			}
+			return nil
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "Error fetching the tweets of %s: %v", username, err)
+			writeV2Error(w, err)
+			return
+		}
+
+		response := apiv2.Timeline{Username: username, Tweets: tweets, NextCursor: timeline.NextCursor}
+		writeCachedJSON(w, r, response, client.TimelineCacheTTL(), timeline.LastModified)
+	}
+}
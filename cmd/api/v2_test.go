@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"twitterx-api/internal/apiv2"
+	"twitterx-api/pkg/twitterx"
+)
+
+// newV2Server serves a timeline of jack with a retweet of ev and a tweet
+// FxTwitter no longer has, and a tweet of a protected account
+func newV2Server(t *testing.T) *httptest.Server {
+	t.Helper()
+	retweet, deleted := tweetID("2024-03-02"), tweetID("2024-03-01")
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<rss><channel>
+<item><title>RT by @jack: hello</title><creator>@ev</creator><description>&lt;p&gt;hello&lt;/p&gt;</description><pubDate>Sat, 02 Mar 2024 10:00:00 GMT</pubDate><guid>https://nitter.net/ev/status/%s#m</guid></item>
+<item><title>gone</title><creator>@jack</creator><description>&lt;p&gt;gone #soon&lt;/p&gt;</description><pubDate>Fri, 01 Mar 2024 10:00:00 GMT</pubDate><guid>https://nitter.net/jack/status/%s#m</guid></item>
+</channel></rss>`, retweet, deleted)
+	}))
+	t.Cleanup(nitter.Close)
+
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/"+tweetID("2024-02-01")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"PRIVATE_TWEET"}`))
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/"+retweet) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"hello","likes":5,"created_at":"Sat Mar 02 09:59:00 +0000 2024","author":{"screen_name":"ev","name":"Ev"}}}`, retweet)
+	}))
+	t.Cleanup(fx.Close)
+
+	client, err := twitterx.New(twitterx.WithNitterInstances(nitter.URL), twitterx.WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	router := mux.NewRouter()
+	registerAPIRoutes(router.PathPrefix("/api").Subrouter(), client, nil, linkBase{}, trackers{})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestV2TimelineMergesSources(t *testing.T) {
+	server := newV2Server(t)
+	resp, err := http.Get(server.URL + "/api/v2/users/jack/timeline")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	var timeline apiv2.Timeline
+	if err := json.NewDecoder(resp.Body).Decode(&timeline); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(timeline.Tweets) != 2 {
+		t.Fatalf("expected 2 tweets, got %+v", timeline)
+	}
+
+	retweet := timeline.Tweets[0]
+	if retweet.RetweetedBy != "jack" || retweet.Author.Name != "Ev" || retweet.Metrics == nil || retweet.Metrics.Likes != 5 {
+		t.Fatalf("unexpected retweet %+v", retweet)
+	}
+	if retweet.Sources["retweeted_by"] != apiv2.SourceNitter || retweet.Sources["metrics"] != apiv2.SourceFxTwitter {
+		t.Fatalf("unexpected retweet sources %v", retweet.Sources)
+	}
+
+	deleted := timeline.Tweets[1]
+	if deleted.Text != "gone #soon" || deleted.Metrics != nil || len(deleted.Entities.Hashtags) != 1 || deleted.Sources["text"] != apiv2.SourceNitter {
+		t.Fatalf("unexpected deleted tweet %+v", deleted)
+	}
+}
+
+func TestV2ErrorsAreJSON(t *testing.T) {
+	server := newV2Server(t)
+	for path, want := range map[string]struct {
+		status int
+		class  string
+	}{
+		"/api/v2/tweets/" + tweetID("2024-03-01"): {http.StatusNotFound, "not_found"},
+		"/api/v2/tweets/" + tweetID("2024-02-01"): {http.StatusForbidden, "protected"},
+		"/api/v2/tweets/abc":                      {http.StatusBadRequest, "validation"},
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		var body apiv2.Error
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil || resp.Header.Get("Content-Type") != "application/json" || resp.StatusCode != want.status || body.Error.Class != want.class {
+			t.Errorf("%s: unexpected error %d %+v (%v)", path, resp.StatusCode, body, err)
+		}
+	}
+
+	// /api keeps answering tweets of protected accounts as upstream failures
+	resp, err := http.Get(server.URL + "/api/users/jack/tweets/" + tweetID("2024-02-01"))
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 from /api, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"twitterx-api/pkg/twitterx"
+)
+
+// searchAuthor stands in for the unknown authors of search results when
+// hydrating them; FxTwitter resolves /i/status/{id} like any other link
+const searchAuthor = "i"
+
+func runUser(ctx context.Context, e *env, args []string) error {
+	fs, f := e.newFlagSet("user", "<username>")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := e.newClient(f)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	user, err := client.GetUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.writeOutput(f.output, func(w io.Writer) error {
+		return writeIndented(w, user)
+	})
+}
+
+func runTweet(ctx context.Context, e *env, args []string) error {
+	fs, f := e.newFlagSet("tweet", "<url|id>")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ref, err := twitterx.ParseTweetRef(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := e.newClient(f)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tweet, err := client.GetTweet(ctx, ref.Username, ref.ID)
+	if err != nil {
+		return err
+	}
+	return e.writeOutput(f.output, func(w io.Writer) error {
+		return writeIndented(w, tweet)
+	})
+}
+
+func runThread(ctx context.Context, e *env, args []string) error {
+	fs, f := e.newFlagSet("thread", "<url|id>")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ref, err := twitterx.ParseTweetRef(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := e.newClient(f)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	thread, err := client.GetThread(ctx, ref.Username, ref.ID)
+	if err != nil {
+		return err
+	}
+	return e.writeOutput(f.output, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		for _, tweet := range thread {
+			if err := enc.Encode(tweet); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func runTimeline(ctx context.Context, e *env, args []string) error {
+	return e.runFeed(ctx, "timeline", "<username>", args, func(client *twitterx.Client, arg string) (fetchPage, string) {
+		return func(ctx context.Context, cursor string) (*twitterx.Timeline, error) {
+			return client.GetTimelinePage(ctx, arg, cursor)
+		}, arg
+	})
+}
+
+func runSearch(ctx context.Context, e *env, args []string) error {
+	return e.runFeed(ctx, "search", "<query>", args, func(client *twitterx.Client, arg string) (fetchPage, string) {
+		return func(ctx context.Context, cursor string) (*twitterx.Timeline, error) {
+			return client.Search(ctx, arg, cursor)
+		}, searchAuthor
+	})
+}
+
+// runFeed lists the tweets of a paginated feed: one ID per line, or one
+// tweet per line as JSON with --hydrate. feed returns the page fetcher for
+// the positional argument and the author used to hydrate its tweets.
+func (e *env) runFeed(ctx context.Context, name, argsUsage string, args []string, feed func(client *twitterx.Client, arg string) (fetchPage, string)) error {
+	fs, f := e.newFlagSet(name, argsUsage)
+	pages := fs.Int("pages", 1, "number of pages to fetch (0 = all)")
+	hydrateTweets := fs.Bool("hydrate", false, "print full tweets as JSON lines instead of IDs")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	client, err := e.newClient(f)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	fetch, author := feed(client, args[0])
+	progress := e.newProgress(f, name+" "+args[0])
+	defer progress.finish()
+
+	return e.writeOutput(f.output, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		count := 0
+		return walk(ctx, fetch, "", *pages, func(page int, timeline *twitterx.Timeline) error {
+			if !*hydrateTweets {
+				for _, id := range timeline.TweetIDs {
+					if _, err := fmt.Fprintln(w, id); err != nil {
+						return err
+					}
+				}
+				count += len(timeline.TweetIDs)
+				progress.report("page %d, %d tweets", page, count)
+				return nil
+			}
+			err := hydrate(ctx, client, author, timeline.TweetIDs, f.concurrency, func(tweet *twitterx.Tweet) error {
+				count++
+				progress.update("page %d, %d tweets", page, count)
+				return enc.Encode(tweet)
+			}, progress.skipped)
+			if err != nil {
+				return err
+			}
+			progress.report("page %d, %d tweets", page, count)
+			return nil
+		})
+	})
+}
+
+// writeOutput calls write with the output file, or stdout when path is
+// empty or "-"
+func (e *env) writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(e.stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeIndented(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"twitterx-api/internal/export"
+	"twitterx-api/pkg/twitterx"
+)
+
+// exportState records how far an export got so it can be resumed. It is
+// kept next to the output file until the export completes, and saved after
+// every page.
+type exportState struct {
+	Username string        `json:"username"`
+	Format   export.Format `json:"format"`
+	// Cursor is the cursor of the page being exported
+	Cursor string `json:"cursor"`
+	// Pages is the number of pages exported completely
+	Pages int `json:"pages"`
+	// Written is the number of tweets of those pages
+	Written int `json:"written"`
+	// Size is the size of the output file after those pages. A resumed
+	// export truncates the file to it, dropping a partly written page.
+	Size int64 `json:"size"`
+}
+
+func loadExportState(path string) (*exportState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state exportState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid export state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// save replaces the state file atomically
+func (s *exportState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func runExport(ctx context.Context, e *env, args []string) error {
+	flags, f := e.newFlagSet("export", "<username>")
+	formatName := flags.String("format", string(export.FormatJSONL), "output format: jsonl, csv or md")
+	pages := flags.Int("pages", 0, "number of pages to export in this run (0 = all)")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	if f.output == "" || f.output == "-" {
+		return errors.New("export needs --output, where its progress is kept for resuming")
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	if format == export.FormatParquet {
+		// A Parquet file ends with its footer, so it can't be appended to
+		return errors.New("parquet exports can't be resumed, use the API's export endpoint")
+	}
+	username := args[0]
+
+	statePath := f.output + ".state"
+	state, err := loadExportState(statePath)
+	if err != nil {
+		return err
+	}
+	resuming := state != nil
+	if resuming && (!strings.EqualFold(state.Username, username) || state.Format != format) {
+		return fmt.Errorf("%s belongs to an export of %s as %s; remove it to start over", statePath, state.Username, state.Format)
+	}
+	if !resuming {
+		state = &exportState{Username: username, Format: format}
+	}
+
+	file, err := os.OpenFile(f.output, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// The tweets past the last saved page are written again
+	if err := file.Truncate(state.Size); err != nil {
+		return err
+	}
+	if _, err := file.Seek(state.Size, io.SeekStart); err != nil {
+		return err
+	}
+	// Whatever stopped an earlier run, the header is there if the file
+	// isn't empty
+	writer, err := export.NewWriter(file, format, state.Size == 0)
+	if err != nil {
+		return err
+	}
+
+	client, err := e.newClient(f)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	progress := e.newProgress(f, "export "+username)
+	defer progress.finish()
+	if resuming {
+		progress.report("resuming after %d pages, %d tweets", state.Pages, state.Written)
+	}
+
+	fetch := func(ctx context.Context, cursor string) (*twitterx.Timeline, error) {
+		return client.GetTimelinePage(ctx, username, cursor)
+	}
+	finished := false
+	err = walk(ctx, fetch, state.Cursor, *pages, func(_ int, timeline *twitterx.Timeline) error {
+		written := 0
+		err := hydrate(ctx, client, username, timeline.TweetIDs, f.concurrency, func(tweet *twitterx.Tweet) error {
+			if err := writer.Write(export.FromTweet(tweet)); err != nil {
+				return err
+			}
+			written++
+			progress.update("page %d, %d tweets", state.Pages+1, state.Written+written)
+			return nil
+		}, progress.skipped)
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			return err
+		}
+		size, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		state.Pages++
+		state.Written += written
+		state.Size = size
+		finished = timeline.NextCursor == "" || timeline.NextCursor == state.Cursor
+		state.Cursor = timeline.NextCursor
+		progress.report("page %d, %d tweets", state.Pages, state.Written)
+		return state.save(statePath)
+	})
+	if err != nil {
+		progress.finish()
+		fmt.Fprintf(e.stderr, "export %s: stopped after %d tweets, run the same command to resume\n", username, state.Written)
+		return err
+	}
+	// The state file resumes the export, not an in-band cursor
+	if err := writer.Close(""); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	progress.finish()
+	if !finished {
+		fmt.Fprintf(e.stderr, "export %s: %d tweets written to %s, run the same command to export more pages\n", username, state.Written, f.output)
+		return nil
+	}
+	if err := os.Remove(statePath); err != nil {
+		return err
+	}
+	if !f.quiet {
+		fmt.Fprintf(e.stderr, "export %s: %d tweets written to %s\n", username, state.Written, f.output)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"twitterx-api/pkg/twitterx"
+)
+
+// fetchPage fetches the page of a feed that starts at cursor
+type fetchPage func(ctx context.Context, cursor string) (*twitterx.Timeline, error)
+
+// walk calls fn with up to pages pages of a feed (0 = all), starting at
+// cursor. Pages are numbered from 1.
+func walk(ctx context.Context, fetch fetchPage, cursor string, pages int, fn func(page int, timeline *twitterx.Timeline) error) error {
+	for page := 1; pages <= 0 || page <= pages; page++ {
+		timeline, err := fetch(ctx, cursor)
+		if err != nil {
+			return err
+		}
+		if err := fn(page, timeline); err != nil {
+			return err
+		}
+		if timeline.NextCursor == "" || timeline.NextCursor == cursor {
+			return nil
+		}
+		cursor = timeline.NextCursor
+	}
+	return nil
+}
+
+// hydrate passes the tweets ids to fn in order. Tweets that no longer exist
+// are passed to skipped instead.
+func hydrate(ctx context.Context, client *twitterx.Client, username string, ids []string, concurrency int, fn func(tweet *twitterx.Tweet) error, skipped func(id string, err error)) error {
+	return client.HydrateTweets(ctx, username, ids, concurrency, func(id string, tweet *twitterx.Tweet, err error) error {
+		if twitterx.IsNotFound(err) {
+			skipped(id, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(tweet)
+	})
+}
+
+// progress reports how far a command got on stderr
+type progress struct {
+	w     io.Writer
+	label string
+	// tty redraws a single status line
+	tty   bool
+	drawn bool
+}
+
+func (e *env) newProgress(f *commonFlags, label string) *progress {
+	if f.quiet {
+		return &progress{}
+	}
+	return &progress{w: e.stderr, label: label, tty: e.progressTTY}
+}
+
+// update redraws the status line on a terminal and is dropped otherwise
+func (p *progress) update(format string, args ...any) {
+	if p.w == nil || !p.tty {
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[K%s: %s", p.label, fmt.Sprintf(format, args...))
+	p.drawn = true
+}
+
+// report shows a milestone, as a new line when not on a terminal
+func (p *progress) report(format string, args ...any) {
+	if p.w == nil {
+		return
+	}
+	if p.tty {
+		p.update(format, args...)
+		return
+	}
+	fmt.Fprintf(p.w, "%s: %s\n", p.label, fmt.Sprintf(format, args...))
+}
+
+// skipped reports a tweet that could not be fetched
+func (p *progress) skipped(id string, err error) {
+	if p.w == nil {
+		return
+	}
+	p.finish()
+	fmt.Fprintf(p.w, "%s: skipping tweet %s: %v\n", p.label, id, err)
+}
+
+// finish ends the status line
+func (p *progress) finish() {
+	if p.drawn {
+		fmt.Fprintln(p.w)
+		p.drawn = false
+	}
+}
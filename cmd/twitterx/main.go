@@ -0,0 +1,205 @@
+// Command twitterx fetches profiles, timelines and tweets from the terminal
+// and exports timelines to files, using the same upstream clients as the API
+// server. Upstreams are configured with the server's environment variables
+// (NITTER_URL, UPSTREAM_PROXY, ...).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"twitterx-api/internal/config"
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+const usage = `Usage: twitterx <command> [flags] <args>
+
+Commands:
+  user <username>                 print a user profile
+  tweet <url|id>                  print a tweet
+  thread <url|id>                 print a tweet and the replies it answers
+  timeline <username>             list the tweets of a user
+  search <query>                  list the tweets matching a query
+  export <username>               export the timeline of a user to a file
+
+Run "twitterx <command> -h" for the flags of a command.
+`
+
+// commands maps the subcommand names to their implementation
+var commands = map[string]func(ctx context.Context, e *env, args []string) error{
+	"user":     runUser,
+	"tweet":    runTweet,
+	"thread":   runThread,
+	"timeline": runTimeline,
+	"search":   runSearch,
+	"export":   runExport,
+}
+
+// errUsage reports invalid arguments; the usage was already printed
+var errUsage = errors.New("invalid usage")
+
+// env holds what commands share
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+	cfg    *config.Config
+	// progressTTY redraws progress in place instead of printing lines
+	progressTTY bool
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "twitterx: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command line args
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return nil
+	}
+
+	runCommand, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "twitterx: unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+
+	// Logs would interleave with the output, so only problems are shown
+	// unless LOG_LEVEL asks for more
+	if os.Getenv("LOG_LEVEL") == "" {
+		_ = logger.SetLevel("warn")
+	}
+	cfg, err := config.LoadUpstream()
+	if err != nil {
+		return err
+	}
+
+	e := &env{stdout: stdout, stderr: stderr, cfg: cfg, progressTTY: isTerminal(stderr)}
+	if err := runCommand(ctx, e, args[1:]); !errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return nil
+}
+
+// commonFlags are accepted by every command
+type commonFlags struct {
+	output      string
+	concurrency int
+	nitter      string
+	fxTwitter   string
+	quiet       bool
+}
+
+// newFlagSet creates the flag set of a command with the common flags.
+// argsUsage describes the positional arguments.
+func (e *env) newFlagSet(name, argsUsage string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: twitterx %s [flags] %s\n\nFlags:\n", name, argsUsage)
+		fs.PrintDefaults()
+	}
+
+	f := &commonFlags{}
+	fs.StringVar(&f.output, "output", "", "write to `file` instead of stdout")
+	fs.StringVar(&f.output, "o", "", "shorthand for --output")
+	fs.IntVar(&f.concurrency, "concurrency", 4, "maximum number of parallel tweet fetches")
+	fs.StringVar(&f.nitter, "nitter", "", "comma-separated Nitter `urls` (default NITTER_URL)")
+	fs.StringVar(&f.fxTwitter, "fxtwitter", "", "base `url` of the FxTwitter API")
+	fs.BoolVar(&f.quiet, "quiet", false, "don't report progress")
+	return fs, f
+}
+
+// parseArgs parses flags placed before, between and after the positional
+// arguments and checks that there are exactly want of them
+func parseArgs(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != want {
+		fmt.Fprintf(fs.Output(), "twitterx %s: expected %d argument(s), got %d\n", fs.Name(), want, len(positional))
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// newClient creates the upstream client from the environment and flags
+func (e *env) newClient(f *commonFlags) (*twitterx.Client, error) {
+	nitterURLs := e.cfg.NitterURLs
+	if f.nitter != "" {
+		nitterURLs = nil
+		for _, u := range strings.Split(f.nitter, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				nitterURLs = append(nitterURLs, strings.TrimRight(u, "/"))
+			}
+		}
+	}
+
+	opts := []twitterx.Option{
+		twitterx.WithNitterInstances(nitterURLs...),
+		twitterx.WithTransport(twitterx.TransportConfig{
+			Proxy:               e.cfg.UpstreamProxy,
+			UserAgent:           e.cfg.UpstreamUserAgent,
+			MaxIdleConns:        e.cfg.UpstreamMaxIdleConns,
+			MaxIdleConnsPerHost: e.cfg.UpstreamMaxIdleConnsPerHost,
+			IdleConnTimeout:     e.cfg.UpstreamIdleConnTimeout,
+			Compression:         e.cfg.UpstreamCompression,
+			MaxResponseSize:     int64(e.cfg.UpstreamMaxResponseSize),
+		}),
+		twitterx.WithRetryPolicy(twitterx.RetryPolicy{
+			MaxAttempts: e.cfg.RetryAttempts,
+			BaseDelay:   e.cfg.RetryBaseDelay,
+			MaxDelay:    e.cfg.RetryMaxDelay,
+		}),
+		twitterx.WithCircuitBreaker(e.cfg.BreakerThreshold, e.cfg.BreakerOpenTimeout),
+	}
+	if f.fxTwitter != "" {
+		opts = append(opts, twitterx.WithFxTwitterURL(f.fxTwitter))
+	}
+	return twitterx.New(opts...)
+}
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
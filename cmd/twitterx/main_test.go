@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newUpstreams serves a two page timeline of jack (tweets 3, 2 then 1) and
+// the tweets and profile through a fake FxTwitter
+func newUpstreams(t *testing.T) []string {
+	t.Helper()
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		item := func(id string) string {
+			return fmt.Sprintf(`<item><guid>https://nitter.net/jack/status/%s#m</guid></item>`, id)
+		}
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set("Min-Id", "c2")
+			fmt.Fprintf(w, `<rss><channel>%s%s</channel></rss>`, item("3"), item("2"))
+		case "c2":
+			fmt.Fprintf(w, `<rss><channel>%s</channel></rss>`, item("1"))
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+	}))
+	t.Cleanup(nitter.Close)
+
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jack" {
+			w.Write([]byte(`{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12"}}`))
+			return
+		}
+		var id string
+		if _, err := fmt.Sscanf(r.URL.Path, "/jack/status/%s", &id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"tweet %s","author":{"screen_name":"jack"}}}`, id, id)
+	}))
+	t.Cleanup(fx.Close)
+
+	return []string{"--nitter", nitter.URL, "--fxtwitter", fx.URL}
+}
+
+func runCLI(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRunUser(t *testing.T) {
+	upstreams := newUpstreams(t)
+	stdout, _, err := runCLI(t, append([]string{"user", "jack"}, upstreams...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &user); err != nil || user.ID != "12" {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+}
+
+func TestRunTweetFromURL(t *testing.T) {
+	upstreams := newUpstreams(t)
+	stdout, _, err := runCLI(t, append([]string{"tweet", "https://x.com/jack/status/2"}, upstreams...)...)
+	if err != nil || !strings.Contains(stdout, `"text": "tweet 2"`) {
+		t.Fatalf("unexpected output %q, %v", stdout, err)
+	}
+}
+
+func TestRunTimeline(t *testing.T) {
+	upstreams := newUpstreams(t)
+
+	stdout, stderr, err := runCLI(t, append([]string{"timeline", "jack", "--pages", "0"}, upstreams...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout != "3\n2\n1\n" {
+		t.Fatalf("unexpected IDs %q", stdout)
+	}
+	if !strings.Contains(stderr, "timeline jack: page 2, 3 tweets") {
+		t.Fatalf("expected progress, got %q", stderr)
+	}
+
+	stdout, _, err = runCLI(t, append([]string{"timeline", "--hydrate", "jack", "--concurrency", "2", "--quiet"}, upstreams...)...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"id":"3"`) || !strings.Contains(lines[1], `"id":"2"`) {
+		t.Fatalf("expected tweets 3 and 2 in order, got %q", stdout)
+	}
+}
+
+func TestRunExportResumes(t *testing.T) {
+	upstreams := newUpstreams(t)
+	output := filepath.Join(t.TempDir(), "jack.csv")
+	args := append([]string{"export", "jack", "--format", "csv", "--output", output}, upstreams...)
+
+	if _, _, err := runCLI(t, append(args, "--pages", "1")...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := loadExportState(output + ".state")
+	if err != nil || state == nil || state.Cursor != "c2" || state.Written != 2 {
+		t.Fatalf("unexpected state %+v, %v", state, err)
+	}
+
+	if _, _, err := runCLI(t, args...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(output + ".state"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the state file to be removed, got %v", err)
+	}
+	assertExportedIDs(t, output, "3", "2", "1")
+}
+
+func TestRunExportRewritesPartialPage(t *testing.T) {
+	upstreams := newUpstreams(t)
+	output := filepath.Join(t.TempDir(), "jack.csv")
+
+	// An earlier run was interrupted while writing the first page
+	os.WriteFile(output, []byte("id,url\n3,https://x.co"), 0o644)
+	state := &exportState{Username: "jack", Format: "csv"}
+	if err := state.save(output + ".state"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if _, _, err := runCLI(t, append([]string{"export", "jack", "--format", "csv", "-o", output}, upstreams...)...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertExportedIDs(t, output, "3", "2", "1")
+
+	// A state file of another export is not reused
+	state.save(output + ".state")
+	if _, _, err := runCLI(t, append([]string{"export", "biz", "--format", "csv", "-o", output}, upstreams...)...); err == nil {
+		t.Fatal("expected an error for a mismatched state file")
+	}
+}
+
+func TestRunExportKeepsSavedPages(t *testing.T) {
+	upstreams := newUpstreams(t)
+	output := filepath.Join(t.TempDir(), "jack.csv")
+	args := append([]string{"export", "jack", "--format", "csv", "--output", output}, upstreams...)
+
+	if _, _, err := runCLI(t, append(args, "--pages", "1")...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Part of the next page made it to the file before an interruption
+	file, _ := os.OpenFile(output, os.O_WRONLY|os.O_APPEND, 0o644)
+	file.WriteString("1,https://x.com/jack/status/1\n")
+	file.Close()
+
+	if _, _, err := runCLI(t, args...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertExportedIDs(t, output, "3", "2", "1")
+}
+
+func assertExportedIDs(t *testing.T, path string, want ...string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	var ids []string
+	for _, record := range records[1:] {
+		ids = append(ids, record[0])
+	}
+	if records[0][0] != "id" || strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected CSV %v", records)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	if _, _, err := runCLI(t); !errors.Is(err, errUsage) {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+	if _, _, err := runCLI(t, "unknown"); !errors.Is(err, errUsage) {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+	if _, _, err := runCLI(t, "user"); !errors.Is(err, errUsage) {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+	if _, stderr, err := runCLI(t, "timeline", "-h"); err != nil || !strings.Contains(stderr, "-hydrate") {
+		t.Fatalf("expected help, got %q, %v", stderr, err)
+	}
+	if _, _, err := runCLI(t, "export", "jack"); err == nil || !strings.Contains(err.Error(), "--output") {
+		t.Fatalf("expected --output to be required, got %v", err)
+	}
+}
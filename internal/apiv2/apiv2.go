@@ -0,0 +1,264 @@
+// Package apiv2 defines the schema of the /api/v2 endpoints. Responses carry
+// no upstream envelope: tweets merge the facts only the Nitter feed knows,
+// like retweets and pinned tweets, with the details of FxTwitter, and every
+// object lists which source each of its fields came from.
+package apiv2
+
+import (
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/models"
+)
+
+// Sources of fields
+const (
+	SourceNitter    = "nitter"
+	SourceFxTwitter = "fxtwitter"
+)
+
+// Sources maps the JSON name of every field that is set to the upstream it
+// came from
+type Sources map[string]string
+
+func (s Sources) set(source string, fields ...string) {
+	for _, f := range fields {
+		s[f] = source
+	}
+}
+
+// User is a profile
+type User struct {
+	ID               string     `json:"id"`
+	ScreenName       string     `json:"screen_name"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	Location         string     `json:"location"`
+	URL              string     `json:"url"`
+	Website          *string    `json:"website"`
+	AvatarURL        string     `json:"avatar_url"`
+	BannerURL        string     `json:"banner_url"`
+	Joined           string     `json:"joined"`
+	Protected        bool       `json:"protected"`
+	Verified         bool       `json:"verified"`
+	VerificationType string     `json:"verification_type,omitempty"`
+	Counts           UserCounts `json:"counts"`
+	PinnedTweetID    string     `json:"pinned_tweet_id,omitempty"`
+	Sources          Sources    `json:"sources"`
+}
+
+// UserCounts are the counters of a profile
+type UserCounts struct {
+	Followers int64 `json:"followers"`
+	Following int64 `json:"following"`
+	Tweets    int64 `json:"tweets"`
+	Likes     int64 `json:"likes"`
+	Media     int64 `json:"media"`
+}
+
+// Tweet is a tweet. Tweets of a timeline that FxTwitter could not return
+// only carry the fields of the Nitter feed.
+type Tweet struct {
+	ID                string              `json:"id"`
+	URL               string              `json:"url"`
+	Text              string              `json:"text"`
+	Entities          *models.Entities    `json:"entities"`
+	Author            Author              `json:"author"`
+	CreatedAt         time.Time           `json:"created_at"`
+	Lang              string              `json:"lang,omitempty"`
+	Metrics           *Metrics            `json:"metrics,omitempty"`
+	Media             []models.MediaItem  `json:"media"`
+	Poll              *models.Poll        `json:"poll,omitempty"`
+	Translation       *models.Translation `json:"translation,omitempty"`
+	Quote             *Tweet              `json:"quote,omitempty"`
+	ReplyTo           *ReplyTo            `json:"reply_to,omitempty"`
+	PossiblySensitive bool                `json:"possibly_sensitive"`
+	// PublishedAt is the pubDate of the feed item
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// RetweetedBy is the owner of the timeline when the tweet is a retweet
+	RetweetedBy string  `json:"retweeted_by,omitempty"`
+	Pinned      bool    `json:"pinned,omitempty"`
+	Sources     Sources `json:"sources"`
+}
+
+// Author is the author of a tweet. Only the screen name is known for tweets
+// of the Nitter feed.
+type Author struct {
+	ID         string `json:"id,omitempty"`
+	ScreenName string `json:"screen_name"`
+	Name       string `json:"name,omitempty"`
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	Verified   bool   `json:"verified"`
+}
+
+// Metrics are the engagement counts of a tweet
+type Metrics struct {
+	Replies  int64  `json:"replies"`
+	Retweets int64  `json:"retweets"`
+	Likes    int64  `json:"likes"`
+	Views    *int64 `json:"views"`
+}
+
+// ReplyTo is the tweet a reply answers
+type ReplyTo struct {
+	ScreenName string `json:"screen_name,omitempty"`
+	TweetID    string `json:"tweet_id"`
+}
+
+// Timeline is a page of the tweets of a user, newest first
+type Timeline struct {
+	Username string   `json:"username"`
+	Tweets   []*Tweet `json:"tweets"`
+	// NextCursor requests the following, older page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Error is the body of error responses
+type Error struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes an error by its class, as returned by
+// apperror.Class, and a message
+type ErrorDetail struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+// NewError describes err
+func NewError(err error) *Error {
+	return &Error{ErrorDetail{Class: apperror.Class(err), Message: err.Error()}}
+}
+
+// FromUser converts a FxTwitter profile, with the pinned tweet of the
+// Nitter feed when it is set
+func FromUser(u *models.User) *User {
+	user := &User{
+		ID:          u.ID,
+		ScreenName:  u.ScreenName,
+		Name:        u.Name,
+		Description: u.Description,
+		Location:    u.Location,
+		URL:         u.URL,
+		Website:     u.Website,
+		AvatarURL:   u.AvatarURL,
+		BannerURL:   u.BannerURL,
+		Joined:      u.Joined,
+		Protected:   u.Protected,
+		Counts: UserCounts{
+			Followers: u.Followers,
+			Following: u.Following,
+			Tweets:    u.Tweets,
+			Likes:     u.Likes,
+			Media:     u.MediaCount,
+		},
+		Sources: Sources{},
+	}
+	user.Sources.set(SourceFxTwitter, "id", "screen_name", "name", "description", "location", "url", "website",
+		"avatar_url", "banner_url", "joined", "protected", "verified", "counts")
+	if u.Verification != nil {
+		user.Verified = u.Verification.Verified
+		user.VerificationType = u.Verification.Type
+		user.Sources.set(SourceFxTwitter, "verification_type")
+	}
+	if u.PinnedTweetID != "" {
+		user.PinnedTweetID = u.PinnedTweetID
+		user.Sources.set(SourceNitter, "pinned_tweet_id")
+	}
+	return user
+}
+
+// FromTweet converts a FxTwitter tweet
+func FromTweet(t *models.Tweet) *Tweet {
+	tweet := &Tweet{
+		ID:       t.ID,
+		URL:      t.URL,
+		Text:     t.Text,
+		Entities: t.Entities,
+		Author: Author{
+			ID:         t.Author.ID,
+			ScreenName: t.Author.ScreenName,
+			Name:       t.Author.Name,
+			AvatarURL:  t.Author.AvatarURL,
+			Verified:   t.Author.Verified || t.Author.BlueBadge,
+		},
+		CreatedAt: t.CreatedAt.Time.UTC(),
+		Lang:      t.Lang,
+		Metrics: &Metrics{
+			Replies:  t.Replies,
+			Retweets: t.Retweets,
+			Likes:    t.Likes,
+			Views:    t.Views,
+		},
+		Media:             []models.MediaItem{},
+		Poll:              t.Poll,
+		Translation:       t.Translation,
+		PossiblySensitive: t.PossiblySensitive,
+		Sources:           Sources{},
+	}
+	tweet.Sources.set(SourceFxTwitter, "id", "url", "text", "entities", "author", "created_at", "lang", "metrics",
+		"media", "possibly_sensitive")
+	if t.Media != nil {
+		tweet.Media = append(tweet.Media, t.Media.All...)
+	}
+	if t.Poll != nil {
+		tweet.Sources.set(SourceFxTwitter, "poll")
+	}
+	if t.Translation != nil {
+		tweet.Sources.set(SourceFxTwitter, "translation")
+	}
+	if t.Quote != nil {
+		tweet.Quote = FromTweet(t.Quote)
+		tweet.Sources.set(SourceFxTwitter, "quote")
+	}
+	if t.ReplyingToStatus != nil && *t.ReplyingToStatus != "" {
+		tweet.ReplyTo = &ReplyTo{TweetID: *t.ReplyingToStatus}
+		if t.ReplyingTo != nil {
+			tweet.ReplyTo.ScreenName = *t.ReplyingTo
+		}
+		tweet.Sources.set(SourceFxTwitter, "reply_to")
+	}
+	return tweet
+}
+
+// FromTimelineTweet converts a tweet of the Nitter feed. createdAt is
+// derived from the tweet ID by the caller.
+func FromTimelineTweet(item *models.TimelineTweet, createdAt time.Time) *Tweet {
+	tweet := &Tweet{
+		ID:        item.ID,
+		URL:       "https://x.com/" + item.Author + "/status/" + item.ID,
+		Text:      item.Text,
+		Entities:  item.Entities,
+		Author:    Author{ScreenName: item.Author},
+		CreatedAt: createdAt.UTC(),
+		Media:     []models.MediaItem{},
+		Sources:   Sources{},
+	}
+	tweet.Sources.set(SourceNitter, "id", "url", "text", "entities", "author", "created_at")
+	addNitterFacts(tweet, item)
+	return tweet
+}
+
+// Merge converts a FxTwitter tweet and adds the facts the Nitter feed
+// published about it
+func Merge(item *models.TimelineTweet, t *models.Tweet) *Tweet {
+	tweet := FromTweet(t)
+	addNitterFacts(tweet, item)
+	return tweet
+}
+
+func addNitterFacts(tweet *Tweet, item *models.TimelineTweet) {
+	if !item.PublishedAt.IsZero() {
+		published := item.PublishedAt.UTC()
+		tweet.PublishedAt = &published
+		tweet.Sources.set(SourceNitter, "published_at")
+	}
+	if item.RetweetedBy != "" {
+		tweet.RetweetedBy = item.RetweetedBy
+		tweet.Sources.set(SourceNitter, "retweeted_by")
+	}
+	if item.Pinned {
+		tweet.Pinned = true
+		tweet.Sources.set(SourceNitter, "pinned")
+	}
+}
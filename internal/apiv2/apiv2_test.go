@@ -0,0 +1,59 @@
+package apiv2
+
+import (
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/models"
+)
+
+func TestMergeMarksSources(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := &models.TimelineTweet{ID: "20", Author: "jack", Text: "hi", PublishedAt: published, RetweetedBy: "ev"}
+	replyTo := "1"
+	tweet := Merge(item, &models.Tweet{ID: "20", Text: "hi", Author: models.Author{ScreenName: "jack"}, Likes: 3, ReplyingToStatus: &replyTo})
+
+	if tweet.RetweetedBy != "ev" || tweet.PublishedAt == nil || !tweet.PublishedAt.Equal(published) || tweet.Metrics.Likes != 3 {
+		t.Fatalf("unexpected tweet %+v", tweet)
+	}
+	want := map[string]string{"text": SourceFxTwitter, "metrics": SourceFxTwitter, "reply_to": SourceFxTwitter,
+		"retweeted_by": SourceNitter, "published_at": SourceNitter}
+	for field, source := range want {
+		if tweet.Sources[field] != source {
+			t.Errorf("expected %s from %s, got %q", field, source, tweet.Sources[field])
+		}
+	}
+	if _, ok := tweet.Sources["pinned"]; ok {
+		t.Error("pinned is not set and must have no source")
+	}
+}
+
+func TestFromTimelineTweet(t *testing.T) {
+	item := &models.TimelineTweet{ID: "20", Author: "jack", Text: "hi", Pinned: true}
+	tweet := FromTimelineTweet(item, time.Unix(0, 0))
+	if tweet.Metrics != nil || tweet.URL != "https://x.com/jack/status/20" || !tweet.Pinned {
+		t.Fatalf("unexpected tweet %+v", tweet)
+	}
+	if tweet.Sources["text"] != SourceNitter || tweet.Sources["pinned"] != SourceNitter {
+		t.Fatalf("unexpected sources %v", tweet.Sources)
+	}
+}
+
+func TestFromUserPinnedTweet(t *testing.T) {
+	user := FromUser(&models.User{ID: "12", ScreenName: "jack"})
+	if _, ok := user.Sources["pinned_tweet_id"]; ok || user.PinnedTweetID != "" {
+		t.Fatalf("unexpected pinned tweet %+v", user)
+	}
+	user = FromUser(&models.User{ID: "12", ScreenName: "jack", PinnedTweetID: "20"})
+	if user.PinnedTweetID != "20" || user.Sources["pinned_tweet_id"] != SourceNitter || user.Sources["id"] != SourceFxTwitter {
+		t.Fatalf("unexpected user %+v", user)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	e := NewError(&apperror.NotFoundError{Resource: "tweet", ID: "1"})
+	if e.Error.Class != apperror.ClassNotFound || e.Error.Message != "tweet '1' not found" {
+		t.Fatalf("unexpected error %+v", e)
+	}
+}
@@ -1,9 +1,16 @@
 package apperror
 
 import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // ValidationError represents invalid input from the client
@@ -16,16 +23,53 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s - %s", e.Field, e.Message)
 }
 
-// NotFoundError represents a resource that was not found
+// NotFoundError represents a resource that was not found. Reason, when set,
+// is ErrProtected or ErrSuspended for resources that exist but are hidden.
 type NotFoundError struct {
 	Resource string
 	ID       string
+	Reason   error
 }
 
 func (e *NotFoundError) Error() string {
+	if e.Reason != nil {
+		return fmt.Sprintf("%s '%s' not found: %v", e.Resource, e.ID, e.Reason)
+	}
 	return fmt.Sprintf("%s '%s' not found", e.Resource, e.ID)
 }
 
+func (e *NotFoundError) Unwrap() error {
+	return e.Reason
+}
+
+// UnauthorizedError represents a request without valid credentials
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return fmt.Sprintf("unauthorized: %s", e.Message)
+}
+
+// ForbiddenError represents a request whose credentials lack a required permission
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %s", e.Message)
+}
+
+// RateLimitError represents a request rejected by a rate limit or quota
+type RateLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited: %s", e.Message)
+}
+
 // UpstreamError represents an error from an upstream service (Nitter, FxTwitter)
 type UpstreamError struct {
 	Service    string
@@ -45,15 +89,58 @@ func (e *UpstreamError) Unwrap() error {
 	return e.Err
 }
 
-// HTTPStatusCode returns the appropriate HTTP status code for the error
+// ErrSessionsExhausted is wrapped by an UpstreamError when a Nitter instance
+// has no usable sessions left
+var ErrSessionsExhausted = errors.New("sessions exhausted")
+
+// ErrCircuitOpen is returned without calling an upstream whose circuit
+// breaker is open after repeated failures
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrResponseTooLarge is returned when an upstream response exceeds the
+// configured size cap
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrProtected is the Reason of a NotFoundError for a tweet or account only
+// the followers of a protected account can see
+var ErrProtected = errors.New("account protected")
+
+// ErrSuspended is the Reason of a NotFoundError for an account that was
+// suspended
+var ErrSuspended = errors.New("account suspended")
+
+// StatusClientClosedRequest is returned when the client went away before the response was ready
+const StatusClientClosedRequest = 499
+
+// HTTPStatusCode returns the appropriate HTTP status code for the error, as
+// answered by the stable /api endpoints. Tweets of protected accounts keep
+// the 502 of the FxTwitter error they used to surface as, and suspended
+// accounts the 404 of a missing one; DetailedHTTPStatusCode tells them apart.
 func HTTPStatusCode(err error) int {
 	var validationErr *ValidationError
 	var notFoundErr *NotFoundError
 	var upstreamErr *UpstreamError
+	var unauthorizedErr *UnauthorizedError
+	var forbiddenErr *ForbiddenError
+	var rateLimitErr *RateLimitError
 
 	switch {
+	case errors.As(err, &unauthorizedErr):
+		return http.StatusUnauthorized
+	case errors.As(err, &forbiddenErr):
+		return http.StatusForbidden
+	case errors.As(err, &rateLimitErr):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, ErrSessionsExhausted), errors.Is(err, ErrCircuitOpen):
+		return http.StatusServiceUnavailable
 	case errors.As(err, &validationErr):
 		return http.StatusBadRequest
+	case errors.Is(err, ErrProtected):
+		return http.StatusBadGateway
 	case errors.As(err, &notFoundErr):
 		return http.StatusNotFound
 	case errors.As(err, &upstreamErr):
@@ -62,3 +149,118 @@ func HTTPStatusCode(err error) int {
 		return http.StatusInternalServerError
 	}
 }
+
+// DetailedHTTPStatusCode returns the status code of HTTPStatusCode, except
+// for hidden resources: 403 when the account is protected and 410 when it
+// was suspended. It serves /api/v2 and gRPC.
+func DetailedHTTPStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrProtected):
+		return http.StatusForbidden
+	case errors.Is(err, ErrSuspended):
+		return http.StatusGone
+	default:
+		return HTTPStatusCode(err)
+	}
+}
+
+// Error classes returned by Class
+const (
+	ClassValidation     = "validation"
+	ClassNotFound       = "not_found"
+	ClassProtected      = "protected"
+	ClassSuspended      = "suspended"
+	ClassTimeout        = "timeout"
+	ClassCanceled       = "canceled"
+	ClassNetwork        = "network"
+	ClassParse          = "parse"
+	ClassUpstreamStatus = "upstream_status"
+	ClassExhausted      = "sessions_exhausted"
+	ClassRateLimited    = "rate_limited"
+	ClassCircuitOpen    = "circuit_open"
+	ClassTooLarge       = "response_too_large"
+	ClassUpstream       = "upstream"
+	ClassInternal       = "internal"
+)
+
+// Class returns a short, stable name for the kind of err, suitable for
+// metric labels. It returns an empty string for a nil error.
+func Class(err error) string {
+	var validationErr *ValidationError
+	var notFoundErr *NotFoundError
+	var upstreamErr *UpstreamError
+	var netErr net.Error
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var xmlSyntaxErr *xml.SyntaxError
+	var rateLimitErr *RateLimitError
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &rateLimitErr):
+		return ClassRateLimited
+	case errors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.Is(err, ErrSessionsExhausted):
+		return ClassExhausted
+	case errors.Is(err, ErrCircuitOpen):
+		return ClassCircuitOpen
+	case errors.Is(err, ErrResponseTooLarge):
+		return ClassTooLarge
+	case errors.As(err, &validationErr):
+		return ClassValidation
+	case errors.Is(err, ErrProtected):
+		return ClassProtected
+	case errors.Is(err, ErrSuspended):
+		return ClassSuspended
+	case errors.As(err, &notFoundErr):
+		return ClassNotFound
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ClassTimeout
+		}
+		return ClassNetwork
+	case errors.As(err, &jsonSyntaxErr), errors.As(err, &jsonTypeErr), errors.As(err, &xmlSyntaxErr):
+		return ClassParse
+	case errors.As(err, &upstreamErr):
+		if upstreamErr.StatusCode != 0 {
+			return ClassUpstreamStatus
+		}
+		return ClassUpstream
+	default:
+		return ClassInternal
+	}
+}
+
+// WriteHTTPError writes err as a plain-text response with the matching
+// status code. Rate limit errors also set the Retry-After header.
+func WriteHTTPError(w http.ResponseWriter, err error) {
+	setErrorHeaders(w, err)
+	http.Error(w, err.Error(), HTTPStatusCode(err))
+}
+
+// WriteJSONError writes body, which describes err, as a JSON response with
+// the status code of DetailedHTTPStatusCode and the headers of
+// WriteHTTPError
+func WriteJSONError(w http.ResponseWriter, err error, body any) {
+	setErrorHeaders(w, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(DetailedHTTPStatusCode(err))
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func setErrorHeaders(w http.ResponseWriter, err error) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		seconds := int64(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	var unauthorizedErr *UnauthorizedError
+	if errors.As(err, &unauthorizedErr) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="twitterx-api"`)
+	}
+}
@@ -1,9 +1,15 @@
 package apperror
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestHTTPStatusCodeMapping(t *testing.T) {
@@ -13,10 +19,81 @@ func TestHTTPStatusCodeMapping(t *testing.T) {
 	if code := HTTPStatusCode(&NotFoundError{}); code != http.StatusNotFound {
 		t.Fatalf("expected 404, got %d", code)
 	}
+	if code := HTTPStatusCode(&NotFoundError{Reason: ErrProtected}); code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", code)
+	}
+	if code := HTTPStatusCode(&NotFoundError{Reason: ErrSuspended}); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+	if code := DetailedHTTPStatusCode(&NotFoundError{Reason: ErrProtected}); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+	if code := DetailedHTTPStatusCode(&NotFoundError{Reason: ErrSuspended}); code != http.StatusGone {
+		t.Fatalf("expected 410, got %d", code)
+	}
+	if code := DetailedHTTPStatusCode(&NotFoundError{}); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
 	if code := HTTPStatusCode(&UpstreamError{}); code != http.StatusBadGateway {
 		t.Fatalf("expected 502, got %d", code)
 	}
+	if code := HTTPStatusCode(&UpstreamError{Err: context.DeadlineExceeded}); code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", code)
+	}
+	if code := HTTPStatusCode(&UpstreamError{Err: context.Canceled}); code != StatusClientClosedRequest {
+		t.Fatalf("expected 499, got %d", code)
+	}
+	if code := HTTPStatusCode(&UpstreamError{Err: ErrSessionsExhausted}); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", code)
+	}
+	if code := HTTPStatusCode(&UnauthorizedError{}); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+	if code := HTTPStatusCode(&ForbiddenError{}); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+	if code := HTTPStatusCode(&RateLimitError{}); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", code)
+	}
 	if code := HTTPStatusCode(errors.New("other")); code != http.StatusInternalServerError {
 		t.Fatalf("expected 500, got %d", code)
 	}
 }
+
+func TestClass(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{&ValidationError{}, ClassValidation},
+		{&NotFoundError{}, ClassNotFound},
+		{&NotFoundError{Reason: ErrProtected}, ClassProtected},
+		{&NotFoundError{Reason: ErrSuspended}, ClassSuspended},
+		{&UpstreamError{Err: context.DeadlineExceeded}, ClassTimeout},
+		{&UpstreamError{StatusCode: 500}, ClassUpstreamStatus},
+		{&UpstreamError{StatusCode: 429, Err: ErrSessionsExhausted}, ClassExhausted},
+		{&UpstreamError{Err: &json.SyntaxError{}}, ClassParse},
+		{&UpstreamError{Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}, ClassNetwork},
+		{&RateLimitError{}, ClassRateLimited},
+		{&UpstreamError{Err: ErrCircuitOpen}, ClassCircuitOpen},
+		{&UpstreamError{Err: fmt.Errorf("%w: more than 10 bytes", ErrResponseTooLarge)}, ClassTooLarge},
+		{errors.New("other"), ClassInternal},
+	}
+	for _, tc := range cases {
+		if got := Class(tc.err); got != tc.want {
+			t.Errorf("Class(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestWriteHTTPErrorRetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteHTTPError(rec, &RateLimitError{Message: "slow down", RetryAfter: 1500 * time.Millisecond})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After 2, got %q", got)
+	}
+}
@@ -0,0 +1,403 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/ratelimit"
+)
+
+// Scope is a permission granted to an API key
+type Scope string
+
+// Known scopes
+const (
+	ScopeRead   Scope = "read"
+	ScopeStream Scope = "stream"
+	ScopeAdmin  Scope = "admin"
+)
+
+// AnonymousName is the principal name of requests without an API key
+const AnonymousName = "anonymous"
+
+// Key describes an API key as loaded from configuration
+type Key struct {
+	Name   string  `json:"name"`
+	Key    string  `json:"key"`
+	Scopes []Scope `json:"scopes"`
+	// RateLimit is the number of requests allowed per minute (0 = unlimited)
+	RateLimit int `json:"rate_limit"`
+	// DailyQuota is the number of requests allowed per UTC day (0 = unlimited)
+	DailyQuota int `json:"daily_quota"`
+}
+
+// AnonymousTier configures access for requests without an API key.
+// Limits and quotas apply per client IP.
+type AnonymousTier struct {
+	Enabled    bool
+	Scopes     []Scope
+	RateLimit  int
+	DailyQuota int
+	// TrustProxy takes the client IP from X-Forwarded-For, see ClientIP
+	TrustProxy bool
+}
+
+// ParseKeys decodes a JSON array of keys
+func ParseKeys(data []byte) ([]Key, error) {
+	var keys []Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// LoadKeysFile reads a JSON array of keys from path
+func LoadKeysFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	return ParseKeys(data)
+}
+
+// Principal is the identity a request was authenticated as
+type Principal struct {
+	Name      string
+	Scopes    []Scope
+	Anonymous bool
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// FromContext returns the principal of the request, or nil when
+// authentication is disabled
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Usage holds the request counters of a key
+type Usage struct {
+	Name          string     `json:"name"`
+	Scopes        []Scope    `json:"scopes"`
+	Anonymous     bool       `json:"anonymous,omitempty"`
+	RateLimit     int        `json:"rate_limit"`
+	DailyQuota    int        `json:"daily_quota"`
+	RequestsTotal int64      `json:"requests_total"`
+	RequestsToday int64      `json:"requests_today"`
+	RateLimited   int64      `json:"rate_limited"`
+	QuotaExceeded int64      `json:"quota_exceeded"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+}
+
+// maxSubjects bounds the subjects whose daily quota is tracked per client.
+// Past it, new subjects of the day share the quota of overflowSubject.
+const maxSubjects = 10000
+
+const overflowSubject = "*"
+
+// client is the runtime state of a key or of the anonymous tier
+type client struct {
+	principal  Principal
+	rateLimit  int
+	dailyQuota int
+	limiter    *ratelimit.Keyed
+
+	mu            sync.Mutex
+	day           string
+	usedToday     map[string]int // by subject: the key itself or a client IP
+	requestsToday int64
+	requestsTotal int64
+	rateLimited   int64
+	quotaExceeded int64
+	lastUsedAt    time.Time
+}
+
+func newClient(principal Principal, rateLimit, dailyQuota int) *client {
+	c := &client{
+		principal:  principal,
+		rateLimit:  rateLimit,
+		dailyQuota: dailyQuota,
+		usedToday:  make(map[string]int),
+	}
+	if rateLimit > 0 {
+		c.limiter = ratelimit.NewKeyed(func() *ratelimit.Bucket { return ratelimit.PerMinute(rateLimit) }, 10*time.Minute)
+	}
+	return c
+}
+
+// admit applies the rate limit and daily quota of subject and counts the request
+func (c *client) admit(subject string, now time.Time) error {
+	if c.limiter != nil {
+		if ok, wait := c.limiter.Allow(subject, now); !ok {
+			c.mu.Lock()
+			c.rateLimited++
+			c.mu.Unlock()
+			return &apperror.RateLimitError{Message: "rate limit exceeded", RetryAfter: wait}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	day := now.UTC().Format(time.DateOnly)
+	if day != c.day {
+		c.day = day
+		clear(c.usedToday)
+		c.requestsToday = 0
+	}
+	if c.dailyQuota > 0 {
+		if _, ok := c.usedToday[subject]; !ok && len(c.usedToday) >= maxSubjects {
+			subject = overflowSubject
+		}
+		if c.usedToday[subject] >= c.dailyQuota {
+			c.quotaExceeded++
+			return &apperror.RateLimitError{Message: "daily quota exceeded", RetryAfter: untilNextDay(now)}
+		}
+		c.usedToday[subject]++
+	}
+
+	c.requestsToday++
+	c.requestsTotal++
+	c.lastUsedAt = now
+	return nil
+}
+
+func (c *client) usage(now time.Time) Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	u := Usage{
+		Name:          c.principal.Name,
+		Scopes:        c.principal.Scopes,
+		Anonymous:     c.principal.Anonymous,
+		RateLimit:     c.rateLimit,
+		DailyQuota:    c.dailyQuota,
+		RequestsTotal: c.requestsTotal,
+		RateLimited:   c.rateLimited,
+		QuotaExceeded: c.quotaExceeded,
+	}
+	if c.day == now.UTC().Format(time.DateOnly) {
+		u.RequestsToday = c.requestsToday
+	}
+	if !c.lastUsedAt.IsZero() {
+		last := c.lastUsedAt
+		u.LastUsedAt = &last
+	}
+	return u
+}
+
+func untilNextDay(now time.Time) time.Duration {
+	utc := now.UTC()
+	next := time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(utc)
+}
+
+// Authenticator validates API keys and enforces per-key limits
+type Authenticator struct {
+	keys      map[[sha256.Size]byte]*client
+	ordered   []*client
+	anonymous *client
+	// trustProxy resolves client IPs through X-Forwarded-For
+	trustProxy bool
+	now        func() time.Time
+}
+
+// New creates an authenticator for keys. Key names and secrets must be unique.
+func New(keys []Key, anonymous AnonymousTier) (*Authenticator, error) {
+	a := &Authenticator{
+		keys:       make(map[[sha256.Size]byte]*client),
+		trustProxy: anonymous.TrustProxy,
+		now:        time.Now,
+	}
+
+	names := make(map[string]bool)
+	for _, k := range keys {
+		if k.Name == "" || k.Key == "" {
+			return nil, fmt.Errorf("API key entries need a name and a key")
+		}
+		if names[k.Name] || k.Name == AnonymousName {
+			return nil, fmt.Errorf("duplicate API key name %q", k.Name)
+		}
+		if err := validateScopes(k.Scopes); err != nil {
+			return nil, fmt.Errorf("API key %q: %w", k.Name, err)
+		}
+		hash := sha256.Sum256([]byte(k.Key))
+		if _, exists := a.keys[hash]; exists {
+			return nil, fmt.Errorf("API key %q reuses the secret of another key", k.Name)
+		}
+		names[k.Name] = true
+
+		c := newClient(Principal{Name: k.Name, Scopes: k.Scopes}, k.RateLimit, k.DailyQuota)
+		a.keys[hash] = c
+		a.ordered = append(a.ordered, c)
+	}
+
+	if anonymous.Enabled {
+		if err := validateScopes(anonymous.Scopes); err != nil {
+			return nil, fmt.Errorf("anonymous tier: %w", err)
+		}
+		a.anonymous = newClient(Principal{Name: AnonymousName, Scopes: anonymous.Scopes, Anonymous: true},
+			anonymous.RateLimit, anonymous.DailyQuota)
+	}
+	return a, nil
+}
+
+func validateScopes(scopes []Scope) error {
+	for _, s := range scopes {
+		switch s {
+		case ScopeRead, ScopeStream, ScopeAdmin:
+		default:
+			return fmt.Errorf("unknown scope %q", s)
+		}
+	}
+	return nil
+}
+
+// Middleware authenticates every request and applies the limits of its key
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := a.Authenticate(r.Context(), KeyFromRequest(r), ClientIP(r, a.trustProxy))
+		if err != nil {
+			logger.WarnContext(r.Context(), "Auth: rejected request: %v", err)
+			apperror.WriteHTTPError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Authenticate identifies the caller by its API key secret, or as anonymous
+// from ip when secret is empty, applies the limits of the key and returns
+// ctx carrying the principal
+func (a *Authenticator) Authenticate(ctx context.Context, secret, ip string) (context.Context, error) {
+	c, subject, err := a.identify(secret, ip)
+	if err == nil {
+		err = c.admit(subject, a.now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, principalKey{}, &c.principal)
+	return logger.WithFields(ctx, "api_key", c.principal.Name), nil
+}
+
+func (a *Authenticator) identify(secret, ip string) (*client, string, error) {
+	if secret == "" {
+		if a.anonymous == nil {
+			return nil, "", &apperror.UnauthorizedError{Message: "API key required"}
+		}
+		return a.anonymous, ip, nil
+	}
+
+	c, ok := a.keys[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return nil, "", &apperror.UnauthorizedError{Message: "invalid API key"}
+	}
+	return c, c.principal.Name, nil
+}
+
+// Usage returns the counters of every key, followed by the anonymous tier
+func (a *Authenticator) Usage() []Usage {
+	now := a.now()
+	usage := make([]Usage, 0, len(a.ordered)+1)
+	for _, c := range a.ordered {
+		usage = append(usage, c.usage(now))
+	}
+	if a.anonymous != nil {
+		usage = append(usage, a.anonymous.usage(now))
+	}
+	return usage
+}
+
+// Require rejects requests whose principal lacks scope. Requests pass
+// through when authentication is disabled, except for the admin scope.
+func Require(scope Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := CheckScope(r.Context(), scope); err != nil {
+				apperror.WriteHTTPError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CheckScope returns an error when the principal of ctx lacks scope. When
+// authentication is disabled it returns nil for every scope but the admin
+// one, which needs an API key.
+func CheckScope(ctx context.Context, scope Scope) error {
+	p := FromContext(ctx)
+	if p == nil && scope == ScopeAdmin {
+		return &apperror.ForbiddenError{Message: "admin endpoints need API keys"}
+	}
+	if p == nil || p.HasScope(scope) {
+		return nil
+	}
+	if p.Anonymous {
+		return &apperror.UnauthorizedError{Message: fmt.Sprintf("API key with scope %q required", scope)}
+	}
+	return &apperror.ForbiddenError{Message: fmt.Sprintf("scope %q required", scope)}
+}
+
+// KeyFromRequest returns the API key from the X-API-Key header, a bearer
+// token, or the api_key query parameter
+func KeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if authz := r.Header.Get("Authorization"); len(authz) > 7 && strings.EqualFold(authz[:7], "bearer ") {
+		return strings.TrimSpace(authz[7:])
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// ClientKey identifies a client for rate limiting: by the name of the API
+// key of the principal of ctx once the authenticator validated it, otherwise
+// by ip. Keys are never trusted before validation, so made-up ones neither
+// dodge the limit nor grow the limiter.
+func ClientKey(ctx context.Context, ip string) string {
+	if p := FromContext(ctx); p != nil && !p.Anonymous {
+		return "key:" + p.Name
+	}
+	return "ip:" + ip
+}
+
+// ClientIP returns the IP address of the client of r. With trustProxy it is
+// the last X-Forwarded-For address, the one the reverse proxy appended, as
+// the earlier ones are whatever the client sent; otherwise it is the
+// address of the direct peer.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+		if i := strings.LastIndexByte(forwarded, ','); i >= 0 {
+			forwarded = forwarded[i+1:]
+		}
+		if ip := strings.TrimSpace(forwarded); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+func newTestAuthenticator(t *testing.T, anonymous AnonymousTier) *Authenticator {
+	t.Helper()
+	keys, err := ParseKeys([]byte(`[
+		{"name": "bot", "key": "secret-bot", "scopes": ["read"], "rate_limit": 2},
+		{"name": "ops", "key": "secret-ops", "scopes": ["read", "admin"], "daily_quota": 1}
+	]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a, err := New(keys, anonymous)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return a
+}
+
+func serve(a *Authenticator, scope Scope, r *http.Request) *httptest.ResponseRecorder {
+	handler := a.Middleware(Require(scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(FromContext(r.Context()).Name))
+	})))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestMiddlewareKeySources(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{})
+
+	header := httptest.NewRequest(http.MethodGet, "/api/users/x", nil)
+	header.Header.Set("X-API-Key", "secret-bot")
+	bearer := httptest.NewRequest(http.MethodGet, "/api/users/x", nil)
+	bearer.Header.Set("Authorization", "Bearer secret-ops")
+	query := httptest.NewRequest(http.MethodGet, "/api/users/x?api_key=secret-bot", nil)
+
+	for _, r := range []*http.Request{header, bearer, query} {
+		if rec := serve(a, ScopeRead, r); rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMiddlewareRejectsMissingAndInvalidKeys(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{})
+
+	if rec := serve(a, ScopeRead, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without key, got %d", rec.Code)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Key", "wrong")
+	if rec := serve(a, ScopeRead, r); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with invalid key, got %d", rec.Code)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{Enabled: true, Scopes: []Scope{ScopeRead}})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Key", "secret-bot")
+	if rec := serve(a, ScopeAdmin, r); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for missing scope, got %d", rec.Code)
+	}
+
+	anon := httptest.NewRequest(http.MethodGet, "/", nil)
+	if rec := serve(a, ScopeRead, anon); rec.Code != http.StatusOK || rec.Body.String() != AnonymousName {
+		t.Fatalf("expected anonymous read access, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(a, ScopeAdmin, anon); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for anonymous admin access, got %d", rec.Code)
+	}
+}
+
+func TestAdminScopeFailsClosedWithoutAuthentication(t *testing.T) {
+	if err := CheckScope(context.Background(), ScopeRead); err != nil {
+		t.Fatalf("expected read access without authentication, got %v", err)
+	}
+	if err := CheckScope(context.Background(), ScopeAdmin); apperror.HTTPStatusCode(err) != http.StatusForbidden {
+		t.Fatalf("expected 403 for admin access without authentication, got %v", err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Add("X-Forwarded-For", "6.6.6.6, 1.2.3.4")
+	if ip := ClientIP(r, false); ip != "10.0.0.1" {
+		t.Fatalf("expected the peer without a trusted proxy, got %q", ip)
+	}
+	if ip := ClientIP(r, true); ip != "1.2.3.4" {
+		t.Fatalf("expected the address appended by the proxy, got %q", ip)
+	}
+	r.Header.Add("X-Forwarded-For", "5.6.7.8")
+	if ip := ClientIP(r, true); ip != "5.6.7.8" {
+		t.Fatalf("expected the last address over repeated headers, got %q", ip)
+	}
+}
+
+func TestAnonymousQuotaBehindProxy(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{Enabled: true, Scopes: []Scope{ScopeRead}, DailyQuota: 1, TrustProxy: true})
+	anon := func(ip string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", ip)
+		return r
+	}
+	serve(a, ScopeRead, anon("1.1.1.1"))
+	if rec := serve(a, ScopeRead, anon("2.2.2.2")); rec.Code != http.StatusOK {
+		t.Fatalf("expected a quota per client behind the proxy, got %d", rec.Code)
+	}
+	if rec := serve(a, ScopeRead, anon("1.1.1.1")); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the quota of 1.1.1.1 to be used up, got %d", rec.Code)
+	}
+}
+
+func TestAnonymousQuotaSubjectsAreBounded(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{Enabled: true, Scopes: []Scope{ScopeRead}, DailyQuota: 1})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range maxSubjects {
+		if err := a.anonymous.admit(fmt.Sprintf("ip:%d", i), now); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := a.anonymous.admit("ip:new", now); err != nil {
+		t.Fatalf("expected the first overflowing subject to be admitted, got %v", err)
+	}
+	if err := a.anonymous.admit("ip:newer", now); err == nil {
+		t.Fatal("expected overflowing subjects to share a quota")
+	}
+	if n := len(a.anonymous.usedToday); n != maxSubjects+1 {
+		t.Fatalf("expected %d tracked subjects, got %d", maxSubjects+1, n)
+	}
+	if usage := a.anonymous.usage(now); usage.RequestsToday != maxSubjects+1 {
+		t.Fatalf("unexpected usage: %#v", usage)
+	}
+}
+
+func TestRateLimitAndQuota(t *testing.T) {
+	a := newTestAuthenticator(t, AnonymousTier{})
+	now := time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	bot := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", "secret-bot")
+		return r
+	}
+	serve(a, ScopeRead, bot())
+	serve(a, ScopeRead, bot())
+	rec := serve(a, ScopeRead, bot())
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected 429 with Retry-After 30, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	ops := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", "secret-ops")
+		return r
+	}
+	serve(a, ScopeRead, ops())
+	rec = serve(a, ScopeRead, ops())
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("expected quota rejection until midnight, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	now = now.Add(time.Hour)
+	if rec := serve(a, ScopeRead, ops()); rec.Code != http.StatusOK {
+		t.Fatalf("expected quota reset on the next day, got %d", rec.Code)
+	}
+
+	usage := a.Usage()
+	if len(usage) != 2 {
+		t.Fatalf("expected 2 usage entries, got %d", len(usage))
+	}
+	if usage[0].Name != "bot" || usage[0].RequestsTotal != 2 || usage[0].RateLimited != 1 {
+		t.Fatalf("unexpected bot usage: %#v", usage[0])
+	}
+	if usage[1].RequestsTotal != 2 || usage[1].RequestsToday != 1 || usage[1].QuotaExceeded != 1 {
+		t.Fatalf("unexpected ops usage: %#v", usage[1])
+	}
+}
+
+func TestNewValidatesKeys(t *testing.T) {
+	if _, err := New([]Key{{Name: "a", Key: "x", Scopes: []Scope{"write"}}}, AnonymousTier{}); err == nil {
+		t.Fatal("expected error for unknown scope")
+	}
+	if _, err := New([]Key{{Name: "a", Key: "x"}, {Name: "b", Key: "x"}}, AnonymousTier{}); err == nil {
+		t.Fatal("expected error for reused secret")
+	}
+	if _, err := New([]Key{{Name: "a", Key: "x"}, {Name: "a", Key: "y"}}, AnonymousTier{}); err == nil {
+		t.Fatal("expected error for duplicate name")
+	}
+}
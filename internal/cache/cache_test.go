@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[string](time.Minute, 0)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", "1")
+	if v, ok := c.Get("a"); !ok || v != "1" {
+		t.Fatalf("expected hit, got %q %v", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected entry to expire")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected expired entry to be removed, got %d", c.Len())
+	}
+}
+
+func TestCacheMaxEntries(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New[int](time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(time.Second)
+	c.Set("b", 2)
+	now = now.Add(time.Second)
+	c.Set("c", 3)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected oldest entry to be evicted")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected newest entry to be present")
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	c := New[int](0, 0)
+	if c != nil {
+		t.Fatal("expected nil cache for zero TTL")
+	}
+	c.Set("a", 1)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected nil cache to miss")
+	}
+}
@@ -0,0 +1,344 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the application settings loaded from the environment
+type Config struct {
+	// NitterURLs are the base URLs of the Nitter instances, in failover order
+	NitterURLs []string
+	// Port is the listen address of the HTTP server
+	Port string
+	// PublicURL is the external base URL of the server, used in links to it;
+	// empty derives it from each request whose host is in AllowedHosts
+	PublicURL string
+	// AllowedHosts are the hosts links may point to when PublicURL is empty,
+	// besides loopback ones
+	AllowedHosts []string
+	// GRPCPort is the listen address of the gRPC server; empty, the default,
+	// disables it
+	GRPCPort string
+	// LogLevel is the minimum log level (debug, info, warn, error)
+	LogLevel string
+	// LogFormat is the log output format (text, json)
+	LogFormat string
+	// RequestTimeout is the total time budget of a single API request,
+	// shared by all upstream calls made while serving it
+	RequestTimeout time.Duration
+	// ExportTimeout replaces RequestTimeout for bulk exports, which stream
+	// many pages
+	ExportTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests may run after a shutdown signal
+	ShutdownTimeout time.Duration
+	// TweetCacheTTL, UserCacheTTL and TimelineCacheTTL control how long
+	// upstream responses are cached; zero disables the cache
+	TweetCacheTTL    time.Duration
+	UserCacheTTL     time.Duration
+	TimelineCacheTTL time.Duration
+	// HealthProbeUser is the account fetched to probe upstream health
+	HealthProbeUser string
+	// HealthCacheTTL is how long dependency probe results are reused
+	HealthCacheTTL time.Duration
+	// HealthProbeTimeout bounds a single dependency probe
+	HealthProbeTimeout time.Duration
+	// APIKeys is a JSON array of API keys
+	APIKeys string
+	// APIKeysFile is the path of a JSON file with API keys
+	APIKeysFile string
+	// AnonymousAccess allows read-only requests without an API key
+	AnonymousAccess bool
+	// AnonymousRateLimit is the per-IP request limit per minute of anonymous clients
+	AnonymousRateLimit int
+	// AnonymousDailyQuota is the per-IP daily request quota of anonymous clients
+	AnonymousDailyQuota int
+	// RateLimitRPS is the sustained request rate allowed per client (0 disables)
+	RateLimitRPS float64
+	// RateLimitBurst is the number of requests a client may send at once
+	RateLimitBurst int
+	// TrustProxy takes the client IP from the last X-Forwarded-For address
+	TrustProxy bool
+	// NitterMaxConcurrency and FxTwitterMaxConcurrency bound simultaneous
+	// upstream calls (0 = unlimited)
+	NitterMaxConcurrency    int
+	FxTwitterMaxConcurrency int
+	// UpstreamMaxQueue is how many upstream calls may wait for a free slot
+	UpstreamMaxQueue int
+	// UpstreamQueueTimeout is how long an upstream call waits for a free slot
+	UpstreamQueueTimeout time.Duration
+	// RetryAttempts is the total number of attempts of an upstream call
+	// (1 disables retries)
+	RetryAttempts int
+	// RetryBaseDelay and RetryMaxDelay bound the jittered exponential
+	// backoff between attempts
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerThreshold is the number of consecutive failures that opens the
+	// circuit breaker of an upstream instance (0 disables the breaker)
+	BreakerThreshold int
+	// BreakerOpenTimeout is how long an open breaker rejects calls before a
+	// trial call is let through
+	BreakerOpenTimeout time.Duration
+	// UpstreamProxy is the URL of an egress proxy for upstream calls
+	// (http, https, socks5); empty honors HTTP_PROXY and HTTPS_PROXY
+	UpstreamProxy string
+	// UpstreamUserAgent is the User-Agent sent to the upstreams
+	UpstreamUserAgent string
+	// UpstreamMaxIdleConns and UpstreamMaxIdleConnsPerHost size the
+	// keep-alive connection pool
+	UpstreamMaxIdleConns        int
+	UpstreamMaxIdleConnsPerHost int
+	// UpstreamIdleConnTimeout is how long an unused connection is kept open
+	UpstreamIdleConnTimeout time.Duration
+	// UpstreamCompression requests gzip or brotli encoded responses
+	UpstreamCompression bool
+	// UpstreamMaxResponseSize caps the size of an upstream response in bytes
+	// (0 = unlimited)
+	UpstreamMaxResponseSize int
+	// TrackUsers are the accounts whose profiles are snapshotted in the
+	// background; empty disables tracking
+	TrackUsers []string
+	// TrackInterval is how often tracked profiles are snapshotted
+	TrackInterval time.Duration
+	// TrackRetention is how long profile snapshots are kept
+	TrackRetention time.Duration
+	// TrackEngagement also follows the engagement counts of the recent
+	// tweets of the tracked accounts
+	TrackEngagement bool
+	// TrackDeletions also detects the deletions of the recent tweets of the
+	// tracked accounts
+	TrackDeletions bool
+	// TrackDataDir keeps the tracked data across restarts; empty keeps it
+	// in memory only
+	TrackDataDir string
+	// TracesExporter selects where OpenTelemetry spans are sent (none, otlp)
+	TracesExporter string
+	// ServiceName is the service.name reported in traces
+	ServiceName string
+}
+
+// Load reads the configuration from environment variables
+func Load() (*Config, error) {
+	cfg, err := LoadUpstream()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.NitterURLs) == 0 {
+		return nil, fmt.Errorf("NITTER_URL environment variable is required")
+	}
+	return cfg, nil
+}
+
+// LoadUpstream reads the configuration like Load, but doesn't require
+// NITTER_URL, for tools that only need the upstream settings
+func LoadUpstream() (*Config, error) {
+	cfg := &Config{
+		NitterURLs:   splitList(os.Getenv("NITTER_URL")),
+		Port:         normalizePort(getEnv("PORT", ":8080")),
+		GRPCPort:     normalizePort(getEnv("GRPC_PORT", "")),
+		PublicURL:    strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"),
+		AllowedHosts: splitList(os.Getenv("ALLOWED_HOSTS")),
+		LogLevel:     os.Getenv("LOG_LEVEL"),
+		LogFormat:    os.Getenv("LOG_FORMAT"),
+
+		HealthProbeUser: getEnv("HEALTH_PROBE_USER", "x"),
+
+		APIKeys:     os.Getenv("API_KEYS"),
+		APIKeysFile: os.Getenv("API_KEYS_FILE"),
+
+		TrackUsers:   splitList(os.Getenv("TRACK_USERS")),
+		TrackDataDir: os.Getenv("TRACK_DATA_DIR"),
+
+		UpstreamProxy:     os.Getenv("UPSTREAM_PROXY"),
+		UpstreamUserAgent: os.Getenv("UPSTREAM_USER_AGENT"),
+
+		TracesExporter: getEnv("OTEL_TRACES_EXPORTER", "none"),
+		ServiceName:    getEnv("OTEL_SERVICE_NAME", "twitterx-api"),
+	}
+
+	var err error
+	if cfg.RequestTimeout, err = getDuration("REQUEST_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ExportTimeout, err = getDuration("EXPORT_TIMEOUT", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownTimeout, err = getDuration("SHUTDOWN_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.HealthCacheTTL, err = getDuration("HEALTH_CACHE_TTL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.HealthProbeTimeout, err = getDuration("HEALTH_PROBE_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.AnonymousAccess, err = getBool("AUTH_ANONYMOUS", true); err != nil {
+		return nil, err
+	}
+	if cfg.AnonymousRateLimit, err = getInt("AUTH_ANONYMOUS_RATE_LIMIT", 120); err != nil {
+		return nil, err
+	}
+	if cfg.AnonymousDailyQuota, err = getInt("AUTH_ANONYMOUS_DAILY_QUOTA", 0); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitRPS, err = getFloat("RATE_LIMIT_RPS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitBurst, err = getInt("RATE_LIMIT_BURST", 30); err != nil {
+		return nil, err
+	}
+	if cfg.TrustProxy, err = getBool("TRUST_PROXY", false); err != nil {
+		return nil, err
+	}
+	if cfg.NitterMaxConcurrency, err = getInt("NITTER_MAX_CONCURRENCY", 4); err != nil {
+		return nil, err
+	}
+	if cfg.FxTwitterMaxConcurrency, err = getInt("FXTWITTER_MAX_CONCURRENCY", 8); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamMaxQueue, err = getInt("UPSTREAM_MAX_QUEUE", 100); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamQueueTimeout, err = getDuration("UPSTREAM_QUEUE_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.RetryAttempts, err = getInt("UPSTREAM_RETRY_ATTEMPTS", 3); err != nil {
+		return nil, err
+	}
+	if cfg.RetryBaseDelay, err = getDuration("UPSTREAM_RETRY_BASE_DELAY", 200*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if cfg.RetryMaxDelay, err = getDuration("UPSTREAM_RETRY_MAX_DELAY", 2*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.BreakerThreshold, err = getInt("BREAKER_FAILURE_THRESHOLD", 5); err != nil {
+		return nil, err
+	}
+	if cfg.BreakerOpenTimeout, err = getDuration("BREAKER_OPEN_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamMaxIdleConns, err = getInt("UPSTREAM_MAX_IDLE_CONNS", 100); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamMaxIdleConnsPerHost, err = getInt("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", 16); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamIdleConnTimeout, err = getDuration("UPSTREAM_IDLE_CONN_TIMEOUT", 90*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamCompression, err = getBool("UPSTREAM_COMPRESSION", true); err != nil {
+		return nil, err
+	}
+	if cfg.UpstreamMaxResponseSize, err = getInt("UPSTREAM_MAX_RESPONSE_SIZE", 10<<20); err != nil {
+		return nil, err
+	}
+	if cfg.TrackInterval, err = getDuration("TRACK_INTERVAL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.TrackRetention, err = getDuration("TRACK_RETENTION", 90*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrackEngagement, err = getBool("TRACK_ENGAGEMENT", false); err != nil {
+		return nil, err
+	}
+	if cfg.TrackDeletions, err = getBool("TRACK_DELETIONS", false); err != nil {
+		return nil, err
+	}
+	if cfg.TweetCacheTTL, err = getDuration("CACHE_TWEET_TTL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.UserCacheTTL, err = getDuration("CACHE_USER_TTL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.TimelineCacheTTL, err = getDuration("CACHE_TIMELINE_TTL", time.Minute); err != nil {
+		return nil, err
+	}
+
+	// DEBUG is kept for backwards compatibility with existing deployments
+	if cfg.LogLevel == "" && os.Getenv("DEBUG") != "" {
+		cfg.LogLevel = "debug"
+	}
+
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// splitList parses a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.TrimRight(item, "/"))
+		}
+	}
+	return items
+}
+
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func getBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// normalizePort accepts both "8080" and ":8080", and "off" for no listener
+func normalizePort(port string) string {
+	if port == "" || port == "off" {
+		return ""
+	}
+	if strings.Contains(port, ":") {
+		return port
+	}
+	return ":" + port
+}
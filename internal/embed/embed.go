@@ -0,0 +1,241 @@
+// Package embed renders tweets as self-contained HTML cards and describes
+// them for oEmbed consumers. Cards load no scripts: all text is escaped and
+// only http(s) links and images survive.
+package embed
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/models"
+	"twitterx-api/internal/render"
+)
+
+//go:embed card.html
+var cardTemplate string
+
+var card = template.Must(template.New("card").Parse(cardTemplate))
+
+// Theme is the color scheme of a card
+type Theme string
+
+// Supported themes
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+)
+
+// Card widths in pixels, the range Twitter allows for embedded tweets
+const (
+	MinWidth     = 220
+	MaxWidth     = 550
+	DefaultWidth = MaxWidth
+)
+
+// maxMedia is how many attachments the mosaic shows
+const maxMedia = 4
+
+// Options control how a card is rendered
+type Options struct {
+	Theme    Theme
+	MaxWidth int
+}
+
+// ParseOptions reads the theme and maxwidth query parameters. A maxwidth
+// above the supported range is clamped; one below it is kept, see Fits.
+func ParseOptions(query url.Values) (Options, error) {
+	opts := Options{Theme: ThemeLight, MaxWidth: DefaultWidth}
+
+	switch theme := Theme(query.Get("theme")); theme {
+	case "":
+	case ThemeLight, ThemeDark:
+		opts.Theme = theme
+	default:
+		return Options{}, &apperror.ValidationError{Field: "theme", Message: "must be light or dark"}
+	}
+
+	if value := query.Get("maxwidth"); value != "" {
+		width, err := strconv.Atoi(value)
+		if err != nil || width <= 0 {
+			return Options{}, &apperror.ValidationError{Field: "maxwidth", Message: "must be a positive integer"}
+		}
+		opts.MaxWidth = min(width, MaxWidth)
+	}
+	return opts, nil
+}
+
+// Fits reports whether a card fits in MaxWidth
+func (o Options) Fits() bool {
+	return o.MaxWidth >= MinWidth
+}
+
+// Query encodes the options as query parameters for an embed URL
+func (o Options) Query() url.Values {
+	return url.Values{"theme": {string(o.Theme)}, "maxwidth": {strconv.Itoa(o.MaxWidth)}}
+}
+
+// Render writes tweet as a standalone HTML page
+func Render(w io.Writer, tweet *models.Tweet, opts Options) error {
+	return card.Execute(w, cardData{
+		Title:    title(tweet),
+		Lang:     tweet.Lang,
+		Theme:    opts.Theme,
+		MaxWidth: opts.MaxWidth,
+		Tweet:    newTweetView(tweet, true),
+	})
+}
+
+type cardData struct {
+	Title    string
+	Lang     string
+	Theme    Theme
+	MaxWidth int
+	Tweet    *tweetView
+}
+
+// tweetView is a tweet prepared for the card template
+type tweetView struct {
+	URL         string
+	Name        string
+	ScreenName  string
+	ProfileURL  string
+	AvatarURL   string
+	Verified    bool
+	Text        template.HTML
+	Created     string
+	CreatedISO  string
+	Media       []mediaView
+	Poll        *pollView
+	Quote       *tweetView
+	Replies     string
+	Retweets    string
+	Likes       string
+	ShowMetrics bool
+}
+
+type mediaView struct {
+	URL      string
+	ImageURL string
+	Video    bool
+}
+
+type pollView struct {
+	Choices []pollChoiceView
+	Footer  string
+}
+
+type pollChoiceView struct {
+	Label      string
+	Percentage int
+	Leading    bool
+}
+
+// newTweetView prepares tweet for the template. Quotes are shown one
+// level deep and without metrics.
+func newTweetView(t *models.Tweet, top bool) *tweetView {
+	v := &tweetView{
+		URL:         render.SafeURL(t.URL),
+		Name:        t.Author.Name,
+		ScreenName:  t.Author.ScreenName,
+		AvatarURL:   render.SafeURL(t.Author.AvatarURL),
+		Verified:    t.Author.Verified || t.Author.BlueBadge,
+		Text:        render.TextHTML(t),
+		Replies:     FormatCount(t.Replies),
+		Retweets:    FormatCount(t.Retweets),
+		Likes:       FormatCount(t.Likes),
+		ShowMetrics: top,
+	}
+	if v.ScreenName != "" {
+		v.ProfileURL = "https://x.com/" + url.PathEscape(v.ScreenName)
+	}
+	if !t.CreatedAt.IsZero() {
+		created := t.CreatedAt.UTC()
+		v.Created = created.Format("3:04 PM · Jan 2, 2006")
+		v.CreatedISO = created.Format("2006-01-02T15:04:05Z")
+	}
+
+	if t.Media != nil {
+		for _, m := range t.Media.All {
+			if len(v.Media) == maxMedia {
+				break
+			}
+			item := mediaView{URL: render.SafeURL(t.URL), ImageURL: render.SafeURL(m.URL), Video: m.Type != "photo"}
+			if item.Video {
+				item.ImageURL = render.SafeURL(m.ThumbnailURL)
+			}
+			if item.ImageURL != "" {
+				v.Media = append(v.Media, item)
+			}
+		}
+	}
+
+	if p := t.Poll; p != nil && len(p.Choices) > 0 {
+		poll := &pollView{Footer: fmt.Sprintf("%s votes", FormatCount(p.TotalVotes))}
+		if p.TimeRemaining != "" {
+			poll.Footer += " · " + p.TimeRemaining
+		}
+		leading := 0
+		for i, c := range p.Choices {
+			if c.Count > p.Choices[leading].Count {
+				leading = i
+			}
+		}
+		for i, c := range p.Choices {
+			poll.Choices = append(poll.Choices, pollChoiceView{
+				Label:      c.Label,
+				Percentage: min(max(c.Percentage, 0), 100),
+				Leading:    i == leading && c.Count > 0,
+			})
+		}
+		v.Poll = poll
+	}
+
+	if top && t.Quote != nil {
+		v.Quote = newTweetView(t.Quote, false)
+	}
+	return v
+}
+
+// FormatCount formats a count as Twitter does: 950, 1.2K, 3.4M
+func FormatCount(n int64) string {
+	switch {
+	case n < 1_000:
+		return strconv.FormatInt(n, 10)
+	case n < 1_000_000:
+		return trimZero(float64(n)/1_000) + "K"
+	case n < 1_000_000_000:
+		return trimZero(float64(n)/1_000_000) + "M"
+	default:
+		return trimZero(float64(n)/1_000_000_000) + "B"
+	}
+}
+
+func trimZero(f float64) string {
+	if f >= 100 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(float64(int64(f*10))/10, 'f', 1, 64), ".0")
+}
+
+// maxTitle bounds the length of a card or oEmbed title in characters
+const maxTitle = 100
+
+// title is "Name on X: text", shortened
+func title(t *models.Tweet) string {
+	text := strings.Join(strings.Fields(t.Text), " ")
+	if utf8.RuneCountInString(text) > maxTitle {
+		text = string([]rune(text)[:maxTitle-1]) + "…"
+	}
+	name := t.Author.Name
+	if name == "" {
+		name = "@" + t.Author.ScreenName
+	}
+	return fmt.Sprintf("%s on X: \"%s\"", name, text)
+}
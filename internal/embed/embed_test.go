@@ -0,0 +1,109 @@
+package embed
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/models"
+)
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions(url.Values{"theme": {"dark"}, "maxwidth": {"9999"}})
+	if err != nil || opts.Theme != ThemeDark || opts.MaxWidth != MaxWidth {
+		t.Fatalf("unexpected options %+v, %v", opts, err)
+	}
+	opts, err = ParseOptions(url.Values{"maxwidth": {"300"}})
+	if err != nil || opts.Theme != ThemeLight || opts.MaxWidth != 300 || !opts.Fits() {
+		t.Fatalf("unexpected options %+v, %v", opts, err)
+	}
+	// No card fits, which oEmbed consumers must be told
+	opts, err = ParseOptions(url.Values{"maxwidth": {"100"}})
+	if err != nil || opts.MaxWidth != 100 || opts.Fits() {
+		t.Fatalf("unexpected options %+v, %v", opts, err)
+	}
+	for _, query := range []url.Values{{"theme": {"pink"}}, {"maxwidth": {"wide"}}} {
+		if _, err := ParseOptions(query); err == nil {
+			t.Errorf("expected an error for %v", query)
+		}
+	}
+}
+
+func testTweet() *models.Tweet {
+	views := int64(1500)
+	return &models.Tweet{
+		URL:  "https://x.com/jack/status/20",
+		ID:   "20",
+		Text: "just setting up my twttr <b>",
+		Author: models.Author{
+			Name:       "jack",
+			ScreenName: "jack",
+			AvatarURL:  "javascript:alert(1)",
+		},
+		Likes:     250_000,
+		Views:     &views,
+		CreatedAt: models.TwitterTime{Time: time.Date(2006, time.March, 21, 20, 50, 14, 0, time.UTC)},
+		Media: &models.Media{All: []models.MediaItem{
+			{Type: "photo", URL: "https://pbs.twimg.com/media/a.jpg"},
+			{Type: "video", URL: "https://video.twimg.com/v.mp4", ThumbnailURL: "https://pbs.twimg.com/thumb.jpg"},
+		}},
+		Poll: &models.Poll{TotalVotes: 10, Choices: []models.PollChoice{
+			{Label: "yes", Count: 7, Percentage: 70},
+			{Label: "no", Count: 3, Percentage: 30},
+		}},
+		Quote: &models.Tweet{ID: "1", Text: "quoted", Author: models.Author{Name: "biz", ScreenName: "biz"}},
+	}
+}
+
+func TestRender(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, testTweet(), Options{Theme: ThemeDark, MaxWidth: 400}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	page := b.String()
+
+	for _, want := range []string{
+		`<body class="dark">`,
+		`max-width: 400px`,
+		`just setting up my twttr &lt;b&gt;`,
+		`<div class="media m2">`,
+		`src="https://pbs.twimg.com/thumb.jpg"`,
+		`<div class="choice lead"><div class="bar" style="width: 70%">`,
+		`<div class="quote">`,
+		`@biz`,
+		`<b>250K</b> Likes`,
+		`datetime="2006-03-21T20:50:14Z"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("card lacks %q", want)
+		}
+	}
+	for _, unwanted := range []string{"javascript:", "<script", "<b>just"} {
+		if strings.Contains(page, unwanted) {
+			t.Errorf("card contains %q", unwanted)
+		}
+	}
+}
+
+func TestNewOEmbed(t *testing.T) {
+	o := NewOEmbed(testTweet(), "https://tx.example.com/", Options{Theme: ThemeLight, MaxWidth: 500}, 300, 5*time.Minute)
+
+	if o.Type != "rich" || o.Version != "1.0" || o.ProviderURL != "https://tx.example.com/" || o.AuthorURL != "https://x.com/jack" {
+		t.Fatalf("unexpected oEmbed %+v", o)
+	}
+	if o.Width != 500 || o.Height != 300 || o.CacheAge != 300 {
+		t.Fatalf("unexpected size %dx%d, cache age %d", o.Width, o.Height, o.CacheAge)
+	}
+	if !strings.Contains(o.HTML, `src="https://tx.example.com/embed/20?maxwidth=500&amp;theme=light"`) {
+		t.Fatalf("unexpected html %s", o.HTML)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int64]string{950: "950", 1_000: "1K", 1_250: "1.2K", 999_999: "999K", 3_400_000: "3.4M", 2_000_000_000: "2B"} {
+		if got := FormatCount(n); got != want {
+			t.Errorf("FormatCount(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
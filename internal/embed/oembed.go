@@ -0,0 +1,95 @@
+package embed
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"twitterx-api/internal/models"
+)
+
+// OEmbed is an oEmbed 1.0 response of the rich type
+type OEmbed struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	AuthorURL    string `json:"author_url"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age,omitempty"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// ProviderName is the provider_name of oEmbed responses
+const ProviderName = "TwitterX"
+
+// NewOEmbed describes tweet as an iframe of its card, served under
+// baseURL at /embed/{id}. maxHeight caps the height (0 = no cap); longer
+// cards scroll.
+func NewOEmbed(tweet *models.Tweet, baseURL string, opts Options, maxHeight int, cacheAge time.Duration) *OEmbed {
+	baseURL = strings.TrimRight(baseURL, "/")
+	src := fmt.Sprintf("%s/embed/%s?%s", baseURL, url.PathEscape(tweet.ID), opts.Query().Encode())
+	height := estimateHeight(tweet, opts.MaxWidth)
+	if maxHeight > 0 {
+		height = min(height, maxHeight)
+	}
+
+	html := fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" style="border: 0; max-width: 100%%" loading="lazy" sandbox="allow-popups allow-popups-to-escape-sandbox"></iframe>`,
+		template.HTMLEscapeString(src), opts.MaxWidth, height, template.HTMLEscapeString(title(tweet)))
+
+	oembed := &OEmbed{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        title(tweet),
+		AuthorName:   tweet.Author.Name,
+		ProviderName: ProviderName,
+		ProviderURL:  baseURL + "/",
+		CacheAge:     int(cacheAge.Seconds()),
+		HTML:         html,
+		Width:        opts.MaxWidth,
+		Height:       height,
+	}
+	if tweet.Author.ScreenName != "" {
+		oembed.AuthorURL = "https://x.com/" + url.PathEscape(tweet.Author.ScreenName)
+	}
+	return oembed
+}
+
+// Approximate heights of card parts in pixels, for sizing the iframe
+const (
+	chromeHeight  = 150
+	lineHeight    = 21
+	charWidth     = 8
+	mediaHeight   = 282
+	choiceHeight  = 32
+	quoteHeight   = 100
+	minCardHeight = 200
+)
+
+// estimateHeight guesses the rendered height of a card maxWidth pixels wide.
+// The iframe has no script to report its real height.
+func estimateHeight(t *models.Tweet, maxWidth int) int {
+	perLine := max((maxWidth-32)/charWidth, 1)
+	lines := 0
+	for _, line := range strings.Split(t.Text, "\n") {
+		lines += max((utf8.RuneCountInString(line)+perLine-1)/perLine, 1)
+	}
+
+	height := chromeHeight + lines*lineHeight
+	if t.Media != nil && len(t.Media.All) > 0 {
+		height += mediaHeight
+	}
+	if t.Poll != nil {
+		height += (len(t.Poll.Choices) + 1) * choiceHeight
+	}
+	if t.Quote != nil {
+		height += quoteHeight + estimateHeight(t.Quote, maxWidth-26) - chromeHeight
+	}
+	return max(height, minCardHeight)
+}
@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"twitterx-api/internal/models"
+)
+
+func sampleTweet() *models.Tweet {
+	views := int64(1000)
+	replyTo, replyToStatus := "biz", "19"
+	return &models.Tweet{
+		URL:              "https://x.com/jack/status/20",
+		ID:               "20",
+		Text:             "just setting up, my \"twttr\"\nsecond line",
+		Author:           models.Author{ScreenName: "jack", Name: "Jack"},
+		Replies:          1,
+		Retweets:         2,
+		Likes:            3,
+		Views:            &views,
+		CreatedAt:        models.TwitterTime{Time: time.Date(2006, time.March, 21, 20, 50, 14, 0, time.UTC)},
+		Lang:             "en",
+		ReplyingTo:       &replyTo,
+		ReplyingToStatus: &replyToStatus,
+		Quote:            &models.Tweet{ID: "7"},
+		Media: &models.Media{All: []models.MediaItem{
+			{Type: "photo", URL: "https://pbs.twimg.com/a.jpg"},
+			{Type: "photo", URL: "https://pbs.twimg.com/b.jpg"},
+		}},
+	}
+}
+
+func TestFromTweet(t *testing.T) {
+	row := FromTweet(sampleTweet())
+	if row.QuoteID != "7" || row.ReplyTo != "biz" || row.ReplyToStatus != "19" || len(row.MediaURLs) != 2 {
+		t.Fatalf("unexpected row %+v", row)
+	}
+	if len(row.Record()) != len(Columns) {
+		t.Fatalf("record has %d fields, expected %d", len(row.Record()), len(Columns))
+	}
+
+	empty := FromTweet(&models.Tweet{ID: "1"})
+	if empty.MediaURLs == nil || empty.Record()[2] != "" {
+		t.Fatalf("unexpected empty row %+v", empty)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	row := FromTweet(sampleTweet())
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, FormatJSONL, true)
+	w.Write(row)
+	w.Write(row)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var decoded Row
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[0]), &decoded) != nil || decoded.ID != "20" {
+		t.Fatalf("unexpected JSONL %q", buf.String())
+	}
+
+	buf.Reset()
+	w, _ = NewWriter(&buf, FormatCSV, true)
+	w.Write(row)
+	w.Flush()
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(records) != 2 || records[0][0] != "id" || records[1][5] != row.Text || records[1][14] != "https://pbs.twimg.com/a.jpg https://pbs.twimg.com/b.jpg" {
+		t.Fatalf("unexpected CSV %v, %v", records, err)
+	}
+
+	buf.Reset()
+	w, _ = NewWriter(&buf, FormatCSV, false)
+	w.Write(row)
+	w.Flush()
+	if strings.HasPrefix(buf.String(), "id,") {
+		t.Fatal("expected no CSV header")
+	}
+
+	buf.Reset()
+	w, _ = NewWriter(&buf, FormatMarkdown, true)
+	w.Write(row)
+	w.Flush()
+	if !strings.Contains(buf.String(), "### [@jack](https://x.com/jack/status/20)") || !strings.Contains(buf.String(), "> second line") {
+		t.Fatalf("unexpected Markdown %q", buf.String())
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	row := FromTweet(sampleTweet())
+	noViews := *row
+	noViews.ID, noViews.Views = "21", nil
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatParquet, true)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	w.Write(row)
+	w.Flush()
+	w.Write(&noViews)
+	if err := w.Close("c2"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(buf.Bytes()), new(parquetRow), 1)
+	if err != nil {
+		t.Fatalf("invalid Parquet file: %v", err)
+	}
+	defer pr.ReadStop()
+	rows := make([]parquetRow, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ID != "20" || *rows[0].Views != 1000 || len(rows[0].MediaURLs) != 2 || rows[1].Views != nil {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if got := time.UnixMilli(rows[0].CreatedAt).UTC(); !got.Equal(row.CreatedAt) {
+		t.Fatalf("expected created_at %s, got %s", row.CreatedAt, got)
+	}
+	var cursor string
+	for _, kv := range pr.Footer.KeyValueMetadata {
+		if kv.Key == CursorField && kv.Value != nil {
+			cursor = *kv.Value
+		}
+	}
+	if cursor != "c2" {
+		t.Fatalf("expected the cursor in the metadata, got %q", cursor)
+	}
+}
+
+func TestWritersEndWithCursor(t *testing.T) {
+	for format, want := range map[Format]string{
+		FormatJSONL:    `{"next_cursor":"c2"}`,
+		FormatCSV:      "# next_cursor=c2",
+		FormatMarkdown: "<!-- next_cursor: c2 -->",
+	} {
+		var buf bytes.Buffer
+		w, _ := NewWriter(&buf, format, true)
+		w.Write(FromTweet(sampleTweet()))
+		if err := w.Close("c2"); err != nil {
+			t.Fatalf("%s: close: %v", format, err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if last := lines[len(lines)-1]; last != want {
+			t.Fatalf("%s: expected the cursor last, got %q", format, last)
+		}
+
+		buf.Reset()
+		w, _ = NewWriter(&buf, format, true)
+		w.Write(FromTweet(sampleTweet()))
+		w.Close("")
+		if strings.Contains(buf.String(), CursorField) {
+			t.Fatalf("%s: expected no cursor on the last page, got %q", format, buf.String())
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("CSV"); err != nil || f != FormatCSV {
+		t.Fatalf("expected csv, got %q, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"io"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetRow is the Parquet schema of Row
+type parquetRow struct {
+	ID                string   `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	URL               string   `parquet:"name=url, type=BYTE_ARRAY, convertedtype=UTF8"`
+	CreatedAt         int64    `parquet:"name=created_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Author            string   `parquet:"name=author, type=BYTE_ARRAY, convertedtype=UTF8"`
+	AuthorName        string   `parquet:"name=author_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Text              string   `parquet:"name=text, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Lang              string   `parquet:"name=lang, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Replies           int64    `parquet:"name=replies, type=INT64"`
+	Retweets          int64    `parquet:"name=retweets, type=INT64"`
+	Likes             int64    `parquet:"name=likes, type=INT64"`
+	Views             *int64   `parquet:"name=views, type=INT64, repetitiontype=OPTIONAL"`
+	ReplyTo           string   `parquet:"name=reply_to, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ReplyToStatus     string   `parquet:"name=reply_to_status, type=BYTE_ARRAY, convertedtype=UTF8"`
+	QuoteID           string   `parquet:"name=quote_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MediaURLs         []string `parquet:"name=media_urls, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	PossiblySensitive bool     `parquet:"name=possibly_sensitive, type=BOOLEAN"`
+}
+
+// parquetWriter buffers rows and writes them as a row group on every Flush,
+// so callers should flush in batches rather than after every row
+type parquetWriter struct {
+	w       *writer.ParquetWriter
+	pending int
+}
+
+func newParquetWriter(w io.Writer) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRow), 1)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{w: pw}, nil
+}
+
+func (w *parquetWriter) Write(row *Row) error {
+	w.pending++
+	return w.w.Write(parquetRow{
+		ID:                row.ID,
+		URL:               row.URL,
+		CreatedAt:         row.CreatedAt.UnixMilli(),
+		Author:            row.Author,
+		AuthorName:        row.AuthorName,
+		Text:              row.Text,
+		Lang:              row.Lang,
+		Replies:           row.Replies,
+		Retweets:          row.Retweets,
+		Likes:             row.Likes,
+		Views:             row.Views,
+		ReplyTo:           row.ReplyTo,
+		ReplyToStatus:     row.ReplyToStatus,
+		QuoteID:           row.QuoteID,
+		MediaURLs:         row.MediaURLs,
+		PossiblySensitive: row.PossiblySensitive,
+	})
+}
+
+func (w *parquetWriter) Flush() error {
+	if w.pending == 0 {
+		return nil
+	}
+	w.pending = 0
+	return w.w.Flush(true)
+}
+
+// Close writes the footer, without which the file can't be read, with the
+// cursor as key-value metadata
+func (w *parquetWriter) Close(cursor string) error {
+	if cursor != "" {
+		key := CursorField
+		w.w.Footer.KeyValueMetadata = append(w.w.Footer.KeyValueMetadata, &parquet.KeyValue{Key: key, Value: &cursor})
+	}
+	return w.w.WriteStop()
+}
@@ -0,0 +1,91 @@
+// Package export flattens tweets into rows and writes them as JSON Lines,
+// CSV or Markdown
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"twitterx-api/internal/models"
+)
+
+// Row is a tweet flattened into scalar columns
+type Row struct {
+	ID                string    `json:"id"`
+	URL               string    `json:"url"`
+	CreatedAt         time.Time `json:"created_at"`
+	Author            string    `json:"author"`
+	AuthorName        string    `json:"author_name"`
+	Text              string    `json:"text"`
+	Lang              string    `json:"lang"`
+	Replies           int64     `json:"replies"`
+	Retweets          int64     `json:"retweets"`
+	Likes             int64     `json:"likes"`
+	Views             *int64    `json:"views"`
+	ReplyTo           string    `json:"reply_to"`
+	ReplyToStatus     string    `json:"reply_to_status"`
+	QuoteID           string    `json:"quote_id"`
+	MediaURLs         []string  `json:"media_urls"`
+	PossiblySensitive bool      `json:"possibly_sensitive"`
+}
+
+// Columns are the CSV column names, in the order of Row.Record
+var Columns = []string{
+	"id", "url", "created_at", "author", "author_name", "text", "lang",
+	"replies", "retweets", "likes", "views", "reply_to", "reply_to_status",
+	"quote_id", "media_urls", "possibly_sensitive",
+}
+
+// FromTweet flattens tweet
+func FromTweet(tweet *models.Tweet) *Row {
+	row := &Row{
+		ID:                tweet.ID,
+		URL:               tweet.URL,
+		CreatedAt:         tweet.CreatedAt.Time.UTC(),
+		Author:            tweet.Author.ScreenName,
+		AuthorName:        tweet.Author.Name,
+		Text:              tweet.Text,
+		Lang:              tweet.Lang,
+		Replies:           tweet.Replies,
+		Retweets:          tweet.Retweets,
+		Likes:             tweet.Likes,
+		Views:             tweet.Views,
+		PossiblySensitive: tweet.PossiblySensitive,
+		MediaURLs:         []string{},
+	}
+	if tweet.ReplyingTo != nil {
+		row.ReplyTo = *tweet.ReplyingTo
+	}
+	if tweet.ReplyingToStatus != nil {
+		row.ReplyToStatus = *tweet.ReplyingToStatus
+	}
+	if tweet.Quote != nil {
+		row.QuoteID = tweet.Quote.ID
+	}
+	if tweet.Media != nil {
+		for _, item := range tweet.Media.All {
+			row.MediaURLs = append(row.MediaURLs, item.URL)
+		}
+	}
+	return row
+}
+
+// Record returns the row as CSV fields. Media URLs are separated by spaces
+// and a missing view count is empty.
+func (r *Row) Record() []string {
+	views := ""
+	if r.Views != nil {
+		views = strconv.FormatInt(*r.Views, 10)
+	}
+	createdAt := ""
+	if !r.CreatedAt.IsZero() {
+		createdAt = r.CreatedAt.Format(time.RFC3339)
+	}
+	return []string{
+		r.ID, r.URL, createdAt, r.Author, r.AuthorName, r.Text, r.Lang,
+		strconv.FormatInt(r.Replies, 10), strconv.FormatInt(r.Retweets, 10), strconv.FormatInt(r.Likes, 10),
+		views, r.ReplyTo, r.ReplyToStatus, r.QuoteID, strings.Join(r.MediaURLs, " "),
+		strconv.FormatBool(r.PossiblySensitive),
+	}
+}
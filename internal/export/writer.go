@@ -0,0 +1,176 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported formats
+const (
+	FormatJSONL    Format = "jsonl"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+	FormatParquet  Format = "parquet"
+)
+
+// ParseFormat validates an export format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSONL, FormatCSV, FormatMarkdown, FormatParquet:
+		return f, nil
+	case "markdown":
+		return FormatMarkdown, nil
+	default:
+		return "", &apperror.ValidationError{Field: "format", Message: fmt.Sprintf("unsupported format %q", s)}
+	}
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Writer writes rows in an export format
+type Writer interface {
+	Write(row *Row) error
+	// Flush writes buffered rows to the underlying writer
+	Flush() error
+	// Close flushes the remaining rows and ends the file. A non-empty cursor
+	// is written last, in the format's own way, to resume the export from.
+	Close(cursor string) error
+}
+
+// CursorField is the key of the record holding the cursor that resumes an
+// export: the last JSONL object, a trailing "# next_cursor=" CSV comment, a
+// trailing Markdown comment or a Parquet key-value metadata entry
+const CursorField = "next_cursor"
+
+// NewWriter creates a writer of format. header controls whether the CSV
+// header is written; it is left out when appending to an earlier export.
+// Parquet files can't be appended to.
+func NewWriter(w io.Writer, format Format, header bool) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		buf := bufio.NewWriter(w)
+		return &jsonlWriter{buf: buf, enc: json.NewEncoder(buf)}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), out: w, header: header}, nil
+	case FormatMarkdown:
+		return &markdownWriter{buf: bufio.NewWriter(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w)
+	default:
+		return nil, &apperror.ValidationError{Field: "format", Message: fmt.Sprintf("unsupported format %q", format)}
+	}
+}
+
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(row *Row) error {
+	return w.enc.Encode(row)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+func (w *jsonlWriter) Close(cursor string) error {
+	if cursor != "" {
+		if err := w.enc.Encode(map[string]string{CursorField: cursor}); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	out    io.Writer
+	header bool
+}
+
+func (w *csvWriter) Write(row *Row) error {
+	if w.header {
+		w.header = false
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+	}
+	return w.w.Write(row.Record())
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close(cursor string) error {
+	if err := w.Flush(); err != nil || cursor == "" {
+		return err
+	}
+	// Written past the CSV writer, which would quote it as a record
+	_, err := w.out.Write([]byte("# " + CursorField + "=" + cursor + "\n"))
+	return err
+}
+
+// markdownWriter writes every tweet as a section with a linked heading, the
+// text as a quote and the engagement counts
+type markdownWriter struct {
+	buf *bufio.Writer
+}
+
+func (w *markdownWriter) Write(row *Row) error {
+	fmt.Fprintf(w.buf, "### [@%s](%s)", row.Author, row.URL)
+	if !row.CreatedAt.IsZero() {
+		fmt.Fprintf(w.buf, " · %s", row.CreatedAt.Format(time.DateTime))
+	}
+	w.buf.WriteString("\n\n")
+	for _, line := range strings.Split(row.Text, "\n") {
+		fmt.Fprintf(w.buf, "> %s\n", line)
+	}
+	w.buf.WriteString("\n")
+	for _, mediaURL := range row.MediaURLs {
+		fmt.Fprintf(w.buf, "- <%s>\n", mediaURL)
+	}
+	fmt.Fprintf(w.buf, "%d replies · %d retweets · %d likes", row.Replies, row.Retweets, row.Likes)
+	if row.Views != nil {
+		fmt.Fprintf(w.buf, " · %d views", *row.Views)
+	}
+	_, err := w.buf.WriteString("\n\n")
+	return err
+}
+
+func (w *markdownWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+func (w *markdownWriter) Close(cursor string) error {
+	if cursor != "" {
+		fmt.Fprintf(w.buf, "<!-- %s: %s -->\n", CursorField, cursor)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,133 @@
+// Package graphql serves users, tweets and timelines over GraphQL. Nested
+// fields are resolved through per-request loaders, so each user, tweet and
+// timeline page is fetched at most once per query.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	// maxDepth bounds how deeply fields may be nested
+	maxDepth = 12
+	// fetchConcurrency is how many upstream calls a batch makes at once
+	fetchConcurrency = 8
+	// maxBodySize bounds the size of a request body
+	maxBodySize = 1 << 20
+)
+
+// Schema returns the GraphQL schema in SDL
+func Schema() string {
+	return schemaSDL
+}
+
+// Handler executes GraphQL queries sent as GET ?query= or as a JSON POST
+type Handler struct {
+	schema *graphqlgo.Schema
+	client *twitterx.Client
+}
+
+// NewHandler creates a handler resolving queries with client
+func NewHandler(client *twitterx.Client) (*Handler, error) {
+	schema, err := graphqlgo.ParseSchema(schemaSDL, &resolver{},
+		graphqlgo.MaxDepth(maxDepth),
+		graphqlgo.UseStringDescriptions(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: schema, client: client}, nil
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				http.Error(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		http.Error(w, "Missing query", http.StatusBadRequest)
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(r.Context(), h.client))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if len(resp.Errors) > 0 {
+		logger.DebugContext(ctx, "GraphQL query failed: %v", resp.Errors)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(ctx, "Error encoding response: %v", err)
+	}
+}
+
+// loaders batch and cache the upstream calls of one request
+type loaders struct {
+	users  *loader[*twitterx.User]
+	tweets *loader[*twitterx.Tweet]
+	pages  *loader[*twitterx.Timeline]
+}
+
+func newLoaders(ctx context.Context, client *twitterx.Client) *loaders {
+	return &loaders{
+		users: newLoader(ctx, fetchEach(fetchConcurrency, client.GetUser)),
+		tweets: newLoader(ctx, fetchEach(fetchConcurrency, func(ctx context.Context, id string) (*twitterx.Tweet, error) {
+			// FxTwitter finds a tweet by ID whatever the username
+			return client.GetTweet(ctx, "i", id)
+		})),
+		pages: newLoader(ctx, fetchEach(fetchConcurrency, func(ctx context.Context, key string) (*twitterx.Timeline, error) {
+			screenName, cursor, _ := strings.Cut(key, "\x00")
+			return client.GetTimelinePage(ctx, screenName, cursor)
+		})),
+	}
+}
+
+// pageKey identifies a timeline page in the pages loader
+func pageKey(screenName, cursor string) string {
+	return strings.ToLower(screenName) + "\x00" + cursor
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
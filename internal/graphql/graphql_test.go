@@ -0,0 +1,242 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"twitterx-api/pkg/twitterx"
+)
+
+const timelineRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>jack</title>
+    <item><title>Pinned: quote</title><guid>https://nitter.net/jack/status/22#m</guid></item>
+    <item><guid>https://nitter.net/jack/status/21#m</guid></item>
+    <item><guid>https://nitter.net/jack/status/20#m</guid></item>
+  </channel>
+</rss>`
+
+// upstreams fakes Nitter and FxTwitter and counts calls per path
+type upstreams struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (u *upstreams) count(path string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.calls[path]
+}
+
+func newTestHandler(t *testing.T) (*Handler, *upstreams) {
+	t.Helper()
+	u := &upstreams{calls: make(map[string]int)}
+	record := func(r *http.Request) {
+		u.mu.Lock()
+		u.calls[r.URL.Path]++
+		u.mu.Unlock()
+	}
+
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		switch r.URL.Path {
+		case "/jack":
+			w.Write([]byte(`{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12","followers":6000000000}}`))
+		case "/i/status/20":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"20","text":"just setting up my twttr","author":{"screen_name":"jack"}}}`))
+		case "/i/status/21":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"21","text":"reply","author":{"screen_name":"jack"},"replying_to":"jack","replying_to_status":"20"}}`))
+		case "/i/status/22":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"22","text":"quote","author":{"screen_name":"jack"},"quote":{"id":"20","text":"just setting up my twttr"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}
+	}))
+	t.Cleanup(fx.Close)
+
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Write([]byte(timelineRSS))
+	}))
+	t.Cleanup(nitter.Close)
+
+	client, err := twitterx.New(
+		twitterx.WithFxTwitterURL(fx.URL),
+		twitterx.WithNitterInstances(nitter.URL),
+		twitterx.WithTweetCacheTTL(0),
+		twitterx.WithUserCacheTTL(0),
+		twitterx.WithTimelineCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	h, err := NewHandler(client)
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+	return h, u
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func execute(t *testing.T, h *Handler, query string, variables map[string]any) response {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body, err)
+	}
+	return resp
+}
+
+func TestTimelineDedupesUpstreamCalls(t *testing.T) {
+	h, u := newTestHandler(t)
+
+	resp := execute(t, h, `query($name: String!) {
+		user(screenName: $name) {
+			followers
+			timeline(first: 3) {
+				nodes {
+					id
+					author { screenName followers }
+					quote { id }
+					replyingTo { id author { screenName } }
+				}
+			}
+		}
+	}`, map[string]any{"name": "jack"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+
+	var data struct {
+		User struct {
+			Followers int64
+			Timeline  struct {
+				Nodes []struct {
+					ID         string
+					Quote      *struct{ ID string }
+					ReplyingTo *struct{ ID string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("invalid data: %v", err)
+	}
+	if data.User.Followers != 6000000000 {
+		t.Fatalf("expected 6000000000 followers, got %d", data.User.Followers)
+	}
+	nodes := data.User.Timeline.Nodes
+	if len(nodes) != 3 || nodes[0].Quote == nil || nodes[0].Quote.ID != "20" || nodes[1].ReplyingTo == nil || nodes[1].ReplyingTo.ID != "20" {
+		t.Fatalf("unexpected nodes %+v", nodes)
+	}
+
+	// jack is referenced five times and tweet 20 twice, but each is fetched once
+	for _, path := range []string{"/jack", "/i/status/20", "/i/status/21", "/i/status/22"} {
+		if n := u.count(path); n != 1 {
+			t.Errorf("expected 1 call to %s, got %d", path, n)
+		}
+	}
+}
+
+func TestTimelinePagination(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	query := `query($after: String) {
+		timeline(screenName: "jack", first: 2, after: $after) {
+			tweetIds
+			pageInfo { endCursor hasNextPage }
+		}
+	}`
+	type page struct {
+		Timeline struct {
+			TweetIds []string
+			PageInfo struct {
+				EndCursor   string
+				HasNextPage bool
+			}
+		}
+	}
+
+	var first page
+	resp := execute(t, h, query, nil)
+	if err := json.Unmarshal(resp.Data, &first); err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("unexpected response: %v, %v", resp.Errors, err)
+	}
+	if got := strings.Join(first.Timeline.TweetIds, ","); got != "22,21" || !first.Timeline.PageInfo.HasNextPage {
+		t.Fatalf("unexpected first page %+v", first.Timeline)
+	}
+
+	var second page
+	resp = execute(t, h, query, map[string]any{"after": first.Timeline.PageInfo.EndCursor})
+	if err := json.Unmarshal(resp.Data, &second); err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("unexpected response: %v, %v", resp.Errors, err)
+	}
+	if got := strings.Join(second.Timeline.TweetIds, ","); got != "20" || second.Timeline.PageInfo.HasNextPage {
+		t.Fatalf("unexpected second page %+v", second.Timeline)
+	}
+}
+
+func TestPinnedTweet(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	resp := execute(t, h, `{ user(screenName: "jack") { pinnedTweet { id text } } }`, nil)
+	if len(resp.Errors) > 0 || string(resp.Data) != `{"user":{"pinnedTweet":{"id":"22","text":"quote"}}}` {
+		t.Fatalf("unexpected response %s, %v", resp.Data, resp.Errors)
+	}
+}
+
+func TestMissingTweetIsNull(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	resp := execute(t, h, `{ tweet(id: "404") { id } }`, nil)
+	if len(resp.Errors) > 0 || string(resp.Data) != `{"tweet":null}` {
+		t.Fatalf("unexpected response %s, %v", resp.Data, resp.Errors)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	for _, tt := range []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"missing query", httptest.NewRequest(http.MethodGet, "/graphql", nil), http.StatusBadRequest},
+		{"invalid body", httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")), http.StatusBadRequest},
+		{"method", httptest.NewRequest(http.MethodPut, "/graphql", nil), http.StatusMethodNotAllowed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+
+	resp := execute(t, h, `{ timeline(screenName: "jack", first: 1000) { tweetIds } }`, nil)
+	if len(resp.Errors) == 0 {
+		t.Fatal("expected an error for first above the limit")
+	}
+}
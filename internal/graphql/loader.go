@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// batchWait is how long a loader collects keys before fetching them. Sibling
+// fields resolve in parallel, so a short wait gathers a whole list.
+const batchWait = time.Millisecond
+
+// maxBatch dispatches a batch early once it holds this many keys
+const maxBatch = 100
+
+// loader collects the keys requested while a query executes, fetches them
+// in batches and caches the results for the rest of the request, so a user
+// or tweet referenced many times costs one upstream call
+type loader[V any] struct {
+	// ctx is the context of the request the loader belongs to
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []string) []result[V]
+
+	mu      sync.Mutex
+	cache   map[string]*entry[V]
+	pending []string
+}
+
+type result[V any] struct {
+	value V
+	err   error
+}
+
+type entry[V any] struct {
+	result[V]
+	done chan struct{}
+}
+
+func newLoader[V any](ctx context.Context, fetch func(ctx context.Context, keys []string) []result[V]) *loader[V] {
+	return &loader[V]{ctx: ctx, fetch: fetch, cache: make(map[string]*entry[V])}
+}
+
+// Load returns the value of key, waiting for the batch that fetches it
+func (l *loader[V]) Load(ctx context.Context, key string) (V, error) {
+	l.mu.Lock()
+	e, ok := l.cache[key]
+	if !ok {
+		e = &entry[V]{done: make(chan struct{})}
+		l.cache[key] = e
+		l.pending = append(l.pending, key)
+		switch len(l.pending) {
+		case 1:
+			time.AfterFunc(batchWait, l.dispatch)
+		case maxBatch:
+			go l.dispatch()
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.value, e.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// dispatch fetches the pending keys
+func (l *loader[V]) dispatch() {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	entries := make([]*entry[V], len(keys))
+	for i, key := range keys {
+		entries[i] = l.cache[key]
+	}
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	results := l.fetch(l.ctx, keys)
+	for i, e := range entries {
+		e.result = results[i]
+		close(e.done)
+	}
+}
+
+// fetchEach fetches every key with up to concurrency parallel calls, for
+// upstreams without a batch endpoint
+func fetchEach[V any](concurrency int, fetch func(ctx context.Context, key string) (V, error)) func(ctx context.Context, keys []string) []result[V] {
+	return func(ctx context.Context, keys []string) []result[V] {
+		results := make([]result[V], len(keys))
+		sem := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i].value, results[i].err = fetch(ctx, key)
+			}()
+		}
+		wg.Wait()
+		return results
+	}
+}
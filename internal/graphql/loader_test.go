@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoaderBatchesConcurrentKeys(t *testing.T) {
+	var batches atomic.Int32
+	l := newLoader(context.Background(), func(_ context.Context, keys []string) []result[string] {
+		batches.Add(1)
+		results := make([]result[string], len(keys))
+		for i, key := range keys {
+			results[i].value = "v" + key
+		}
+		return results
+	})
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "a", "c"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := l.Load(context.Background(), key); err != nil || v != "v"+key {
+				t.Errorf("Load(%q) = %q, %v", key, v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := batches.Load(); n != 1 {
+		t.Fatalf("expected 1 batch, got %d", n)
+	}
+
+	// Cached keys cost no further fetch
+	if _, err := l.Load(context.Background(), "b"); err != nil || batches.Load() != 1 {
+		t.Fatalf("expected a cached value, got %v after %d batches", err, batches.Load())
+	}
+}
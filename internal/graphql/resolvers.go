@@ -0,0 +1,455 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/twittertext"
+	"twitterx-api/pkg/twitterx"
+)
+
+// maxFirst bounds how many tweets a connection returns
+const maxFirst = 100
+
+// resolver is the Query root
+type resolver struct{}
+
+func (*resolver) User(ctx context.Context, args struct{ ScreenName string }) (*userResolver, error) {
+	return loadUser(ctx, args.ScreenName)
+}
+
+func (*resolver) Tweet(ctx context.Context, args struct{ ID graphqlgo.ID }) (*tweetResolver, error) {
+	return loadTweet(ctx, string(args.ID))
+}
+
+type connectionArgs struct {
+	First int32
+	After *string
+}
+
+func (*resolver) Timeline(ctx context.Context, args struct {
+	ScreenName string
+	connectionArgs
+}) (*connectionResolver, error) {
+	return loadTimeline(ctx, args.ScreenName, args.connectionArgs)
+}
+
+// loadUser returns nil for a missing user
+func loadUser(ctx context.Context, screenName string) (*userResolver, error) {
+	user, err := loadersFrom(ctx).users.Load(ctx, strings.ToLower(screenName))
+	if twitterx.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{user}, nil
+}
+
+// loadTweet returns nil for a missing tweet
+func loadTweet(ctx context.Context, id string) (*tweetResolver, error) {
+	tweet, err := loadersFrom(ctx).tweets.Load(ctx, id)
+	if twitterx.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tweetResolver{tweet}, nil
+}
+
+// loadTimeline collects the first tweet IDs after the cursor, reading as
+// many Nitter pages as needed
+func loadTimeline(ctx context.Context, screenName string, args connectionArgs) (*connectionResolver, error) {
+	if args.First < 0 || args.First > maxFirst {
+		return nil, &apperror.ValidationError{Field: "first", Message: fmt.Sprintf("must be between 0 and %d", maxFirst)}
+	}
+	var pos cursor
+	if args.After != nil {
+		var err error
+		if pos, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	pages := loadersFrom(ctx).pages
+	conn := &connectionResolver{tweetIDs: []string{}}
+	for {
+		page, err := pages.Load(ctx, pageKey(screenName, pos.Page))
+		if err != nil {
+			return nil, err
+		}
+		available := page.TweetIDs[min(pos.Offset, len(page.TweetIDs)):]
+		take := min(int(args.First)-len(conn.tweetIDs), len(available))
+		conn.tweetIDs = append(conn.tweetIDs, available[:take]...)
+		pos.Offset += take
+
+		switch {
+		case take < len(available):
+			conn.hasNext = true
+		case page.NextCursor == "" || page.NextCursor == pos.Page:
+			conn.hasNext = false
+		default:
+			pos = cursor{Page: page.NextCursor}
+			conn.hasNext = true
+		}
+		if !conn.hasNext || len(conn.tweetIDs) == int(args.First) {
+			break
+		}
+	}
+	end := pos.encode()
+	conn.endCursor = &end
+	return conn, nil
+}
+
+// cursor is a position in a timeline: a Nitter page and an offset into it
+type cursor struct {
+	Page   string `json:"p,omitempty"`
+	Offset int    `json:"o,omitempty"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Offset < 0 {
+		return cursor{}, &apperror.ValidationError{Field: "after", Message: "invalid cursor"}
+	}
+	return c, nil
+}
+
+type userResolver struct {
+	u *twitterx.User
+}
+
+func (r *userResolver) ID() graphqlgo.ID          { return graphqlgo.ID(r.u.ID) }
+func (r *userResolver) ScreenName() string        { return r.u.ScreenName }
+func (r *userResolver) Name() string              { return r.u.Name }
+func (r *userResolver) Description() string       { return r.u.Description }
+func (r *userResolver) Location() string          { return r.u.Location }
+func (r *userResolver) URL() string               { return r.u.URL }
+func (r *userResolver) Website() *string          { return r.u.Website }
+func (r *userResolver) AvatarURL() string         { return r.u.AvatarURL }
+func (r *userResolver) BannerURL() string         { return r.u.BannerURL }
+func (r *userResolver) Joined() string            { return r.u.Joined }
+func (r *userResolver) Protected() bool           { return r.u.Protected }
+func (r *userResolver) Followers() long           { return long(r.u.Followers) }
+func (r *userResolver) Following() long           { return long(r.u.Following) }
+func (r *userResolver) Likes() long               { return long(r.u.Likes) }
+func (r *userResolver) Tweets() long              { return long(r.u.Tweets) }
+func (r *userResolver) MediaCount() long          { return long(r.u.MediaCount) }
+func (r *userResolver) Verified() bool            { return r.u.Verification != nil && r.u.Verification.Verified }
+func (r *userResolver) VerificationType() *string { return verificationType(r.u.Verification) }
+
+func verificationType(v *twitterx.Verification) *string {
+	if v == nil || v.Type == "" {
+		return nil
+	}
+	return &v.Type
+}
+
+func (r *userResolver) Timeline(ctx context.Context, args connectionArgs) (*connectionResolver, error) {
+	return loadTimeline(ctx, r.u.ScreenName, args)
+}
+
+// PinnedTweet reads the first page of the timeline, which lists the pinned
+// tweet
+func (r *userResolver) PinnedTweet(ctx context.Context) (*tweetResolver, error) {
+	page, err := loadersFrom(ctx).pages.Load(ctx, pageKey(r.u.ScreenName, ""))
+	if err != nil {
+		return nil, err
+	}
+	id := page.PinnedTweetID()
+	if id == "" {
+		return nil, nil
+	}
+	return loadTweet(ctx, id)
+}
+
+type tweetResolver struct {
+	t *twitterx.Tweet
+}
+
+func (r *tweetResolver) ID() graphqlgo.ID        { return graphqlgo.ID(r.t.ID) }
+func (r *tweetResolver) URL() string             { return r.t.URL }
+func (r *tweetResolver) Text() string            { return r.t.Text }
+func (r *tweetResolver) CreatedAt() string       { return formatTime(r.t.CreatedAt.Time) }
+func (r *tweetResolver) Lang() string            { return r.t.Lang }
+func (r *tweetResolver) Source() string          { return r.t.Source }
+func (r *tweetResolver) Replies() long           { return long(r.t.Replies) }
+func (r *tweetResolver) Retweets() long          { return long(r.t.Retweets) }
+func (r *tweetResolver) Likes() long             { return long(r.t.Likes) }
+func (r *tweetResolver) PossiblySensitive() bool { return r.t.PossiblySensitive }
+
+func (r *tweetResolver) Views() *long {
+	if r.t.Views == nil {
+		return nil
+	}
+	v := long(*r.t.Views)
+	return &v
+}
+
+func (r *tweetResolver) Author(ctx context.Context) (*userResolver, error) {
+	if r.t.Author.ScreenName == "" {
+		return nil, nil
+	}
+	return loadUser(ctx, r.t.Author.ScreenName)
+}
+
+func (r *tweetResolver) Media() []*mediaResolver {
+	resolvers := []*mediaResolver{}
+	if r.t.Media != nil {
+		for i := range r.t.Media.All {
+			resolvers = append(resolvers, &mediaResolver{&r.t.Media.All[i]})
+		}
+	}
+	return resolvers
+}
+
+func (r *tweetResolver) Poll() *pollResolver {
+	if r.t.Poll == nil {
+		return nil
+	}
+	return &pollResolver{r.t.Poll}
+}
+
+// Quote is embedded in the tweet, so it costs no upstream call
+func (r *tweetResolver) Quote() *tweetResolver {
+	if r.t.Quote == nil {
+		return nil
+	}
+	return &tweetResolver{r.t.Quote}
+}
+
+func (r *tweetResolver) ReplyingTo(ctx context.Context) (*tweetResolver, error) {
+	if r.t.ReplyingToStatus == nil || *r.t.ReplyingToStatus == "" {
+		return nil, nil
+	}
+	return loadTweet(ctx, *r.t.ReplyingToStatus)
+}
+
+func (r *tweetResolver) ReplyingToScreenName() *string {
+	return r.t.ReplyingTo
+}
+
+func (r *tweetResolver) Entities() *entitiesResolver {
+	if r.t.Entities == nil {
+		return &entitiesResolver{twittertext.Extract(r.t.Text)}
+	}
+	return &entitiesResolver{r.t.Entities}
+}
+
+type entitiesResolver struct {
+	e *twitterx.Entities
+}
+
+func (r *entitiesResolver) Hashtags() []*hashtagResolver {
+	resolvers := make([]*hashtagResolver, len(r.e.Hashtags))
+	for i := range r.e.Hashtags {
+		resolvers[i] = &hashtagResolver{&r.e.Hashtags[i]}
+	}
+	return resolvers
+}
+
+func (r *entitiesResolver) Mentions() []*mentionResolver {
+	resolvers := make([]*mentionResolver, len(r.e.Mentions))
+	for i := range r.e.Mentions {
+		resolvers[i] = &mentionResolver{&r.e.Mentions[i]}
+	}
+	return resolvers
+}
+
+func (r *entitiesResolver) URLs() []*urlEntityResolver {
+	resolvers := make([]*urlEntityResolver, len(r.e.URLs))
+	for i := range r.e.URLs {
+		resolvers[i] = &urlEntityResolver{&r.e.URLs[i]}
+	}
+	return resolvers
+}
+
+func (r *entitiesResolver) Cashtags() []*cashtagResolver {
+	resolvers := make([]*cashtagResolver, len(r.e.Cashtags))
+	for i := range r.e.Cashtags {
+		resolvers[i] = &cashtagResolver{&r.e.Cashtags[i]}
+	}
+	return resolvers
+}
+
+type hashtagResolver struct {
+	h *twitterx.Hashtag
+}
+
+func (r *hashtagResolver) Start() int32 { return int32(r.h.Start) }
+func (r *hashtagResolver) End() int32   { return int32(r.h.End) }
+func (r *hashtagResolver) Tag() string  { return r.h.Tag }
+
+type mentionResolver struct {
+	m *twitterx.Mention
+}
+
+func (r *mentionResolver) Start() int32       { return int32(r.m.Start) }
+func (r *mentionResolver) End() int32         { return int32(r.m.End) }
+func (r *mentionResolver) ScreenName() string { return r.m.ScreenName }
+
+type urlEntityResolver struct {
+	u *twitterx.URLEntity
+}
+
+func (r *urlEntityResolver) Start() int32        { return int32(r.u.Start) }
+func (r *urlEntityResolver) End() int32          { return int32(r.u.End) }
+func (r *urlEntityResolver) URL() string         { return r.u.URL }
+func (r *urlEntityResolver) ExpandedURL() string { return r.u.ExpandedURL }
+func (r *urlEntityResolver) DisplayURL() string  { return r.u.DisplayURL }
+
+type cashtagResolver struct {
+	c *twitterx.Cashtag
+}
+
+func (r *cashtagResolver) Start() int32 { return int32(r.c.Start) }
+func (r *cashtagResolver) End() int32   { return int32(r.c.End) }
+func (r *cashtagResolver) Tag() string  { return r.c.Tag }
+
+type mediaResolver struct {
+	m *twitterx.MediaItem
+}
+
+func (r *mediaResolver) Type() string       { return r.m.Type }
+func (r *mediaResolver) URL() string        { return r.m.URL }
+func (r *mediaResolver) Width() int32       { return int32(r.m.Width) }
+func (r *mediaResolver) Height() int32      { return int32(r.m.Height) }
+func (r *mediaResolver) Duration() *float64 { return r.m.Duration }
+
+func (r *mediaResolver) ThumbnailURL() *string {
+	if r.m.ThumbnailURL == "" {
+		return nil
+	}
+	return &r.m.ThumbnailURL
+}
+
+type pollResolver struct {
+	p *twitterx.Poll
+}
+
+func (r *pollResolver) TotalVotes() long      { return long(r.p.TotalVotes) }
+func (r *pollResolver) EndsAt() string        { return formatTime(r.p.EndsAt.Time) }
+func (r *pollResolver) TimeRemaining() string { return r.p.TimeRemaining }
+
+func (r *pollResolver) Choices() []*pollChoiceResolver {
+	resolvers := make([]*pollChoiceResolver, len(r.p.Choices))
+	for i := range r.p.Choices {
+		resolvers[i] = &pollChoiceResolver{&r.p.Choices[i]}
+	}
+	return resolvers
+}
+
+type pollChoiceResolver struct {
+	c *twitterx.PollChoice
+}
+
+func (r *pollChoiceResolver) Label() string     { return r.c.Label }
+func (r *pollChoiceResolver) Count() long       { return long(r.c.Count) }
+func (r *pollChoiceResolver) Percentage() int32 { return int32(r.c.Percentage) }
+
+type connectionResolver struct {
+	tweetIDs  []string
+	endCursor *string
+	hasNext   bool
+}
+
+func (r *connectionResolver) TweetIds() []graphqlgo.ID {
+	ids := make([]graphqlgo.ID, len(r.tweetIDs))
+	for i, id := range r.tweetIDs {
+		ids[i] = graphqlgo.ID(id)
+	}
+	return ids
+}
+
+// Nodes requests every tweet at once, so the loader fetches them as one batch
+func (r *connectionResolver) Nodes(ctx context.Context) ([]*tweetResolver, error) {
+	tweets := loadersFrom(ctx).tweets
+	results := make([]result[*twitterx.Tweet], len(r.tweetIDs))
+	done := make(chan struct{})
+	for i, id := range r.tweetIDs {
+		go func() {
+			results[i].value, results[i].err = tweets.Load(ctx, id)
+			done <- struct{}{}
+		}()
+	}
+	for range r.tweetIDs {
+		<-done
+	}
+
+	nodes := []*tweetResolver{}
+	for _, res := range results {
+		if twitterx.IsNotFound(res.err) {
+			continue
+		}
+		if res.err != nil {
+			return nil, res.err
+		}
+		nodes = append(nodes, &tweetResolver{res.value})
+	}
+	return nodes, nil
+}
+
+func (r *connectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{endCursor: r.endCursor, hasNext: r.hasNext}
+}
+
+type pageInfoResolver struct {
+	endCursor *string
+	hasNext   bool
+}
+
+func (r *pageInfoResolver) EndCursor() *string { return r.endCursor }
+func (r *pageInfoResolver) HasNextPage() bool  { return r.hasNext }
+
+// long is the Long scalar
+type long int64
+
+func (long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+func (l *long) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case int32:
+		*l = long(v)
+	case int64:
+		*l = long(v)
+	case float64:
+		*l = long(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+		*l = long(n)
+	default:
+		return fmt.Errorf("invalid Long %v", input)
+	}
+	return nil
+}
+
+func (l long) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(l), 10), nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
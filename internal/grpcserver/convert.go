@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+	pb "twitterx-api/pkg/twitterxpb"
+)
+
+func toUser(u *twitterx.User) *pb.User {
+	user := &pb.User{
+		ScreenName:    u.ScreenName,
+		Url:           u.URL,
+		Id:            u.ID,
+		Followers:     u.Followers,
+		Following:     u.Following,
+		Likes:         u.Likes,
+		MediaCount:    u.MediaCount,
+		Tweets:        u.Tweets,
+		Name:          u.Name,
+		Description:   u.Description,
+		Location:      u.Location,
+		BannerUrl:     u.BannerURL,
+		AvatarUrl:     u.AvatarURL,
+		Joined:        u.Joined,
+		Protected:     u.Protected,
+		Website:       u.Website,
+		PinnedTweetId: u.PinnedTweetID,
+	}
+	if u.Verification != nil {
+		user.Verification = &pb.Verification{Verified: u.Verification.Verified, Type: u.Verification.Type}
+	}
+	return user
+}
+
+func toTweet(t *twitterx.Tweet) *pb.Tweet {
+	tweet := &pb.Tweet{
+		Url:               t.URL,
+		Id:                t.ID,
+		Text:              t.Text,
+		Author:            toAuthor(&t.Author),
+		Replies:           t.Replies,
+		Retweets:          t.Retweets,
+		Likes:             t.Likes,
+		Views:             t.Views,
+		CreatedAt:         timestamp(t.CreatedAt.Time),
+		PossiblyScam:      t.PossiblyScam,
+		PossiblySensitive: t.PossiblySensitive,
+		Lang:              t.Lang,
+		Source:            t.Source,
+		ReplyingTo:        t.ReplyingTo,
+		ReplyingToStatus:  t.ReplyingToStatus,
+	}
+	if t.Media != nil {
+		for _, m := range t.Media.All {
+			tweet.Media = append(tweet.Media, &pb.MediaItem{
+				Type:         m.Type,
+				Url:          m.URL,
+				Width:        int32(m.Width),
+				Height:       int32(m.Height),
+				Format:       m.Format,
+				ThumbnailUrl: m.ThumbnailURL,
+				Duration:     m.Duration,
+			})
+		}
+	}
+	if t.Poll != nil {
+		tweet.Poll = &pb.Poll{
+			TotalVotes:    t.Poll.TotalVotes,
+			EndsAt:        timestamp(t.Poll.EndsAt.Time),
+			TimeRemaining: t.Poll.TimeRemaining,
+		}
+		for _, c := range t.Poll.Choices {
+			tweet.Poll.Choices = append(tweet.Poll.Choices, &pb.PollChoice{Label: c.Label, Count: c.Count, Percentage: int32(c.Percentage)})
+		}
+	}
+	if t.Quote != nil {
+		tweet.Quote = toTweet(t.Quote)
+	}
+	if t.Translation != nil {
+		tweet.Translation = &pb.Translation{
+			Text:           t.Translation.Text,
+			SourceLang:     t.Translation.SourceLang,
+			TargetLang:     t.Translation.TargetLang,
+			TranslationUrl: t.Translation.TranslationURL,
+		}
+	}
+	if t.Entities != nil {
+		tweet.Entities = toEntities(t.Entities)
+	}
+	return tweet
+}
+
+func toEntities(e *twitterx.Entities) *pb.Entities {
+	entities := &pb.Entities{}
+	for _, h := range e.Hashtags {
+		entities.Hashtags = append(entities.Hashtags, &pb.Hashtag{Start: int32(h.Start), End: int32(h.End), Tag: h.Tag})
+	}
+	for _, m := range e.Mentions {
+		entities.Mentions = append(entities.Mentions, &pb.Mention{Start: int32(m.Start), End: int32(m.End), ScreenName: m.ScreenName})
+	}
+	for _, u := range e.URLs {
+		entities.Urls = append(entities.Urls, &pb.UrlEntity{
+			Start:       int32(u.Start),
+			End:         int32(u.End),
+			Url:         u.URL,
+			ExpandedUrl: u.ExpandedURL,
+			DisplayUrl:  u.DisplayURL,
+		})
+	}
+	for _, c := range e.Cashtags {
+		entities.Cashtags = append(entities.Cashtags, &pb.Cashtag{Start: int32(c.Start), End: int32(c.End), Tag: c.Tag})
+	}
+	return entities
+}
+
+func toDeletedTweet(d tracker.DeletedTweet) *pb.DeletedTweet {
+	deleted := &pb.DeletedTweet{
+		Id:         d.ID,
+		ScreenName: d.Username,
+		CreatedAt:  timestamp(d.CreatedAt),
+		LastSeen:   timestamp(d.LastSeen),
+		DetectedAt: timestamp(d.DetectedAt),
+		Text:       d.Text,
+	}
+	if d.Entities != nil {
+		deleted.Entities = toEntities(d.Entities)
+	}
+	if d.Tweet != nil {
+		deleted.Tweet = toTweet(d.Tweet)
+	}
+	return deleted
+}
+
+func toAuthor(a *twitterx.Author) *pb.Author {
+	return &pb.Author{
+		Id:          a.ID,
+		Name:        a.Name,
+		ScreenName:  a.ScreenName,
+		AvatarUrl:   a.AvatarURL,
+		BannerUrl:   a.BannerURL,
+		Description: a.Description,
+		Location:    a.Location,
+		Url:         a.URL,
+		Followers:   a.Followers,
+		Following:   a.Following,
+		Joined:      a.Joined,
+		Likes:       a.Likes,
+		Tweets:      a.Tweets,
+		Verified:    a.Verified,
+		BlueBadge:   a.BlueBadge,
+	}
+}
+
+// timestamp returns nil for the zero time
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
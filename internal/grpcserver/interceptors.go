@@ -0,0 +1,208 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/ratelimit"
+	pb "twitterx-api/pkg/twitterxpb"
+)
+
+// methodScopes lists the scope each TwitterX method needs, as the matching
+// REST endpoints do
+var methodScopes = map[string]auth.Scope{
+	pb.TwitterX_GetUser_FullMethodName:        auth.ScopeRead,
+	pb.TwitterX_GetTweet_FullMethodName:       auth.ScopeRead,
+	pb.TwitterX_BatchGetTweets_FullMethodName: auth.ScopeRead,
+	pb.TwitterX_GetTimeline_FullMethodName:    auth.ScopeRead,
+	pb.TwitterX_WatchUser_FullMethodName:      auth.ScopeStream,
+	pb.TwitterX_WatchDeletions_FullMethodName: auth.ScopeStream,
+}
+
+// authenticate checks the API key of a call to the TwitterX service and
+// returns ctx carrying the principal. Other services, such as health and
+// reflection, need no key.
+func authenticate(ctx context.Context, a *auth.Authenticator, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if a == nil || !ok {
+		return ctx, nil
+	}
+
+	ctx, err := a.Authenticate(ctx, keyFromMetadata(ctx), peerIP(ctx))
+	if err == nil {
+		err = auth.CheckScope(ctx, scope)
+	}
+	return ctx, err
+}
+
+// keyFromMetadata returns the API key from the x-api-key metadata or a
+// bearer token
+func keyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if key := md.Get("x-api-key"); len(key) > 0 && key[0] != "" {
+		return key[0]
+	}
+	if authz := md.Get("authorization"); len(authz) > 0 && len(authz[0]) > 7 && strings.EqualFold(authz[0][:7], "bearer ") {
+		return strings.TrimSpace(authz[0][7:])
+	}
+	return ""
+}
+
+// peerIP returns the IP address of the caller
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func unaryAuth(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		authCtx, err := authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			logger.WarnContext(ctx, "Auth: rejected call: %v", err)
+			return nil, statusError(err)
+		}
+		return handler(authCtx, req)
+	}
+}
+
+func streamAuth(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			logger.WarnContext(ss.Context(), "Auth: rejected call: %v", err)
+			return statusError(err)
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// rateLimit rejects a call to the TwitterX service once the bucket of its
+// client is empty. It runs after authentication, so clients are keyed by
+// validated key, as auth.ClientKey does for the REST API.
+func rateLimit(ctx context.Context, limiter *ratelimit.Keyed, method string) error {
+	if _, ok := methodScopes[method]; limiter == nil || !ok {
+		return nil
+	}
+	if ok, wait := limiter.Allow(auth.ClientKey(ctx, peerIP(ctx)), time.Now()); !ok {
+		err := &apperror.RateLimitError{Message: "too many requests", RetryAfter: wait}
+		logger.WarnContext(ctx, "Rate limit: rejected call: %v", err)
+		return err
+	}
+	return nil
+}
+
+func unaryRateLimit(limiter *ratelimit.Keyed) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := rateLimit(ctx, limiter, info.FullMethod); err != nil {
+			return nil, statusError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamRateLimit(limiter *ratelimit.Keyed) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(ss.Context(), limiter, info.FullMethod); err != nil {
+			return statusError(err)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// unaryBudget bounds the total time spent serving a unary call, like the
+// request budget of the REST API
+func unaryBudget(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// unaryLogging writes one access log line per call
+func unaryLogging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx = logger.WithFields(ctx, "grpc_method", info.FullMethod)
+	resp, err := handler(ctx, req)
+	logCall(ctx, start, err)
+	return resp, err
+}
+
+// streamLogging writes one access log line per stream, when it ends
+func streamLogging(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := logger.WithFields(ss.Context(), "grpc_method", info.FullMethod)
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, start, err)
+	return err
+}
+
+func logCall(ctx context.Context, start time.Time, err error) {
+	logger.Logger().InfoContext(ctx, "call completed",
+		"code", status.Code(err).String(),
+		"latency_ms", time.Since(start).Milliseconds(),
+	)
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// statusError converts an application error to a gRPC status with the code
+// matching the HTTP status /api/v2 answers it with
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch apperror.DetailedHTTPStatusCode(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		code = codes.NotFound
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	case apperror.StatusClientClosedRequest:
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,132 @@
+// Package grpcserver serves the TwitterX API over gRPC on the same client
+// as the REST API, together with the standard health and reflection services.
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/ratelimit"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+	pb "twitterx-api/pkg/twitterxpb"
+)
+
+const (
+	// defaultWatchInterval is the WatchUser poll interval when the caller sets none
+	defaultWatchInterval = time.Minute
+	// defaultMinWatchInterval is the shortest poll interval a caller may ask for
+	defaultMinWatchInterval = 15 * time.Second
+)
+
+// Option configures a Server
+type Option func(*options)
+
+type options struct {
+	authenticator    *auth.Authenticator
+	rateLimiter      *ratelimit.Keyed
+	requestTimeout   time.Duration
+	watchInterval    time.Duration
+	minWatchInterval time.Duration
+	deletions        *tracker.DeletionTracker
+}
+
+// WithAuthenticator requires API keys on the TwitterX service, sent in the
+// x-api-key or authorization metadata. Health and reflection stay open.
+func WithAuthenticator(a *auth.Authenticator) Option {
+	return func(o *options) {
+		o.authenticator = a
+	}
+}
+
+// WithRateLimiter limits the calls to the TwitterX service per client, keyed
+// like the REST API so both share the buckets of l
+func WithRateLimiter(l *ratelimit.Keyed) Option {
+	return func(o *options) {
+		o.rateLimiter = l
+	}
+}
+
+// WithRequestTimeout bounds every unary call (0 = unlimited)
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.requestTimeout = d
+	}
+}
+
+// WithWatchIntervals sets the WatchUser poll interval used when the caller
+// sets none, and the shortest one a caller may ask for
+func WithWatchIntervals(interval, minInterval time.Duration) Option {
+	return func(o *options) {
+		o.watchInterval = interval
+		o.minWatchInterval = minInterval
+	}
+}
+
+// WithDeletions streams the deletions detected by d to WatchDeletions
+// callers. Without it no account is watched.
+func WithDeletions(d *tracker.DeletionTracker) Option {
+	return func(o *options) {
+		o.deletions = d
+	}
+}
+
+// Server is a gRPC server with the TwitterX, health and reflection services
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// New creates a server backed by client
+func New(client *twitterx.Client, opts ...Option) *Server {
+	o := options{watchInterval: defaultWatchInterval, minWatchInterval: defaultMinWatchInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &Server{
+		grpc: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(unaryLogging, unaryAuth(o.authenticator), unaryRateLimit(o.rateLimiter), unaryBudget(o.requestTimeout)),
+			grpc.ChainStreamInterceptor(streamLogging, streamAuth(o.authenticator), streamRateLimit(o.rateLimiter)),
+		),
+		health: health.NewServer(),
+	}
+	pb.RegisterTwitterXServer(s.grpc, &service{
+		client:           client,
+		watchInterval:    o.watchInterval,
+		minWatchInterval: o.minWatchInterval,
+		deletions:        o.deletions,
+	})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+	s.health.SetServingStatus(pb.TwitterX_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	return s
+}
+
+// Serve accepts connections on lis until the server stops
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown reports NOT_SERVING, waits for calls in flight and closes the
+// remaining ones, such as WatchUser streams, once ctx is done
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
@@ -0,0 +1,316 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"twitterx-api/internal/auth"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/ratelimit"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+	pb "twitterx-api/pkg/twitterxpb"
+)
+
+// newTestClient fakes FxTwitter and a Nitter timeline with pinned tweet 5
+// that gains tweet 21 after the first poll
+func newTestClient(t *testing.T) *twitterx.Client {
+	t.Helper()
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jack":
+			w.Write([]byte(`{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12","followers":42,"website":"https://example.com"}}`))
+		case "/i/status/20", "/jack/status/20":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"20","text":"just setting up my twttr","created_at":"Tue Mar 21 20:50:14 +0000 2006","author":{"screen_name":"jack"}}}`))
+		case "/jack/status/21":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"21","text":"second"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}
+	}))
+	t.Cleanup(fx.Close)
+
+	var polls atomic.Int32
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := `<item><guid>https://nitter.net/jack/status/20#m</guid></item>`
+		if polls.Add(1) > 1 {
+			items = `<item><guid>https://nitter.net/jack/status/21#m</guid></item>` + items
+		}
+		items = `<item><title>Pinned: hello</title><guid>https://nitter.net/jack/status/5#m</guid></item>` + items
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>jack</title>%s</channel></rss>`, items)
+	}))
+	t.Cleanup(nitter.Close)
+
+	client, err := twitterx.New(
+		twitterx.WithFxTwitterURL(fx.URL),
+		twitterx.WithNitterInstances(nitter.URL),
+		twitterx.WithTimelineCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// dial starts s on an in-memory listener and returns a connection to it
+func dial(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUnaryMethods(t *testing.T) {
+	client := pb.NewTwitterXClient(dial(t, New(newTestClient(t))))
+	ctx := context.Background()
+
+	// Without a cached timeline the pinned tweet is unknown
+	user, err := client.GetUser(ctx, &pb.GetUserRequest{ScreenName: "jack"})
+	if err != nil || user.GetFollowers() != 42 || user.GetWebsite() != "https://example.com" || user.GetPinnedTweetId() != "" {
+		t.Fatalf("unexpected user %v, %v", user, err)
+	}
+
+	tweet, err := client.GetTweet(ctx, &pb.GetTweetRequest{Id: "https://x.com/jack/status/20"})
+	if err != nil || tweet.GetText() != "just setting up my twttr" || tweet.GetAuthor().GetScreenName() != "jack" {
+		t.Fatalf("unexpected tweet %v, %v", tweet, err)
+	}
+	if want := time.Date(2006, time.March, 21, 20, 50, 14, 0, time.UTC); !tweet.GetCreatedAt().AsTime().Equal(want) {
+		t.Fatalf("expected created_at %s, got %s", want, tweet.GetCreatedAt().AsTime())
+	}
+
+	_, err = client.GetTweet(ctx, &pb.GetTweetRequest{Id: "404"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+	_, err = client.GetTweet(ctx, &pb.GetTweetRequest{Id: "not a tweet"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+
+	batch, err := client.BatchGetTweets(ctx, &pb.BatchGetTweetsRequest{Ids: []string{"404", "20"}})
+	if err != nil {
+		t.Fatalf("BatchGetTweets: %v", err)
+	}
+	results := batch.GetTweets()
+	if len(results) != 2 || results[0].GetError().GetClass() != "not_found" || results[1].GetTweet().GetId() != "20" {
+		t.Fatalf("unexpected batch %v", results)
+	}
+	_, err = client.BatchGetTweets(ctx, &pb.BatchGetTweetsRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for an empty batch, got %v", err)
+	}
+
+	timeline, err := client.GetTimeline(ctx, &pb.GetTimelineRequest{ScreenName: "jack"})
+	if err != nil || strings.Join(timeline.GetTweetIds(), ",") != "5,20" || timeline.GetPinnedTweetId() != "5" {
+		t.Fatalf("unexpected timeline %v, %v", timeline, err)
+	}
+}
+
+func TestWatchUser(t *testing.T) {
+	s := New(newTestClient(t), WithWatchIntervals(10*time.Millisecond, 10*time.Millisecond))
+	client := pb.NewTwitterXClient(dial(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchUser(ctx, &pb.WatchUserRequest{ScreenName: "jack"})
+	if err != nil {
+		t.Fatalf("WatchUser: %v", err)
+	}
+	tweet, err := stream.Recv()
+	if err != nil || tweet.GetId() != "21" {
+		t.Fatalf("expected new tweet 21, got %v, %v", tweet, err)
+	}
+}
+
+func TestWatchDeletions(t *testing.T) {
+	id := strconv.FormatInt((time.Now().Add(-time.Hour).UnixMilli()-1288834974657)<<22, 10)
+	var deleted atomic.Bool
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/jack":
+			w.Write([]byte(`{"code":200,"message":"OK","user":{"screen_name":"jack"}}`))
+		case r.URL.Path == "/jack/status/"+id && !deleted.Load():
+			fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"oops"}}`, id)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}
+	}))
+	defer fx.Close()
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<rss><channel><item><title>oops</title><description>&lt;p&gt;oops&lt;/p&gt;</description><guid>https://nitter.net/jack/status/%s#m</guid></item></channel></rss>`, id)
+	}))
+	defer nitter.Close()
+	tc, err := twitterx.New(twitterx.WithFxTwitterURL(fx.URL), twitterx.WithNitterInstances(nitter.URL), twitterx.WithTweetCacheTTL(0))
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	defer tc.Close()
+	deletions, err := tracker.NewDeletionTracker(tc, []string{"jack"})
+	if err != nil {
+		t.Fatalf("NewDeletionTracker: %v", err)
+	}
+	client := pb.NewTwitterXClient(dial(t, New(tc, WithDeletions(deletions))))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchDeletions(ctx, &pb.WatchDeletionsRequest{ScreenName: "jack"})
+	if err == nil {
+		_, err = stream.Header()
+	}
+	if err != nil {
+		t.Fatalf("WatchDeletions: %v", err)
+	}
+	deletions.Poll(ctx)
+	deleted.Store(true)
+	deletions.Poll(ctx)
+	deletions.Poll(ctx)
+
+	event, err := stream.Recv()
+	if err != nil || event.GetId() != id || event.GetText() != "oops" || event.GetTweet().GetText() != "oops" || event.GetDetectedAt() == nil {
+		t.Fatalf("unexpected deletion %v, %v", event, err)
+	}
+
+	stream, err = client.WatchDeletions(ctx, &pb.WatchDeletionsRequest{ScreenName: "ev"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an account that is not watched, got %v", err)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	authenticator, err := auth.New([]auth.Key{{Name: "reader", Key: "secret", Scopes: []auth.Scope{auth.ScopeRead}}}, auth.AnonymousTier{})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	conn := dial(t, New(newTestClient(t), WithAuthenticator(authenticator)))
+	client := pb.NewTwitterXClient(conn)
+
+	_, err = client.GetUser(context.Background(), &pb.GetUserRequest{ScreenName: "jack"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without a key, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.GetUser(ctx, &pb.GetUserRequest{ScreenName: "jack"}); err != nil {
+		t.Fatalf("unexpected error with a key: %v", err)
+	}
+
+	// WatchUser needs the stream scope
+	stream, err := client.WatchUser(ctx, &pb.WatchUserRequest{ScreenName: "jack"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+
+	// Health checks need no key
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: pb.TwitterX_ServiceDesc.ServiceName})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v, %v", resp, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	authenticator, err := auth.New([]auth.Key{{Name: "watcher", Key: "secret", Scopes: []auth.Scope{auth.ScopeRead, auth.ScopeStream}}},
+		auth.AnonymousTier{Enabled: true, Scopes: []auth.Scope{auth.ScopeRead}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	limiter := ratelimit.NewKeyed(func() *ratelimit.Bucket { return ratelimit.NewBucket(0.001, 1) }, time.Minute)
+	conn := dial(t, New(newTestClient(t), WithAuthenticator(authenticator), WithRateLimiter(limiter)))
+	client := pb.NewTwitterXClient(conn)
+
+	ctx := context.Background()
+	if _, err := client.GetUser(ctx, &pb.GetUserRequest{ScreenName: "jack"}); err != nil {
+		t.Fatalf("unexpected error on the first call: %v", err)
+	}
+	if _, err := client.GetUser(ctx, &pb.GetUserRequest{ScreenName: "jack"}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted on the second anonymous call, got %v", err)
+	}
+
+	// A validated key has a bucket of its own
+	keyed := metadata.AppendToOutgoingContext(ctx, "x-api-key", "secret")
+	if _, err := client.GetUser(keyed, &pb.GetUserRequest{ScreenName: "jack"}); err != nil {
+		t.Fatalf("unexpected error with a key: %v", err)
+	}
+	stream, err := client.WatchUser(keyed, &pb.WatchUserRequest{ScreenName: "jack"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted on a stream, got %v", err)
+	}
+
+	// Health checks are not limited
+	for range 2 {
+		if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatalf("unexpected error on a health check: %v", err)
+		}
+	}
+}
+
+func TestRejectedCallLogsRequestFields(t *testing.T) {
+	var logs bytes.Buffer
+	if err := logger.Configure(logger.Config{Output: &logs}); err != nil {
+		t.Fatalf("logger.Configure: %v", err)
+	}
+	t.Cleanup(func() { logger.Configure(logger.Config{}) })
+	authenticator, err := auth.New([]auth.Key{{Name: "reader", Key: "secret"}}, auth.AnonymousTier{})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+
+	ctx := logger.WithFields(context.Background(), "grpc_method", pb.TwitterX_GetUser_FullMethodName)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.TwitterX_GetUser_FullMethodName}
+	_, err = unaryAuth(authenticator)(ctx, nil, info, func(context.Context, any) (any, error) {
+		t.Fatal("handler called without a key")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "grpc_method="+pb.TwitterX_GetUser_FullMethodName) {
+		t.Fatalf("expected the rejection logged with the call fields, got %q", logs.String())
+	}
+}
+
+func TestReflectionRegistered(t *testing.T) {
+	services := New(newTestClient(t)).grpc.GetServiceInfo()
+	for _, name := range []string{pb.TwitterX_ServiceDesc.ServiceName, "grpc.health.v1.Health", "grpc.reflection.v1.ServerReflection"} {
+		if _, ok := services[name]; !ok {
+			t.Errorf("service %s not registered", name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/tracker"
+	"twitterx-api/pkg/twitterx"
+	pb "twitterx-api/pkg/twitterxpb"
+)
+
+const (
+	// maxBatchSize bounds the IDs of a BatchGetTweets call
+	maxBatchSize = 100
+	// batchConcurrency is how many tweets of a batch are fetched at once
+	batchConcurrency = 8
+)
+
+// service implements the TwitterX gRPC service
+type service struct {
+	pb.UnimplementedTwitterXServer
+	client           *twitterx.Client
+	watchInterval    time.Duration
+	minWatchInterval time.Duration
+	deletions        *tracker.DeletionTracker
+}
+
+func (s *service) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	if req.GetScreenName() == "" {
+		return nil, statusError(&apperror.ValidationError{Field: "screen_name", Message: "required"})
+	}
+	user, err := s.client.GetUserWithPinnedTweet(ctx, req.GetScreenName())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toUser(user), nil
+}
+
+func (s *service) GetTweet(ctx context.Context, req *pb.GetTweetRequest) (*pb.Tweet, error) {
+	ref, err := twitterx.ParseTweetRef(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if req.GetScreenName() != "" {
+		ref.Username = req.GetScreenName()
+	}
+	tweet, err := s.client.GetTweet(ctx, ref.Username, ref.ID)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTweet(tweet), nil
+}
+
+func (s *service) BatchGetTweets(ctx context.Context, req *pb.BatchGetTweetsRequest) (*pb.BatchGetTweetsResponse, error) {
+	ids := req.GetIds()
+	if len(ids) == 0 || len(ids) > maxBatchSize {
+		return nil, statusError(&apperror.ValidationError{Field: "ids", Message: fmt.Sprintf("between 1 and %d IDs required", maxBatchSize)})
+	}
+	// Links are accepted like in GetTweet; the author doesn't matter to FxTwitter
+	ids = slices.Clone(ids)
+	for i, id := range ids {
+		ref, err := twitterx.ParseTweetRef(id)
+		if err != nil {
+			return nil, statusError(err)
+		}
+		ids[i] = ref.ID
+	}
+
+	resp := &pb.BatchGetTweetsResponse{Tweets: make([]*pb.TweetResult, 0, len(ids))}
+	err := s.client.HydrateTweets(ctx, "i", ids, batchConcurrency, func(id string, tweet *twitterx.Tweet, err error) error {
+		result := &pb.TweetResult{Id: id}
+		if err != nil {
+			// A context error fails the whole batch
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Result = &pb.TweetResult_Error{Error: &pb.Error{Class: twitterx.ErrorClass(err), Message: err.Error()}}
+		} else {
+			result.Result = &pb.TweetResult_Tweet{Tweet: toTweet(tweet)}
+		}
+		resp.Tweets = append(resp.Tweets, result)
+		return nil
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return resp, nil
+}
+
+func (s *service) GetTimeline(ctx context.Context, req *pb.GetTimelineRequest) (*pb.Timeline, error) {
+	if req.GetScreenName() == "" {
+		return nil, statusError(&apperror.ValidationError{Field: "screen_name", Message: "required"})
+	}
+	timeline, err := s.client.GetTimelinePage(ctx, req.GetScreenName(), req.GetCursor())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.Timeline{
+		ScreenName:    req.GetScreenName(),
+		TweetIds:      timeline.TweetIDs,
+		NextCursor:    timeline.NextCursor,
+		PinnedTweetId: timeline.PinnedTweetID(),
+	}, nil
+}
+
+func (s *service) WatchUser(req *pb.WatchUserRequest, stream pb.TwitterX_WatchUserServer) error {
+	if req.GetScreenName() == "" {
+		return statusError(&apperror.ValidationError{Field: "screen_name", Message: "required"})
+	}
+	interval := s.watchInterval
+	if req.GetPollIntervalSeconds() > 0 {
+		interval = max(time.Duration(req.GetPollIntervalSeconds())*time.Second, s.minWatchInterval)
+	}
+
+	ctx := stream.Context()
+	logger.DebugContext(ctx, "Watching %s every %s", req.GetScreenName(), interval)
+	err := s.client.Watch(ctx, req.GetScreenName(), interval, func(tweet *twitterx.Tweet) error {
+		return stream.Send(toTweet(tweet))
+	})
+	return statusError(err)
+}
+
+func (s *service) WatchDeletions(req *pb.WatchDeletionsRequest, stream pb.TwitterX_WatchDeletionsServer) error {
+	events, unsubscribe, err := s.deletions.Subscribe(req.GetScreenName())
+	if err != nil {
+		return statusError(err)
+	}
+	defer unsubscribe()
+	// Headers tell the caller that no deletion will be missed from now on
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	logger.DebugContext(ctx, "Streaming the deletions of %q", req.GetScreenName())
+	for {
+		select {
+		case deleted := <-events:
+			if err := stream.Send(toDeletedTweet(deleted)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return statusError(ctx.Err())
+		}
+	}
+}
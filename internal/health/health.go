@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/metrics"
+)
+
+// Dependency and readiness states
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// Check probes a single dependency
+type Check struct {
+	// Name identifies the dependency, e.g. the Nitter instance URL
+	Name string
+	// Group ties redundant dependencies together; a group is healthy
+	// when at least one of its checks is up
+	Group string
+	// Probe returns nil when the dependency is usable
+	Probe func(ctx context.Context) error
+	// CircuitState, if set, returns the circuit breaker state of the dependency
+	CircuitState func() string
+}
+
+// Result is the outcome of the latest probe of a dependency
+type Result struct {
+	Name              string    `json:"name"`
+	Group             string    `json:"group"`
+	Status            string    `json:"status"`
+	Error             string    `json:"error,omitempty"`
+	ErrorClass        string    `json:"error_class,omitempty"`
+	SessionsExhausted bool      `json:"sessions_exhausted,omitempty"`
+	CircuitState      string    `json:"circuit_state,omitempty"`
+	LatencyMS         int64     `json:"latency_ms"`
+	CheckedAt         time.Time `json:"checked_at"`
+}
+
+// Report is the readiness of the service and the state of every dependency
+type Report struct {
+	Status       string   `json:"status"`
+	Dependencies []Result `json:"dependencies"`
+}
+
+// Ready reports whether the service can serve traffic
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+// Checker runs dependency probes and caches their results so frequent
+// readiness polls don't hammer the upstreams
+type Checker struct {
+	checks  []Check
+	ttl     time.Duration
+	timeout time.Duration
+	metrics metrics.Recorder
+	now     func() time.Time
+
+	mu       sync.Mutex
+	results  map[string]Result
+	inflight map[string]*flight
+}
+
+// flight is a probe in progress, shared by the callers that need it
+type flight struct {
+	done   chan struct{}
+	result Result
+}
+
+// NewChecker creates a checker whose results are reused for ttl. Each probe
+// is bounded by timeout.
+func NewChecker(ttl, timeout time.Duration, m metrics.Recorder, checks ...Check) *Checker {
+	return &Checker{
+		checks:   checks,
+		ttl:      ttl,
+		timeout:  timeout,
+		metrics:  metrics.OrNop(m),
+		now:      time.Now,
+		results:  make(map[string]Result),
+		inflight: make(map[string]*flight),
+	}
+}
+
+// Report returns the state of all dependencies, probing the ones whose
+// cached result is older than the TTL. Concurrent callers share the probes
+// in progress instead of waiting for each other; a caller that gives up
+// sees the dependencies it didn't wait for as canceled.
+func (c *Checker) Report(ctx context.Context) Report {
+	fresh := make([]Result, len(c.checks))
+	flights := make([]*flight, len(c.checks))
+	c.mu.Lock()
+	for i, check := range c.checks {
+		if cached, ok := c.results[check.Name]; ok && c.now().Sub(cached.CheckedAt) < c.ttl {
+			fresh[i] = cached
+			continue
+		}
+		f := c.inflight[check.Name]
+		if f == nil {
+			f = &flight{done: make(chan struct{})}
+			c.inflight[check.Name] = f
+			go c.refresh(ctx, check, f)
+		}
+		flights[i] = f
+	}
+	c.mu.Unlock()
+
+	for i, f := range flights {
+		if f == nil {
+			continue
+		}
+		select {
+		case <-f.done:
+			fresh[i] = f.result
+		case <-ctx.Done():
+			fresh[i] = c.result(c.checks[i], ctx.Err(), 0)
+		}
+	}
+
+	groupUp := make(map[string]bool)
+	for _, result := range fresh {
+		groupUp[result.Group] = groupUp[result.Group] || result.Status == StatusUp
+	}
+	report := Report{Status: StatusReady, Dependencies: fresh}
+	for _, up := range groupUp {
+		if !up {
+			report.Status = StatusNotReady
+		}
+	}
+	return report
+}
+
+// refresh probes check for every caller waiting on f and caches the result.
+// The probe outlives the caller that started it, so it says something about
+// the dependency rather than about that caller.
+func (c *Checker) refresh(ctx context.Context, check Check, f *flight) {
+	f.result = c.probe(context.WithoutCancel(ctx), check)
+	c.mu.Lock()
+	c.results[check.Name] = f.result
+	delete(c.inflight, check.Name)
+	c.mu.Unlock()
+	c.metrics.SetInstanceHealth(check.Group, check.Name, f.result.Status == StatusUp)
+	close(f.done)
+}
+
+func (c *Checker) probe(ctx context.Context, check Check) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := c.now()
+	err := check.Probe(ctx)
+	return c.result(check, err, c.now().Sub(start))
+}
+
+// result is the outcome of a probe of check that returned err
+func (c *Checker) result(check Check, err error, latency time.Duration) Result {
+	result := Result{
+		Name:      check.Name,
+		Group:     check.Group,
+		Status:    StatusUp,
+		LatencyMS: latency.Milliseconds(),
+		CheckedAt: c.now(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		result.ErrorClass = apperror.Class(err)
+		result.SessionsExhausted = errors.Is(err, apperror.ErrSessionsExhausted)
+	}
+	if check.CircuitState != nil {
+		result.CircuitState = check.CircuitState()
+	}
+	return result
+}
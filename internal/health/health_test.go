@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+func TestCheckerReadiness(t *testing.T) {
+	checker := NewChecker(time.Minute, time.Second, nil,
+		Check{Name: "nitter-a", Group: "nitter", Probe: func(context.Context) error {
+			return &apperror.UpstreamError{Service: "Nitter", Err: apperror.ErrSessionsExhausted}
+		}},
+		Check{Name: "nitter-b", Group: "nitter", Probe: func(context.Context) error { return nil }},
+		Check{Name: "fxtwitter", Group: "fxtwitter", Probe: func(context.Context) error { return nil }},
+	)
+
+	report := checker.Report(context.Background())
+	if !report.Ready() {
+		t.Fatalf("expected ready with one healthy Nitter instance, got %#v", report)
+	}
+	if len(report.Dependencies) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(report.Dependencies))
+	}
+	first := report.Dependencies[0]
+	if first.Status != StatusDown || !first.SessionsExhausted || first.ErrorClass != apperror.ClassExhausted {
+		t.Fatalf("unexpected result for exhausted instance: %#v", first)
+	}
+}
+
+func TestCheckerNotReadyWhenGroupDown(t *testing.T) {
+	checker := NewChecker(time.Minute, time.Second, nil,
+		Check{Name: "nitter", Group: "nitter", Probe: func(context.Context) error { return nil }},
+		Check{Name: "fxtwitter", Group: "fxtwitter", Probe: func(context.Context) error { return errors.New("down") }},
+	)
+
+	if report := checker.Report(context.Background()); report.Ready() {
+		t.Fatalf("expected not ready, got %#v", report)
+	}
+}
+
+func TestCheckerCachesResults(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	probes := 0
+	checker := NewChecker(30*time.Second, time.Second, nil,
+		Check{Name: "fxtwitter", Group: "fxtwitter", Probe: func(context.Context) error {
+			probes++
+			return nil
+		}},
+	)
+	checker.now = func() time.Time { return now }
+
+	checker.Report(context.Background())
+	checker.Report(context.Background())
+	if probes != 1 {
+		t.Fatalf("expected cached result, got %d probes", probes)
+	}
+
+	now = now.Add(30 * time.Second)
+	checker.Report(context.Background())
+	if probes != 2 {
+		t.Fatalf("expected re-probe after TTL, got %d probes", probes)
+	}
+}
+
+func TestCheckerSharesProbesInProgress(t *testing.T) {
+	var probes atomic.Int32
+	release := make(chan struct{})
+	checker := NewChecker(time.Minute, time.Second, nil,
+		Check{Name: "fxtwitter", Group: "fxtwitter", Probe: func(context.Context) error {
+			probes.Add(1)
+			<-release
+			return nil
+		}},
+	)
+
+	// A caller that gives up doesn't wait for the slow probe
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if report := checker.Report(ctx); report.Ready() || report.Dependencies[0].ErrorClass != apperror.ClassTimeout {
+		t.Fatalf("expected the abandoned probe to report a timeout, got %#v", report)
+	}
+
+	reports := make(chan Report)
+	go func() { reports <- checker.Report(context.Background()) }()
+	close(release)
+	if report := <-reports; !report.Ready() {
+		t.Fatalf("expected ready once the probe finished, got %#v", report)
+	}
+	if n := probes.Load(); n != 1 {
+		t.Fatalf("expected one shared probe, got %d", n)
+	}
+}
+
+func TestCheckerReportsCircuitState(t *testing.T) {
+	checker := NewChecker(time.Minute, time.Second, nil, Check{
+		Name:         "fxtwitter",
+		Group:        "fxtwitter",
+		Probe:        func(ctx context.Context) error { return nil },
+		CircuitState: func() string { return "half_open" },
+	})
+	report := checker.Report(context.Background())
+	if got := report.Dependencies[0].CircuitState; got != "half_open" {
+		t.Fatalf("expected circuit state half_open, got %q", got)
+	}
+}
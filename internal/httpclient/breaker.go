@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// Breaker is a circuit breaker. It opens after a number of consecutive
+// failures, rejects calls while open, and lets a single trial call through
+// once the open timeout has passed. A nil *Breaker never opens.
+type Breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	state       string
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+	onChange    func(state string)
+	now         func() time.Time
+}
+
+// NewBreaker creates a breaker that opens after threshold consecutive
+// failures and stays open for openTimeout. onChange, if set, is called with
+// the new state on every transition, while the breaker's lock is held.
+// It returns nil when threshold is not positive.
+func NewBreaker(threshold int, openTimeout time.Duration, onChange func(state string)) *Breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	b := &Breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		state:       StateClosed,
+		onChange:    onChange,
+		now:         time.Now,
+	}
+	if onChange != nil {
+		onChange(StateClosed)
+	}
+	return b
+}
+
+// State returns the current state
+func (b *Breaker) State() string {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by Success, Failure or Cancel.
+func (b *Breaker) Allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(StateHalfOpen)
+		b.trialActive = true
+		return true
+	case StateHalfOpen:
+		if b.trialActive {
+			return false
+		}
+		b.trialActive = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a successful call and closes the breaker
+func (b *Breaker) Success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trialActive = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Cancel records that an allowed call was abandoned without an outcome
+func (b *Breaker) Cancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialActive = false
+}
+
+// Failure records a failed call, opening the breaker when the threshold is
+// reached or a trial call fails
+func (b *Breaker) Failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trialActive = false
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		if b.state != StateOpen {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+func (b *Breaker) setState(state string) {
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	var states []string
+	b := NewBreaker(2, time.Minute, func(state string) { states = append(states, state) })
+
+	b.Allow()
+	b.Failure()
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after one failure, got %s", b.State())
+	}
+	b.Allow()
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after two failures, got %s", b.State())
+	}
+	if b.Allow() {
+		t.Fatal("expected open breaker to reject calls")
+	}
+	if len(states) != 2 || states[0] != StateClosed || states[1] != StateOpen {
+		t.Fatalf("unexpected transitions %v", states)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewBreaker(2, time.Minute, nil)
+	b.Failure()
+	b.Success()
+	b.Failure()
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+}
+
+func TestBreakerHalfOpenTrial(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, time.Second, nil)
+	b.now = func() time.Time { return now }
+
+	b.Failure()
+	if b.Allow() {
+		t.Fatal("expected open breaker to reject calls")
+	}
+
+	now = now.Add(time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half_open after the timeout, got %s", b.State())
+	}
+	if !b.Allow() {
+		t.Fatal("expected a trial call to be allowed")
+	}
+	if b.Allow() {
+		t.Fatal("expected only one trial call")
+	}
+
+	// A failed trial reopens the breaker
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after a failed trial, got %s", b.State())
+	}
+
+	now = now.Add(time.Second)
+	if !b.Allow() {
+		t.Fatal("expected a second trial call")
+	}
+	b.Success()
+	if b.State() != StateClosed || !b.Allow() {
+		t.Fatalf("expected closed after a successful trial, got %s", b.State())
+	}
+}
+
+func TestBreakerCancelReleasesTrial(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(1, time.Second, nil)
+	b.now = func() time.Time { return now }
+	b.Failure()
+	now = now.Add(time.Second)
+
+	b.Allow()
+	b.Cancel()
+	if !b.Allow() {
+		t.Fatal("expected a new trial after a cancelled one")
+	}
+}
+
+func TestBreakerNil(t *testing.T) {
+	var b *Breaker
+	if NewBreaker(0, time.Second, nil) != nil {
+		t.Fatal("expected nil breaker for threshold 0")
+	}
+	b.Failure()
+	if !b.Allow() || b.State() != StateClosed {
+		t.Fatal("expected nil breaker to stay closed")
+	}
+}
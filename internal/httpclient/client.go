@@ -0,0 +1,244 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"twitterx-api/internal/apperror"
+)
+
+// RetryPolicy controls how failed idempotent requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and any Retry-After value
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries twice with a short jittered backoff
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// backoff returns the delay before retry number attempt (starting at 1),
+// using exponential backoff with full jitter
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = max(p.MaxDelay, p.BaseDelay)
+	}
+	return rand.N(ceiling) + 1
+}
+
+// retryableError marks an error returned by a response handler as transient
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Retryable marks err as transient, so the request is retried
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable reports whether err was marked with Retryable
+func IsRetryable(err error) bool {
+	var r *retryableError
+	return errors.As(err, &r)
+}
+
+// Client performs GET requests with retries and a circuit breaker.
+// The zero RetryPolicy makes a single attempt; a nil Breaker never opens.
+type Client struct {
+	HTTP    *http.Client
+	Retry   RetryPolicy
+	Breaker *Breaker
+	// OnRetry, if set, is called before every retry
+	OnRetry func(attempt int, delay time.Duration, cause error)
+}
+
+// Get fetches url and passes the response to handle. Network errors, 5xx
+// and 429 responses, and handler errors marked with Retryable are retried
+// with backoff, honoring Retry-After. When no retry is left, handle sees the
+// failed response so the caller can build its own error. The response body
+// is closed after handle returns. While the breaker is open, Get fails with
+// apperror.ErrCircuitOpen without calling the upstream. The breaker sees one
+// outcome per call, that of its last attempt.
+func (c *Client) Get(ctx context.Context, url string, handle func(*http.Response) error) error {
+	if !c.Breaker.Allow() {
+		return apperror.ErrCircuitOpen
+	}
+	for attempt := 1; ; attempt++ {
+		delay, out, err := c.attempt(ctx, url, handle, attempt)
+		switch out {
+		case succeeded:
+			c.Breaker.Success()
+			return unwrapRetryable(err)
+		case failed:
+			c.Breaker.Failure()
+			return unwrapRetryable(err)
+		case cancelled:
+			c.Breaker.Cancel()
+			return unwrapRetryable(err)
+		}
+
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.Int64("delay_ms", delay.Milliseconds()),
+			attribute.String("cause", err.Error()),
+		))
+		if c.OnRetry != nil {
+			c.OnRetry(attempt+1, delay, err)
+		}
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			c.Breaker.Cancel()
+			return unwrapRetryable(err)
+		}
+	}
+}
+
+func unwrapRetryable(err error) error {
+	var r *retryableError
+	if errors.As(err, &r) {
+		return r.err
+	}
+	return err
+}
+
+// outcome is what an attempt means for the breaker
+type outcome int
+
+const (
+	// succeeded means the upstream answered, even with a client error
+	succeeded outcome = iota
+	// failed means the upstream failed and no retry is left
+	failed
+	// cancelled means the caller gave up, which says nothing about the upstream
+	cancelled
+	// retry means the upstream failed and the request is tried again
+	retry
+)
+
+// attempt performs a single request and reports its outcome, and the delay
+// before the next attempt when it should be retried
+func (c *Client) attempt(ctx context.Context, url string, handle func(*http.Response) error, attempt int) (time.Duration, outcome, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, cancelled, err
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("http.request.method", http.MethodGet),
+		attribute.String("url.full", url),
+	)
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, cancelled, err
+		}
+		delay, out := c.nextDelay(ctx, attempt, 0)
+		return delay, out, err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if retryableStatus(resp.StatusCode) {
+		if delay, out := c.nextDelay(ctx, attempt, retryAfter); out == retry {
+			// Drain so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			return delay, retry, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+		}
+		return 0, failed, handle(resp)
+	}
+
+	err = handle(resp)
+	if !IsRetryable(err) {
+		return 0, succeeded, err
+	}
+	delay, out := c.nextDelay(ctx, attempt, retryAfter)
+	return delay, out, err
+}
+
+// nextDelay returns the wait before the next attempt after a failed one, or
+// failed when the attempts are used up or the wait would not fit the context
+// deadline
+func (c *Client) nextDelay(ctx context.Context, attempt int, retryAfter time.Duration) (time.Duration, outcome) {
+	if attempt >= c.Retry.MaxAttempts {
+		return 0, failed
+	}
+	delay := c.Retry.backoff(attempt)
+	if retryAfter > 0 {
+		if c.Retry.MaxDelay > 0 && retryAfter > c.Retry.MaxDelay {
+			return 0, failed
+		}
+		delay = retryAfter
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, failed
+	}
+	return delay, retry
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,230 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func readBody(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	_, err := io.ReadAll(resp.Body)
+	return err
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var retries int
+	c := &Client{HTTP: server.Client(), Retry: testPolicy, OnRetry: func(int, time.Duration, error) { retries++ }}
+	if err := c.Get(context.Background(), server.URL, readBody); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 3 || retries != 2 {
+		t.Fatalf("expected 3 calls and 2 retries, got %d and %d", calls.Load(), retries)
+	}
+}
+
+func TestClientPassesLastFailureToHandler(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := &Client{HTTP: server.Client(), Retry: testPolicy}
+	var status int
+	err := c.Get(context.Background(), server.URL, func(resp *http.Response) error {
+		status = resp.StatusCode
+		return errors.New("unavailable")
+	})
+	if err == nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("expected the handler to see the 503, got status %d err %v", status, err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c := &Client{HTTP: server.Client(), Retry: testPolicy}
+	if err := c.Get(context.Background(), server.URL, readBody); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single call, got %d", calls.Load())
+	}
+}
+
+func TestClientRetriesMarkedErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	sentinel := errors.New("api fail")
+	c := &Client{HTTP: server.Client(), Retry: testPolicy}
+	err := c.Get(context.Background(), server.URL, func(*http.Response) error {
+		return Retryable(sentinel)
+	})
+	if err != sentinel {
+		t.Fatalf("expected the unwrapped handler error, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestClientRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var retries int
+	c := &Client{Retry: testPolicy, OnRetry: func(int, time.Duration, error) { retries++ }}
+	if err := c.Get(context.Background(), url, readBody); err == nil {
+		t.Fatal("expected a network error")
+	}
+	if retries != 2 {
+		t.Fatalf("expected 2 retries, got %d", retries)
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+	c := &Client{HTTP: server.Client(), Retry: policy, OnRetry: func(_ int, d time.Duration, _ error) { delays = append(delays, d) }}
+	if err := c.Get(context.Background(), server.URL, readBody); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(delays) != 1 || delays[0] != time.Second {
+		t.Fatalf("expected a single 1s delay, got %v", delays)
+	}
+}
+
+func TestClientGivesUpWhenRetryAfterTooLong(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := &Client{HTTP: server.Client(), Retry: testPolicy}
+	if err := c.Get(context.Background(), server.URL, readBody); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single call, got %d", calls.Load())
+	}
+}
+
+func TestClientStopsAtDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second}
+	c := &Client{HTTP: server.Client(), Retry: policy}
+
+	start := time.Now()
+	if err := c.Get(ctx, server.URL, readBody); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Fatalf("expected to give up instead of sleeping past the deadline, took %s", elapsed)
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c := &Client{HTTP: server.Client(), Retry: testPolicy, Breaker: NewBreaker(2, time.Minute, nil)}
+	for _, want := range []string{StateClosed, StateOpen} {
+		if err := c.Get(context.Background(), server.URL, readBody); err == nil {
+			t.Fatal("expected an error")
+		}
+		if state := c.Breaker.State(); state != want {
+			t.Fatalf("expected the retries of a call to count as one failure, got %s", state)
+		}
+	}
+	if calls.Load() != 6 {
+		t.Fatalf("expected 2 calls of 3 attempts, got %d attempts", calls.Load())
+	}
+
+	err := c.Get(context.Background(), server.URL, readBody)
+	if !errors.Is(err, apperror.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 6 {
+		t.Fatalf("expected no call while open, got %d attempts", calls.Load())
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 1; attempt <= 40; attempt++ {
+		d := p.backoff(attempt)
+		if d <= 0 || d > p.MaxDelay {
+			t.Fatalf("attempt %d: backoff %s outside (0, %s]", attempt, d, p.MaxDelay)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3"); d != 3*time.Second {
+		t.Fatalf("expected 3s, got %s", d)
+	}
+	if d := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); d < 59*time.Minute {
+		t.Fatalf("expected about an hour, got %s", d)
+	}
+	if d := parseRetryAfter("soon"); d != 0 {
+		t.Fatalf("expected 0 for an invalid value, got %s", d)
+	}
+}
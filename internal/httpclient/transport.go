@@ -0,0 +1,214 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"twitterx-api/internal/apperror"
+)
+
+// DefaultUserAgent identifies the service to upstreams
+const DefaultUserAgent = "twitterx-api (+https://github.com/Programistich/twitterx-api)"
+
+// TransportConfig configures the transport shared by all upstream clients.
+// Zero values fall back to sensible defaults.
+type TransportConfig struct {
+	// Proxy is the URL of an egress proxy (http, https, socks5 or socks5h).
+	// When empty, HTTP_PROXY, HTTPS_PROXY and NO_PROXY are honored.
+	Proxy string
+	// UserAgent is sent with every request that doesn't set its own
+	UserAgent string
+	// MaxIdleConns and MaxIdleConnsPerHost size the keep-alive pool
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an unused connection is kept open
+	IdleConnTimeout time.Duration
+	// Compression requests gzip or brotli encoded responses and decodes them
+	Compression bool
+	// MaxResponseSize caps the decoded size of a response body in bytes
+	// (0 = unlimited). Reading past it fails with apperror.ErrResponseTooLarge.
+	MaxResponseSize int64
+}
+
+// NewTransport creates an HTTP/2 capable transport with connection pooling
+func NewTransport(cfg TransportConfig) (http.RoundTripper, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := parseProxy(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = 100
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = 16
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = 90 * time.Second
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	base := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		// Encoding is negotiated by transport below, so the size cap
+		// applies to the decoded body
+		DisableCompression: true,
+	}
+	return &transport{base: base, cfg: cfg}, nil
+}
+
+// parseProxy validates a proxy URL
+func parseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// transport adds the User-Agent, decodes compressed responses and enforces
+// the response size cap
+type transport struct {
+	base *http.Transport
+	cfg  TransportConfig
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	negotiate := t.cfg.Compression && req.Header.Get("Accept-Encoding") == "" && req.Method != http.MethodHead
+	if req.Header.Get("User-Agent") == "" || negotiate {
+		req = req.Clone(req.Context())
+		if req.Header.Get("User-Agent") == "" {
+			req.Header.Set("User-Agent", t.cfg.UserAgent)
+		}
+		if negotiate {
+			req.Header.Set("Accept-Encoding", "gzip, br")
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if negotiate {
+		if err := decode(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if t.cfg.MaxResponseSize > 0 {
+		resp.Body = &limitedBody{
+			body:     resp.Body,
+			limit:    t.cfg.MaxResponseSize,
+			tooLarge: resp.ContentLength > t.cfg.MaxResponseSize,
+		}
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the pooled connections that are not in use
+func (t *transport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+}
+
+// decode replaces a gzip or brotli encoded body with its decoded stream
+func decode(resp *http.Response) error {
+	var body io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return nil
+	case "gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("invalid gzip response: %w", err)
+		}
+		body = zr
+	case "br":
+		body = brotli.NewReader(resp.Body)
+	default:
+		// Unknown encodings are passed through untouched
+		return nil
+	}
+
+	resp.Body = &decodedBody{Reader: body, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody reads the decoded stream and closes the raw body
+type decodedBody struct {
+	io.Reader
+	raw io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	return b.raw.Close()
+}
+
+// limitedBody fails with apperror.ErrResponseTooLarge once more than limit
+// bytes were read
+type limitedBody struct {
+	body     io.ReadCloser
+	limit    int64
+	read     int64
+	tooLarge bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.tooLarge {
+		return 0, tooLargeError(b.limit)
+	}
+	// Read one byte past the limit to tell an exact fit from an overflow
+	if remaining := b.limit + 1 - b.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		b.tooLarge = true
+		return n - int(b.read-b.limit), tooLargeError(b.limit)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+func tooLargeError(limit int64) error {
+	return fmt.Errorf("%w: more than %d bytes", apperror.ErrResponseTooLarge, limit)
+}
@@ -1,47 +1,223 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
-var debugEnabled bool
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config holds logger settings
+type Config struct {
+	Level  string
+	Format string
+	Output io.Writer
+}
+
+var (
+	level = new(slog.LevelVar)
+
+	mu   sync.RWMutex
+	base *slog.Logger
+)
 
 func init() {
-	debugEnabled = os.Getenv("DEBUG") != ""
+	cfg := Config{
+		Level:  os.Getenv("LOG_LEVEL"),
+		Format: os.Getenv("LOG_FORMAT"),
+	}
+	// DEBUG is kept for backwards compatibility with existing deployments
+	if cfg.Level == "" && os.Getenv("DEBUG") != "" {
+		cfg.Level = "debug"
+	}
+	if err := Configure(cfg); err != nil {
+		_ = Configure(Config{})
+		Error("Invalid logger configuration: %v", err)
+	}
 }
 
-func formatMessage(level, format string, args ...interface{}) string {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	msg := fmt.Sprintf(format, args...)
-	return fmt.Sprintf("[%s] [%s] %s", timestamp, level, msg)
+// Configure rebuilds the logger with the given settings
+func Configure(cfg Config) error {
+	lvl := slog.LevelInfo
+	if cfg.Level != "" {
+		parsed, err := ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		lvl = parsed
+	}
+
+	out := cfg.Output
+	if out == nil {
+		out = os.Stderr
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(out, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	level.Set(lvl)
+	mu.Lock()
+	base = slog.New(&contextHandler{Handler: handler})
+	mu.Unlock()
+	return nil
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog level
+func ParseLevel(s string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return lvl, nil
+}
+
+// SetLevel changes the minimum level at runtime
+func SetLevel(s string) error {
+	lvl, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	level.Set(lvl)
+	return nil
+}
+
+// GetLevel returns the current minimum level
+func GetLevel() slog.Level {
+	return level.Level()
+}
+
+// Logger returns the underlying structured logger
+func Logger() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return base
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a context carrying additional log fields as key/value pairs.
+// Every log line written with that context includes them.
+func WithFields(ctx context.Context, args ...any) context.Context {
+	if len(args) == 0 {
+		return ctx
+	}
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+
+	existing := fieldsFromContext(ctx)
+	fields := make([]slog.Attr, 0, len(existing)+record.NumAttrs())
+	fields = append(fields, existing...)
+	record.Attrs(func(a slog.Attr) bool {
+		fields = append(fields, a)
+		return true
+	})
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+func fieldsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	return fields
+}
+
+// contextHandler adds fields stored in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if fields := fieldsFromContext(ctx); len(fields) > 0 {
+		r.AddAttrs(fields...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func logf(ctx context.Context, lvl slog.Level, format string, args ...interface{}) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l := Logger()
+	if !l.Enabled(ctx, lvl) {
+		return
+	}
+	l.Log(ctx, lvl, fmt.Sprintf(format, args...))
 }
 
 // Info logs informational messages (always enabled)
 func Info(format string, args ...interface{}) {
-	log.Println(formatMessage("INFO", format, args...))
+	logf(context.Background(), slog.LevelInfo, format, args...)
 }
 
-// Debug logs debug messages (only when DEBUG env is set)
+// Debug logs debug messages (only when the level is debug)
 func Debug(format string, args ...interface{}) {
-	if debugEnabled {
-		log.Println(formatMessage("DEBUG", format, args...))
-	}
+	logf(context.Background(), slog.LevelDebug, format, args...)
+}
+
+// Warn logs warning messages
+func Warn(format string, args ...interface{}) {
+	logf(context.Background(), slog.LevelWarn, format, args...)
 }
 
 // Error logs error messages (always enabled)
 func Error(format string, args ...interface{}) {
-	log.Println(formatMessage("ERROR", format, args...))
+	logf(context.Background(), slog.LevelError, format, args...)
 }
 
 // Fatal logs error message and exits
 func Fatal(format string, args ...interface{}) {
-	log.Fatal(formatMessage("ERROR", format, args...))
+	logf(context.Background(), slog.LevelError, format, args...)
+	os.Exit(1)
+}
+
+// InfoContext logs an informational message with the fields stored in ctx
+func InfoContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelInfo, format, args...)
+}
+
+// DebugContext logs a debug message with the fields stored in ctx
+func DebugContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelDebug, format, args...)
+}
+
+// WarnContext logs a warning with the fields stored in ctx
+func WarnContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelWarn, format, args...)
+}
+
+// ErrorContext logs an error message with the fields stored in ctx
+func ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelError, format, args...)
 }
 
 // IsDebugEnabled returns whether debug logging is enabled
 func IsDebugEnabled() bool {
-	return debugEnabled
+	return level.Level() <= slog.LevelDebug
 }
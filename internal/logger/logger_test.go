@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConfigureJSONWithContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: "info", Format: FormatJSON, Output: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer Configure(Config{})
+
+	ctx := WithFields(context.Background(), "request_id", "abc")
+	ctx = WithFields(ctx, "upstream", "nitter")
+	InfoContext(ctx, "fetched %d tweets", 3)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "fetched 3 tweets" {
+		t.Fatalf("unexpected message: %v", entry["msg"])
+	}
+	if entry["request_id"] != "abc" || entry["upstream"] != "nitter" {
+		t.Fatalf("expected context fields, got %v", entry)
+	}
+}
+
+func TestSetLevelAtRuntime(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: "info", Output: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer Configure(Config{})
+
+	Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug to be filtered, got %q", buf.String())
+	}
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsDebugEnabled() {
+		t.Fatal("expected debug to be enabled")
+	}
+	Debug("visible")
+	if !strings.Contains(buf.String(), "visible") {
+		t.Fatalf("expected debug output, got %q", buf.String())
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+	if GetLevel() != slog.LevelDebug {
+		t.Fatalf("expected level to stay debug, got %v", GetLevel())
+	}
+}
+
+func TestConfigureInvalidFormat(t *testing.T) {
+	if err := Configure(Config{Format: "xml"}); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
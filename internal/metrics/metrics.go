@@ -0,0 +1,43 @@
+package metrics
+
+import "time"
+
+// Recorder is the instrumentation surface used by the handlers and services.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// ObserveRequest records a served HTTP request
+	ObserveRequest(route, method string, status int, duration time.Duration)
+	// AddInFlight adjusts the number of requests currently being served
+	AddInFlight(delta int)
+	// ObserveUpstream records a call to an upstream service. errorClass is
+	// empty for successful calls.
+	ObserveUpstream(service, errorClass string, duration time.Duration)
+	// ObserveCache records a cache lookup
+	ObserveCache(cache string, hit bool)
+	// SetInstanceHealth records whether an upstream instance is usable
+	SetInstanceHealth(service, instance string, healthy bool)
+	// ObserveRetry records a retried upstream call
+	ObserveRetry(service string)
+	// SetCircuitState records the circuit breaker state (closed, open,
+	// half_open) of an upstream instance
+	SetCircuitState(service, instance, state string)
+}
+
+// Nop is a Recorder that discards everything
+type Nop struct{}
+
+func (Nop) ObserveRequest(string, string, int, time.Duration) {}
+func (Nop) AddInFlight(int)                                   {}
+func (Nop) ObserveUpstream(string, string, time.Duration)     {}
+func (Nop) ObserveCache(string, bool)                         {}
+func (Nop) SetInstanceHealth(string, string, bool)            {}
+func (Nop) ObserveRetry(string)                               {}
+func (Nop) SetCircuitState(string, string, string)            {}
+
+// OrNop returns r, or Nop when r is nil
+func OrNop(r Recorder) Recorder {
+	if r == nil {
+		return Nop{}
+	}
+	return r
+}
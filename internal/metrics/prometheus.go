@@ -0,0 +1,306 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram buckets in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Registry is a Recorder that renders the Prometheus text exposition format
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+
+	requests         *counterVec
+	requestDuration  *histogramVec
+	inFlight         *gaugeVec
+	upstreamRequests *counterVec
+	upstreamDuration *histogramVec
+	upstreamErrors   *counterVec
+	cacheRequests    *counterVec
+	instanceUp       *gaugeVec
+	upstreamRetries  *counterVec
+	circuitState     *gaugeVec
+}
+
+// NewRegistry creates a registry with the service metrics registered
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.requests = r.counter("twitterx_http_requests_total",
+		"Total HTTP requests by route, method and status.", "route", "method", "status")
+	r.requestDuration = r.histogram("twitterx_http_request_duration_seconds",
+		"HTTP request latency by route, method and status.", DefaultBuckets, "route", "method", "status")
+	r.inFlight = r.gauge("twitterx_http_requests_in_flight",
+		"HTTP requests currently being served.")
+	r.upstreamRequests = r.counter("twitterx_upstream_requests_total",
+		"Calls to upstream services.", "service")
+	r.upstreamDuration = r.histogram("twitterx_upstream_request_duration_seconds",
+		"Upstream call latency.", DefaultBuckets, "service")
+	r.upstreamErrors = r.counter("twitterx_upstream_errors_total",
+		"Failed upstream calls by error class.", "service", "class")
+	r.cacheRequests = r.counter("twitterx_cache_requests_total",
+		"Cache lookups by result (hit or miss).", "cache", "result")
+	r.instanceUp = r.gauge("twitterx_upstream_instance_up",
+		"Whether an upstream instance is healthy (1) or not (0).", "service", "instance")
+	r.upstreamRetries = r.counter("twitterx_upstream_retries_total",
+		"Retried upstream calls.", "service")
+	r.circuitState = r.gauge("twitterx_upstream_circuit_state",
+		"Circuit breaker state of an upstream instance (1 for the current state).", "service", "instance", "state")
+	return r
+}
+
+func (r *Registry) ObserveRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	r.requests.add(1, route, method, code)
+	r.requestDuration.observe(duration.Seconds(), route, method, code)
+}
+
+func (r *Registry) AddInFlight(delta int) {
+	r.inFlight.add(float64(delta))
+}
+
+func (r *Registry) ObserveUpstream(service, errorClass string, duration time.Duration) {
+	r.upstreamRequests.add(1, service)
+	r.upstreamDuration.observe(duration.Seconds(), service)
+	if errorClass != "" {
+		r.upstreamErrors.add(1, service, errorClass)
+	}
+}
+
+func (r *Registry) ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	r.cacheRequests.add(1, cache, result)
+}
+
+func (r *Registry) SetInstanceHealth(service, instance string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	r.instanceUp.set(value, service, instance)
+}
+
+func (r *Registry) ObserveRetry(service string) {
+	r.upstreamRetries.add(1, service)
+}
+
+// circuitStates are the breaker states exported by SetCircuitState
+var circuitStates = []string{"closed", "open", "half_open"}
+
+func (r *Registry) SetCircuitState(service, instance, state string) {
+	for _, s := range circuitStates {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		r.circuitState.set(value, service, instance, s)
+	}
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := r.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteTo writes all metrics in the Prometheus text format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	r.mu.Lock()
+	for _, m := range r.metrics {
+		m.write(&buf)
+	}
+	r.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+type metric interface {
+	write(w *bytes.Buffer)
+}
+
+// series is the shared label bookkeeping of all vector types
+type series struct {
+	reg        *Registry
+	name, help string
+	kind       string
+	labels     []string
+}
+
+func (s *series) key(values []string) string {
+	if len(values) != len(s.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", s.name, len(s.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (s *series) header(w *bytes.Buffer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, s.kind)
+}
+
+func (s *series) labelString(key string, extra ...string) string {
+	var values []string
+	if len(s.labels) > 0 {
+		values = strings.Split(key, "\xff")
+	}
+	pairs := make([]string, 0, len(values)+1)
+	for i, name := range s.labels {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, extra[0]+`="`+extra[1]+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type counterVec struct {
+	series
+	values map[string]float64
+}
+
+func (r *Registry) counter(name, help string, labels ...string) *counterVec {
+	c := &counterVec{series: series{reg: r, name: name, help: help, kind: "counter", labels: labels}, values: map[string]float64{}}
+	r.metrics = append(r.metrics, c)
+	return c
+}
+
+func (c *counterVec) add(v float64, labels ...string) {
+	key := c.key(labels)
+	c.reg.mu.Lock()
+	c.values[key] += v
+	c.reg.mu.Unlock()
+}
+
+func (c *counterVec) write(w *bytes.Buffer) {
+	c.header(w)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelString(key), formatFloat(c.values[key]))
+	}
+}
+
+type gaugeVec struct {
+	series
+	values map[string]float64
+}
+
+func (r *Registry) gauge(name, help string, labels ...string) *gaugeVec {
+	g := &gaugeVec{series: series{reg: r, name: name, help: help, kind: "gauge", labels: labels}, values: map[string]float64{}}
+	if len(labels) == 0 {
+		g.values[""] = 0
+	}
+	r.metrics = append(r.metrics, g)
+	return g
+}
+
+func (g *gaugeVec) add(v float64, labels ...string) {
+	key := g.key(labels)
+	g.reg.mu.Lock()
+	g.values[key] += v
+	g.reg.mu.Unlock()
+}
+
+func (g *gaugeVec) set(v float64, labels ...string) {
+	key := g.key(labels)
+	g.reg.mu.Lock()
+	g.values[key] = v
+	g.reg.mu.Unlock()
+}
+
+func (g *gaugeVec) write(w *bytes.Buffer) {
+	g.header(w)
+	for _, key := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.labelString(key), formatFloat(g.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type histogramVec struct {
+	series
+	buckets []float64
+	values  map[string]*histogram
+}
+
+func (r *Registry) histogram(name, help string, buckets []float64, labels ...string) *histogramVec {
+	h := &histogramVec{
+		series:  series{reg: r, name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		values:  map[string]*histogram{},
+	}
+	r.metrics = append(r.metrics, h)
+	return h
+}
+
+func (h *histogramVec) observe(v float64, labels ...string) {
+	key := h.key(labels)
+	h.reg.mu.Lock()
+	defer h.reg.mu.Unlock()
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hist.counts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *histogramVec) write(w *bytes.Buffer) {
+	h.header(w)
+	for _, key := range sortedKeys(h.values) {
+		hist := h.values[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(key, "le", formatFloat(upper)), hist.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(key, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelString(key), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelString(key), hist.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryExposition(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveRequest("/api/users/{username}", "GET", 200, 30*time.Millisecond)
+	r.ObserveRequest("/api/users/{username}", "GET", 200, 2*time.Second)
+	r.AddInFlight(1)
+	r.ObserveUpstream("fxtwitter", "", 100*time.Millisecond)
+	r.ObserveUpstream("nitter", "timeout", time.Second)
+	r.ObserveCache("tweet", true)
+	r.ObserveCache("tweet", false)
+	r.SetInstanceHealth("nitter", `http://nitter:8049`, true)
+	r.ObserveRetry("fxtwitter")
+	r.SetCircuitState("fxtwitter", "fxtwitter", "open")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE twitterx_http_requests_total counter",
+		`twitterx_http_requests_total{route="/api/users/{username}",method="GET",status="200"} 2`,
+		`twitterx_http_request_duration_seconds_bucket{route="/api/users/{username}",method="GET",status="200",le="0.05"} 1`,
+		`twitterx_http_request_duration_seconds_bucket{route="/api/users/{username}",method="GET",status="200",le="+Inf"} 2`,
+		`twitterx_http_request_duration_seconds_count{route="/api/users/{username}",method="GET",status="200"} 2`,
+		"twitterx_http_requests_in_flight 1",
+		`twitterx_upstream_requests_total{service="nitter"} 1`,
+		`twitterx_upstream_errors_total{service="nitter",class="timeout"} 1`,
+		`twitterx_cache_requests_total{cache="tweet",result="hit"} 1`,
+		`twitterx_upstream_instance_up{service="nitter",instance="http://nitter:8049"} 1`,
+		`twitterx_upstream_retries_total{service="fxtwitter"} 1`,
+		`twitterx_upstream_circuit_state{service="fxtwitter",instance="fxtwitter",state="open"} 1`,
+		`twitterx_upstream_circuit_state{service="fxtwitter",instance="fxtwitter",state="closed"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected output to contain %q\n%s", want, body)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("unexpected escape: %s", got)
+	}
+}
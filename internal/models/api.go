@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TweetsResponse lists the recent tweet IDs of a user, newest first
+type TweetsResponse struct {
+	Username string   `json:"username"`
+	TweetIDs []string `json:"tweet_ids"`
+	// Tweets are the same tweets as published in the feed, before they are
+	// fetched in full
+	Tweets []TimelineTweet `json:"tweets"`
+}
+
+// TimelineTweet is a tweet as published in a Nitter feed
+type TimelineTweet struct {
+	ID string `json:"id"`
+	// Author is the screen name of the author, who differs from the owner of
+	// the timeline for retweets
+	Author      string    `json:"author"`
+	Text        string    `json:"text"`
+	Entities    *Entities `json:"entities"`
+	PublishedAt time.Time `json:"published_at"`
+	// RetweetedBy is the owner of the timeline when the tweet is a retweet
+	RetweetedBy string `json:"retweeted_by,omitempty"`
+	// Pinned marks the tweet pinned to the profile, which Nitter lists first
+	// whatever its age
+	Pinned bool `json:"pinned,omitempty"`
+}
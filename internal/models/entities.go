@@ -0,0 +1,42 @@
+package models
+
+// Entities are the hashtags, mentions, URLs and cashtags of a tweet text.
+// Offsets count Unicode code points; End is exclusive.
+type Entities struct {
+	Hashtags []Hashtag   `json:"hashtags"`
+	Mentions []Mention   `json:"mentions"`
+	URLs     []URLEntity `json:"urls"`
+	Cashtags []Cashtag   `json:"cashtags"`
+}
+
+// Hashtag is a #hashtag, Tag excludes the hash sign
+type Hashtag struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Tag   string `json:"tag"`
+}
+
+// Mention is an @mention of a user
+type Mention struct {
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	ScreenName string `json:"screen_name"`
+}
+
+// URLEntity is a link. URL is the text as written, ExpandedURL the target
+// when known (a t.co link resolved upstream) and DisplayURL the shortened
+// form shown in place of the link.
+type URLEntity struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	URL         string `json:"url"`
+	ExpandedURL string `json:"expanded_url"`
+	DisplayURL  string `json:"display_url"`
+}
+
+// Cashtag is a $cashtag, Tag excludes the dollar sign
+type Cashtag struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Tag   string `json:"tag"`
+}
@@ -65,6 +65,7 @@ type Tweet struct {
 	Poll              *Poll        `json:"poll,omitempty"`
 	Quote             *Tweet       `json:"quote,omitempty"`
 	Translation       *Translation `json:"translation,omitempty"`
+	Entities          *Entities    `json:"entities,omitempty"`
 }
 
 // Author represents the tweet author information
@@ -193,6 +194,9 @@ type User struct {
 	Protected    bool          `json:"protected"`
 	Website      *string       `json:"website"`
 	Verification *Verification `json:"verification,omitempty"`
+	// PinnedTweetID is the tweet pinned to the profile. FxTwitter doesn't
+	// return it; it is taken from the Nitter feed when available.
+	PinnedTweetID string `json:"pinned_tweet_id,omitempty"`
 }
 
 // Verification represents user verification information
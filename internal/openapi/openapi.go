@@ -0,0 +1,40 @@
+// Package openapi serves the OpenAPI description of the HTTP API and an
+// interactive documentation page.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"strconv"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec returns the OpenAPI 3.1 document
+func Spec() []byte {
+	return spec
+}
+
+// Handler serves the OpenAPI document
+func Handler() http.Handler {
+	return staticHandler("application/json", spec)
+}
+
+// DocsHandler serves the interactive documentation page. It loads the
+// document from /api/openapi.json.
+func DocsHandler() http.Handler {
+	return staticHandler("text/html; charset=utf-8", docsPage)
+}
+
+func staticHandler(contentType string, body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(body)
+	})
+}
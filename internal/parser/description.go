@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Link is an anchor of a tweet text
+type Link struct {
+	// Start and End are the byte offsets of the anchor text
+	Start, End int
+	Href       string
+}
+
+// ParseDescription returns the tweet text of the HTML description of an RSS
+// item and the links in it. Nitter puts the text in the first paragraph,
+// followed by media and quotes; line breaks become newlines.
+func ParseDescription(description string) (string, []Link) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(description), body)
+	if err != nil {
+		return "", nil
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	root := body
+	if p := findElement(body, atom.P); p != nil {
+		root = p
+	}
+
+	var d descriptionText
+	d.walk(root)
+	text := strings.TrimRightFunc(d.b.String(), isSpace)
+	trimmed := strings.TrimLeftFunc(text, isSpace)
+	shift := len(text) - len(trimmed)
+	links := d.links[:0]
+	for _, l := range d.links {
+		l.Start, l.End = l.Start-shift, min(l.End-shift, len(trimmed))
+		if l.Start >= 0 && l.Start < l.End {
+			links = append(links, l)
+		}
+	}
+	return trimmed, links
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\n' || r == '\t' || r == '\r'
+}
+
+// findElement returns the first element a below n, depth first
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// descriptionText accumulates the text of an HTML tree
+type descriptionText struct {
+	b     strings.Builder
+	links []Link
+	// afterBreak drops the newline that commonly follows a <br>
+	afterBreak bool
+}
+
+func (d *descriptionText) walk(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			text := c.Data
+			if d.afterBreak {
+				text = strings.TrimPrefix(text, "\n")
+			}
+			d.b.WriteString(text)
+			d.afterBreak = false
+		case c.Type != html.ElementNode:
+		case c.DataAtom == atom.Br:
+			d.b.WriteByte('\n')
+			d.afterBreak = true
+		case c.DataAtom == atom.Img, c.DataAtom == atom.Video, c.DataAtom == atom.Script, c.DataAtom == atom.Style:
+		case c.DataAtom == atom.A:
+			start := d.b.Len()
+			d.walk(c)
+			if href := attr(c, "href"); href != "" && d.b.Len() > start {
+				d.links = append(d.links, Link{Start: start, End: d.b.Len(), Href: href})
+			}
+		default:
+			d.walk(c)
+		}
+	}
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
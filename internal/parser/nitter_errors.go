@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Kinds of Nitter error pages recognized by DetectNitterError
+const (
+	NitterErrorRateLimited = "rate_limited"
+	NitterErrorNotFound    = "not_found"
+	NitterErrorSuspended   = "suspended"
+)
+
+var (
+	// Shown when every session of the instance is rate limited or no session is left
+	nitterRateLimitedRe = regexp.MustCompile(`(?i)(instance has been rate limited|no (auth tokens|sessions) (are )?available|rate limit exceeded)`)
+	nitterNotFoundRe    = regexp.MustCompile(`(?i)user &quot;[^&]*&quot; not found|user "[^"]*" not found`)
+	nitterSuspendedRe   = regexp.MustCompile(`(?i)has been suspended`)
+)
+
+// DetectNitterError recognizes Nitter HTML error pages and returns the kind
+// of error, or an empty string when body is not a known error page
+func DetectNitterError(body []byte) string {
+	// Error pages are HTML; a valid RSS document is never classified
+	if bytes.Contains(body, []byte("<rss")) {
+		return ""
+	}
+	switch {
+	case nitterRateLimitedRe.Match(body):
+		return NitterErrorRateLimited
+	case nitterSuspendedRe.Match(body):
+		return NitterErrorSuspended
+	case nitterNotFoundRe.Match(body):
+		return NitterErrorNotFound
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,21 @@
+package parser
+
+import "testing"
+
+func TestDetectNitterError(t *testing.T) {
+	cases := []struct {
+		body string
+		want string
+	}{
+		{`<div class="error-panel"><span>Instance has been rate limited.<br>Use another instance or try again later.</span></div>`, NitterErrorRateLimited},
+		{`<div class="error-panel"><span>User &quot;nobody&quot; not found</span></div>`, NitterErrorNotFound},
+		{`<div class="error-panel"><span>User "bad" has been suspended</span></div>`, NitterErrorSuspended},
+		{`<rss><channel><title>rate limited</title></channel></rss>`, ""},
+		{`<html><body>hello</body></html>`, ""},
+	}
+	for _, tc := range cases {
+		if got := DetectNitterError([]byte(tc.body)); got != tc.want {
+			t.Errorf("DetectNitterError(%q) = %q, want %q", tc.body, got, tc.want)
+		}
+	}
+}
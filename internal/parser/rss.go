@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 )
 
 // RSS represents the root RSS structure
@@ -40,6 +42,14 @@ func ParseRSS(data []byte) (*RSS, error) {
 	return &rss, nil
 }
 
+// Regexes to extract the tweet ID from a GUID, which is either:
+// - a plain numeric ID: "2006027578998472912"
+// - a URL: "/status/1982148508187500913#m"
+var (
+	statusRe = regexp.MustCompile(`/status/(\d+)`)
+	plainRe  = regexp.MustCompile(`^(\d+)$`)
+)
+
 // ExtractTweetIDs extracts tweet IDs from RSS items
 // GUID can be either a plain ID (e.g., "2006027578998472912") or a URL with /status/ path
 func ExtractTweetIDs(rss *RSS) ([]string, error) {
@@ -48,22 +58,77 @@ func ExtractTweetIDs(rss *RSS) ([]string, error) {
 	}
 
 	tweetIDs := []string{}
-	// Regex to extract tweet ID from GUID
-	// Supports both formats:
-	// - Plain numeric ID: "2006027578998472912"
-	// - URL format: "/status/1982148508187500913#m"
-	statusRe := regexp.MustCompile(`/status/(\d+)`)
-	plainRe := regexp.MustCompile(`^(\d+)$`)
-
 	for _, item := range rss.Channel.Items {
-		// Try URL format first
-		if matches := statusRe.FindStringSubmatch(item.GUID); len(matches) >= 2 {
-			tweetIDs = append(tweetIDs, matches[1])
-		} else if matches := plainRe.FindStringSubmatch(item.GUID); len(matches) >= 2 {
-			// Fall back to plain numeric ID
-			tweetIDs = append(tweetIDs, matches[1])
+		if id, ok := item.TweetID(); ok {
+			tweetIDs = append(tweetIDs, id)
 		}
 	}
 
 	return tweetIDs, nil
 }
+
+// TweetID returns the ID of the tweet of the item, taken from its GUID
+func (item Item) TweetID() (string, bool) {
+	// Try URL format first
+	if matches := statusRe.FindStringSubmatch(item.GUID); len(matches) >= 2 {
+		return matches[1], true
+	}
+	// Fall back to plain numeric ID
+	if matches := plainRe.FindStringSubmatch(item.GUID); len(matches) >= 2 {
+		return matches[1], true
+	}
+	return "", false
+}
+
+// Nitter prefixes the titles of pinned tweets and retweets
+const (
+	pinnedPrefix  = "Pinned: "
+	retweetPrefix = "RT by @"
+)
+
+// Pinned reports whether the item is the tweet pinned to the profile
+func (item Item) Pinned() bool {
+	return strings.HasPrefix(item.Title, pinnedPrefix)
+}
+
+// RetweetedBy returns the screen name of the owner of the feed when the item
+// is a retweet, or an empty string
+func (item Item) RetweetedBy() string {
+	rest, ok := strings.CutPrefix(item.Title, retweetPrefix)
+	if !ok {
+		return ""
+	}
+	name, _, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// pubDateLayouts are the date formats seen in RSS pubDate fields
+var pubDateLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC822Z, time.RFC822}
+
+// ParsePubDate parses an RSS pubDate value
+func ParsePubDate(value string) (time.Time, error) {
+	for _, layout := range pubDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid pubDate %q", value)
+}
+
+// LatestPubDate returns the newest pubDate of the RSS items, or the zero
+// time when none can be parsed
+func LatestPubDate(rss *RSS) time.Time {
+	var latest time.Time
+	if rss == nil {
+		return latest
+	}
+	for _, item := range rss.Channel.Items {
+		if t, err := ParsePubDate(item.PubDate); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
@@ -1,6 +1,9 @@
 package parser
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 const sampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
 <rss version="2.0">
@@ -56,3 +59,58 @@ func TestExtractTweetIDsNilRSS(t *testing.T) {
 		t.Fatal("expected error for nil rss, got nil")
 	}
 }
+
+func TestLatestPubDate(t *testing.T) {
+	rss := &RSS{Channel: Channel{Items: []Item{
+		{PubDate: "Mon, 06 Oct 2025 10:00:00 GMT"},
+		{PubDate: "Wed, 08 Oct 2025 09:30:00 +0000"},
+		{PubDate: "yesterday"},
+		{PubDate: "Tue, 07 Oct 2025 23:59:59 GMT"},
+	}}}
+
+	latest := LatestPubDate(rss)
+	want := time.Date(2025, time.October, 8, 9, 30, 0, 0, time.UTC)
+	if !latest.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, latest)
+	}
+
+	if !LatestPubDate(&RSS{}).IsZero() || !LatestPubDate(nil).IsZero() {
+		t.Fatal("expected zero time without items")
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	description := `<p>Hello <a href="https://nitter.net/search?q=%23golang">#golang</a> &amp; <a href="https://nitter.net/rob">@rob</a><br>
+see <a href="https://go.dev/blog/go1.24">go.dev/blog/go1.24</a></p>
+<img src="https://nitter.net/pic/media.jpg" />`
+
+	text, links := ParseDescription(description)
+	if text != "Hello #golang & @rob\nsee go.dev/blog/go1.24" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	if len(links) != 3 {
+		t.Fatalf("expected 3 links, got %+v", links)
+	}
+	if l := links[2]; text[l.Start:l.End] != "go.dev/blog/go1.24" || l.Href != "https://go.dev/blog/go1.24" {
+		t.Fatalf("unexpected link %+v", l)
+	}
+}
+
+func TestItemTitleMarkers(t *testing.T) {
+	tests := []struct {
+		title       string
+		pinned      bool
+		retweetedBy string
+	}{
+		{"Pinned: hello", true, ""},
+		{"RT by @jack: hello", false, "jack"},
+		{"R to @jack: hello", false, ""},
+		{"RT by @jack without colon", false, ""},
+	}
+	for _, tt := range tests {
+		item := Item{Title: tt.title}
+		if item.Pinned() != tt.pinned || item.RetweetedBy() != tt.retweetedBy {
+			t.Errorf("%q: got pinned %v, retweeted by %q", tt.title, item.Pinned(), item.RetweetedBy())
+		}
+	}
+}
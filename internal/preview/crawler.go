@@ -0,0 +1,43 @@
+package preview
+
+import "strings"
+
+// crawlerAgents are User-Agent fragments of link unfurlers and search
+// engines, lowercased
+var crawlerAgents = []string{
+	"slackbot",
+	"slack-imgproxy",
+	"twitterbot",
+	"facebookexternalhit",
+	"facebot",
+	"linkedinbot",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview",
+	"microsoftpreview",
+	"mattermost",
+	"zulip",
+	"redditbot",
+	"pinterest",
+	"vkshare",
+	"embedly",
+	"iframely",
+	"mastodon",
+	"bluesky",
+	"applebot",
+	"googlebot",
+	"bingbot",
+}
+
+// IsCrawler reports whether userAgent belongs to a link unfurler or search
+// engine, which reads meta tags instead of running scripts
+func IsCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, agent := range crawlerAgents {
+		if strings.Contains(userAgent, agent) {
+			return true
+		}
+	}
+	return false
+}
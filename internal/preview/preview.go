@@ -0,0 +1,142 @@
+// Package preview renders the OpenGraph and Twitter Card tags that link
+// unfurlers (Slack, Discord, iMessage, ...) read, for crawlers that don't
+// run the single-page UI.
+package preview
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"twitterx-api/internal/embed"
+	"twitterx-api/internal/models"
+)
+
+//go:embed page.html
+var pageTemplate string
+
+var page = template.Must(template.New("page").Parse(pageTemplate))
+
+// maxDescription bounds og:description in characters
+const maxDescription = 300
+
+// maxImages is how many photos of a tweet are listed
+const maxImages = 4
+
+// Page holds the metadata of a preview page
+type Page struct {
+	// URL is the canonical URL of the page
+	URL         string
+	Type        string
+	Title       string
+	Description string
+	// Card is the twitter:card type
+	Card   string
+	Images []Image
+	Video  *Video
+	// OEmbedURL advertises the oEmbed description of the page, if any
+	OEmbedURL string
+}
+
+// Image is an og:image
+type Image struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// Video is an og:video
+type Video struct {
+	URL    string
+	Type   string
+	Width  int
+	Height int
+}
+
+// UserPage describes the profile of user at pageURL
+func UserPage(user *models.User, pageURL string) *Page {
+	p := &Page{
+		URL:         pageURL,
+		Type:        "profile",
+		Title:       fmt.Sprintf("%s (@%s)", user.Name, user.ScreenName),
+		Description: truncate(user.Description),
+		Card:        "summary",
+	}
+	counts := fmt.Sprintf("%s followers · %s following · %s posts",
+		embed.FormatCount(user.Followers), embed.FormatCount(user.Following), embed.FormatCount(user.Tweets))
+	if p.Description == "" {
+		p.Description = counts
+	} else {
+		p.Description = truncate(p.Description + "\n\n" + counts)
+	}
+	if user.AvatarURL != "" {
+		p.Images = []Image{{URL: user.AvatarURL}}
+	}
+	return p
+}
+
+// TweetPage describes tweet at pageURL, with the oEmbed discovery link
+// oembedURL
+func TweetPage(tweet *models.Tweet, pageURL, oembedURL string) *Page {
+	p := &Page{
+		URL:         pageURL,
+		Type:        "article",
+		Title:       fmt.Sprintf("%s (@%s)", tweet.Author.Name, tweet.Author.ScreenName),
+		Description: truncate(tweetDescription(tweet)),
+		Card:        "summary",
+		OEmbedURL:   oembedURL,
+	}
+
+	if tweet.Media != nil {
+		for _, m := range tweet.Media.All {
+			switch {
+			case m.Type == "photo" && len(p.Images) < maxImages:
+				p.Images = append(p.Images, Image{URL: m.URL, Width: m.Width, Height: m.Height})
+			case m.Type != "photo" && p.Video == nil:
+				p.Video = &Video{URL: m.URL, Type: "video/mp4", Width: m.Width, Height: m.Height}
+				if m.ThumbnailURL != "" {
+					p.Images = append([]Image{{URL: m.ThumbnailURL, Width: m.Width, Height: m.Height}}, p.Images...)
+				}
+			}
+		}
+	}
+	if len(p.Images) > 0 {
+		p.Card = "summary_large_image"
+	} else if tweet.Author.AvatarURL != "" {
+		p.Images = []Image{{URL: tweet.Author.AvatarURL}}
+	}
+	return p
+}
+
+// tweetDescription is the text of tweet followed by its poll, its quote
+// and its counts
+func tweetDescription(t *models.Tweet) string {
+	var b strings.Builder
+	b.WriteString(t.Text)
+	if t.Poll != nil {
+		for _, c := range t.Poll.Choices {
+			fmt.Fprintf(&b, "\n📊 %s: %d%%", c.Label, c.Percentage)
+		}
+	}
+	if q := t.Quote; q != nil {
+		fmt.Fprintf(&b, "\n\n↪ Quoting %s (@%s): %s", q.Author.Name, q.Author.ScreenName, q.Text)
+	}
+	fmt.Fprintf(&b, "\n\n💬 %s  🔁 %s  ❤️ %s",
+		embed.FormatCount(t.Replies), embed.FormatCount(t.Retweets), embed.FormatCount(t.Likes))
+	return strings.TrimSpace(b.String())
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxDescription {
+		return s
+	}
+	return string([]rune(s)[:maxDescription-1]) + "…"
+}
+
+// Render writes p as an HTML page with its meta tags
+func Render(w io.Writer, p *Page) error {
+	return page.Execute(w, p)
+}
@@ -0,0 +1,79 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"twitterx-api/internal/models"
+)
+
+func TestIsCrawler(t *testing.T) {
+	for ua, want := range map[string]bool{
+		"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)":                true,
+		"Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)":         true,
+		"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)": true,
+		"TelegramBot (like TwitterBot)":                                             true,
+		"Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0":    false,
+		"": false,
+	} {
+		if got := IsCrawler(ua); got != want {
+			t.Errorf("IsCrawler(%q) = %v, want %v", ua, got, want)
+		}
+	}
+}
+
+func TestUserPage(t *testing.T) {
+	user := &models.User{ScreenName: "jack", Name: "jack", Description: "no state is the best state", Followers: 6_500_000, AvatarURL: "https://pbs.twimg.com/a.jpg"}
+	var b strings.Builder
+	if err := Render(&b, UserPage(user, "https://tx.example.com/jack")); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	page := b.String()
+	for _, want := range []string{
+		`<meta property="og:type" content="profile">`,
+		`<meta property="og:title" content="jack (@jack)">`,
+		`<meta property="og:url" content="https://tx.example.com/jack">`,
+		`<meta property="og:image" content="https://pbs.twimg.com/a.jpg">`,
+		`<meta name="twitter:card" content="summary">`,
+		`6.5M followers`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+}
+
+func TestTweetPage(t *testing.T) {
+	tweet := &models.Tweet{
+		ID:     "20",
+		Text:   `look "here" <script>`,
+		Author: models.Author{Name: "jack", ScreenName: "jack"},
+		Media: &models.Media{All: []models.MediaItem{
+			{Type: "video", URL: "https://video.twimg.com/v.mp4", ThumbnailURL: "https://pbs.twimg.com/t.jpg", Width: 1280, Height: 720},
+		}},
+		Poll: &models.Poll{Choices: []models.PollChoice{{Label: "yes", Percentage: 70}}},
+	}
+	var b strings.Builder
+	err := Render(&b, TweetPage(tweet, "https://tx.example.com/jack/status/20", "https://tx.example.com/api/oembed?url=x"))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	page := b.String()
+	for _, want := range []string{
+		`<meta property="og:type" content="article">`,
+		`<meta property="og:video" content="https://video.twimg.com/v.mp4">`,
+		`<meta property="og:video:width" content="1280">`,
+		`<meta property="og:image" content="https://pbs.twimg.com/t.jpg">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`look &#34;here&#34; &lt;script&gt;`,
+		`📊 yes: 70%`,
+		`type="application/json+oembed" href="https://tx.example.com/api/oembed?url=x"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("tweet text is not escaped")
+	}
+}
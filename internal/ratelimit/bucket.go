@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket refilled at a constant rate
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket creates a full bucket holding up to burst tokens, refilled with
+// rate tokens per second
+func NewBucket(rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// PerMinute creates a bucket allowing n requests per minute with bursts of up to n
+func PerMinute(n int) *Bucket {
+	return NewBucket(float64(n)/60, n)
+}
+
+// Allow takes a token if one is available. Otherwise it returns false and
+// how long to wait until the next token.
+func (b *Bucket) Allow(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if b.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// idle reports whether the bucket is full and was last used before cutoff
+func (b *Bucket) idle(now, cutoff time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	unused := b.last.Before(cutoff)
+	b.refill(now)
+	return unused && b.tokens >= b.burst
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		elapsed := now.Sub(b.last).Seconds()
+		if elapsed > 0 {
+			b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		}
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+}
+
+// Keyed holds one bucket per key, e.g. per client IP. Buckets left unused
+// for longer than the idle timeout are dropped.
+type Keyed struct {
+	mu        sync.Mutex
+	newBucket func() *Bucket
+	buckets   map[string]*Bucket
+	idleAfter time.Duration
+	lastSweep time.Time
+}
+
+// NewKeyed creates a keyed limiter whose buckets are built by newBucket
+func NewKeyed(newBucket func() *Bucket, idleAfter time.Duration) *Keyed {
+	return &Keyed{
+		newBucket: newBucket,
+		buckets:   make(map[string]*Bucket),
+		idleAfter: idleAfter,
+	}
+}
+
+// Allow takes a token from the bucket of key
+func (k *Keyed) Allow(key string, now time.Time) (bool, time.Duration) {
+	k.mu.Lock()
+	if k.idleAfter > 0 && now.Sub(k.lastSweep) >= k.idleAfter {
+		k.sweep(now)
+	}
+	b, ok := k.buckets[key]
+	if !ok {
+		b = k.newBucket()
+		k.buckets[key] = b
+	}
+	k.mu.Unlock()
+	return b.Allow(now)
+}
+
+// Len returns the number of tracked keys
+func (k *Keyed) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.buckets)
+}
+
+func (k *Keyed) sweep(now time.Time) {
+	cutoff := now.Add(-k.idleAfter)
+	for key, b := range k.buckets {
+		if b.idle(now, cutoff) {
+			delete(k.buckets, key)
+		}
+	}
+	k.lastSweep = now
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketAllow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBucket(1, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := b.Allow(now); !ok {
+			t.Fatalf("expected request %d to be allowed", i)
+		}
+	}
+	ok, wait := b.Allow(now)
+	if ok {
+		t.Fatal("expected bucket to be empty")
+	}
+	if wait != time.Second {
+		t.Fatalf("expected 1s wait, got %v", wait)
+	}
+
+	if ok, _ := b.Allow(now.Add(time.Second)); !ok {
+		t.Fatal("expected token after refill")
+	}
+}
+
+func TestKeyedSweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	k := NewKeyed(func() *Bucket { return PerMinute(60) }, time.Minute)
+
+	k.Allow("a", now)
+	k.Allow("b", now)
+	if k.Len() != 2 {
+		t.Fatalf("expected 2 buckets, got %d", k.Len())
+	}
+
+	now = now.Add(2 * time.Minute)
+	k.Allow("c", now)
+	if k.Len() != 1 {
+		t.Fatalf("expected idle buckets to be dropped, got %d", k.Len())
+	}
+}
+
+func TestKeyedIsolatesKeys(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	k := NewKeyed(func() *Bucket { return NewBucket(0, 1) }, 0)
+
+	if ok, _ := k.Allow("a", now); !ok {
+		t.Fatal("expected first request of a to be allowed")
+	}
+	if ok, _ := k.Allow("a", now); ok {
+		t.Fatal("expected second request of a to be limited")
+	}
+	if ok, _ := k.Allow("b", now); !ok {
+		t.Fatal("expected b to have its own bucket")
+	}
+}
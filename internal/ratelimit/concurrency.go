@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+// Concurrency bounds the number of simultaneous operations. Callers over the
+// limit wait in a queue for a free slot, up to a maximum wait time.
+// A nil *Concurrency imposes no limit.
+type Concurrency struct {
+	slots    chan struct{}
+	maxQueue int64
+	maxWait  time.Duration
+	waiting  atomic.Int64
+}
+
+// NewConcurrency allows limit concurrent operations with at most maxQueue
+// callers waiting for up to maxWait each. It returns nil when limit is not positive.
+func NewConcurrency(limit, maxQueue int, maxWait time.Duration) *Concurrency {
+	if limit <= 0 {
+		return nil
+	}
+	return &Concurrency{
+		slots:    make(chan struct{}, limit),
+		maxQueue: int64(maxQueue),
+		maxWait:  maxWait,
+	}
+}
+
+// Acquire takes a slot and returns the function releasing it. It fails with
+// a RateLimitError when the queue is full or no slot frees up in time, and
+// with the context error when ctx ends first.
+func (c *Concurrency) Acquire(ctx context.Context) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c.release, nil
+	default:
+	}
+
+	if c.maxQueue > 0 && c.waiting.Load() >= c.maxQueue {
+		return nil, &apperror.RateLimitError{Message: "too many queued upstream calls", RetryAfter: c.retryAfter()}
+	}
+	c.waiting.Add(1)
+	defer c.waiting.Add(-1)
+
+	var timeout <-chan time.Time
+	if c.maxWait > 0 {
+		timer := time.NewTimer(c.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c.release, nil
+	case <-timeout:
+		return nil, &apperror.RateLimitError{Message: "upstream concurrency limit reached", RetryAfter: c.retryAfter()}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// InUse returns the number of taken slots
+func (c *Concurrency) InUse() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.slots)
+}
+
+// Waiting returns the number of queued callers
+func (c *Concurrency) Waiting() int {
+	if c == nil {
+		return 0
+	}
+	return int(c.waiting.Load())
+}
+
+func (c *Concurrency) release() {
+	<-c.slots
+}
+
+func (c *Concurrency) retryAfter() time.Duration {
+	if c.maxWait > 0 {
+		return c.maxWait
+	}
+	return time.Second
+}
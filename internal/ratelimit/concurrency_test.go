@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+func TestConcurrencyQueuesUntilRelease(t *testing.T) {
+	c := NewConcurrency(1, 10, time.Second)
+
+	release, err := c.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		release, err := c.Acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if c.Waiting() != 1 {
+		t.Fatalf("expected 1 waiting caller, got %d", c.Waiting())
+	}
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("expected queued caller to get a slot, got %v", err)
+	}
+}
+
+func TestConcurrencyTimesOut(t *testing.T) {
+	c := NewConcurrency(1, 10, 20*time.Millisecond)
+	release, _ := c.Acquire(context.Background())
+	defer release()
+
+	_, err := c.Acquire(context.Background())
+	var rlErr *apperror.RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if rlErr.RetryAfter != 20*time.Millisecond {
+		t.Fatalf("unexpected Retry-After: %v", rlErr.RetryAfter)
+	}
+}
+
+func TestConcurrencyRejectsWhenQueueFull(t *testing.T) {
+	c := NewConcurrency(1, 1, time.Second)
+	release, _ := c.Acquire(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _, _ = c.Acquire(ctx) }()
+	defer cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := c.Acquire(context.Background())
+	var rlErr *apperror.RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("expected RateLimitError for full queue, got %v", err)
+	}
+}
+
+func TestConcurrencyHonorsContext(t *testing.T) {
+	c := NewConcurrency(1, 10, time.Second)
+	release, _ := c.Acquire(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestNilConcurrency(t *testing.T) {
+	var c *Concurrency
+	release, err := c.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+)
+
+// Middleware rejects requests with 429 once the bucket of their client is
+// empty. clientKey identifies the client of a request, e.g. by IP or API key.
+func Middleware(limiter *Keyed, clientKey func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := limiter.Allow(clientKey(r), time.Now()); !ok {
+				err := &apperror.RateLimitError{Message: "too many requests", RetryAfter: wait}
+				logger.WarnContext(r.Context(), "Rate limit: rejected request: %v", err)
+				apperror.WriteHTTPError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
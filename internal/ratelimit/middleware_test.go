@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareRejectsWithRetryAfter(t *testing.T) {
+	limiter := NewKeyed(func() *Bucket { return NewBucket(0.5, 1) }, 0)
+	handler := Middleware(limiter, func(r *http.Request) string { return r.Header.Get("X-Client") })(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	request := func(client string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/users/x", nil)
+		r.Header.Set("X-Client", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := request("a"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	rec := request("a")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After 2, got %q", got)
+	}
+	if rec := request("b"); rec.Code != http.StatusOK {
+		t.Fatalf("expected other client to pass, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	"twitterx-api/internal/models"
+)
+
+// HTML renders tweet as an HTML fragment: the text as a paragraph, then the
+// media and the quoted tweet in a blockquote. Everything taken from the
+// tweet is escaped and only http(s) URLs are linked or embedded.
+func HTML(tweet *models.Tweet) string {
+	var b strings.Builder
+	writeHTML(&b, tweet, true)
+	return b.String()
+}
+
+// TextHTML returns the escaped text of tweet with its entities as links.
+// Line breaks are kept.
+func TextHTML(tweet *models.Tweet) template.HTML {
+	var b strings.Builder
+	for _, s := range segments(tweet) {
+		if s.href == "" {
+			b.WriteString(escapeHTML(s.text))
+			continue
+		}
+		writeLink(&b, s.href, escapeHTML(s.text))
+	}
+	return template.HTML(b.String())
+}
+
+// writeHTML writes tweet, and its quote when top is set: quotes are shown
+// one level deep
+func writeHTML(b *strings.Builder, tweet *models.Tweet, top bool) {
+	b.WriteString("<p>")
+	b.WriteString(string(TextHTML(tweet)))
+	b.WriteString("</p>\n")
+
+	if tweet.Media != nil {
+		for _, item := range tweet.Media.All {
+			src := SafeURL(item.URL)
+			if src == "" {
+				continue
+			}
+			b.WriteString("<p>")
+			switch thumbnail := SafeURL(item.ThumbnailURL); {
+			case item.Type == "photo":
+				writeLink(b, src, `<img src="`+template.HTMLEscapeString(src)+`" alt="Photo">`)
+			case thumbnail != "":
+				writeLink(b, src, `<img src="`+template.HTMLEscapeString(thumbnail)+`" alt="Video">`)
+			default:
+				writeLink(b, src, "Video")
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+
+	if quote := tweet.Quote; quote != nil && top {
+		b.WriteString("<blockquote>\n<p>")
+		writeLink(b, "https://x.com/"+url.PathEscape(quote.Author.ScreenName),
+			escapeHTML(quote.Author.Name)+" @"+escapeHTML(quote.Author.ScreenName))
+		b.WriteString("</p>\n")
+		writeHTML(b, quote, false)
+		b.WriteString("</blockquote>\n")
+	}
+}
+
+// writeLink writes an anchor to href, which must be safe, around inner HTML
+func writeLink(b *strings.Builder, href, inner string) {
+	b.WriteString(`<a href="`)
+	b.WriteString(template.HTMLEscapeString(href))
+	b.WriteString(`" target="_blank" rel="noopener noreferrer nofollow">`)
+	b.WriteString(inner)
+	b.WriteString(`</a>`)
+}
+
+// escapeHTML escapes text for HTML and turns line breaks into <br>
+func escapeHTML(text string) string {
+	return strings.ReplaceAll(template.HTMLEscapeString(text), "\n", "<br>")
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"strings"
+
+	"twitterx-api/internal/models"
+)
+
+// Markdown renders tweet as CommonMark: the text with its entities as
+// links, then the media and the quoted tweet as a blockquote. Text is
+// escaped so that it never turns into markup, and line breaks are kept as
+// hard breaks.
+func Markdown(tweet *models.Tweet) string {
+	var b strings.Builder
+	writeMarkdown(&b, tweet, true)
+	return b.String()
+}
+
+// writeMarkdown writes tweet, and its quote when top is set
+func writeMarkdown(b *strings.Builder, tweet *models.Tweet, top bool) {
+	b.WriteString(markdownText(tweet))
+	b.WriteString("\n")
+
+	if tweet.Media != nil {
+		for _, item := range tweet.Media.All {
+			src := SafeURL(item.URL)
+			if src == "" {
+				continue
+			}
+			b.WriteString("\n")
+			switch thumbnail := SafeURL(item.ThumbnailURL); {
+			case item.Type == "photo":
+				b.WriteString("![Photo](" + destination(src) + ")")
+			case thumbnail != "":
+				b.WriteString("[![Video](" + destination(thumbnail) + ")](" + destination(src) + ")")
+			default:
+				b.WriteString("[Video](" + destination(src) + ")")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if quote := tweet.Quote; quote != nil && top {
+		var q strings.Builder
+		q.WriteString("**" + escapeMarkdown(quote.Author.Name, false) + "** ")
+		q.WriteString("[@" + escapeMarkdown(quote.Author.ScreenName, false) + "](https://x.com/" + destination(quote.Author.ScreenName) + ")\n\n")
+		writeMarkdown(&q, quote, false)
+
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSuffix(q.String(), "\n"), "\n") {
+			if line == "" {
+				b.WriteString(">\n")
+			} else {
+				b.WriteString("> " + line + "\n")
+			}
+		}
+	}
+}
+
+// markdownText returns the escaped text of tweet with its entities as links
+func markdownText(tweet *models.Tweet) string {
+	var b strings.Builder
+	for _, s := range segments(tweet) {
+		if s.href == "" {
+			lineStart := b.Len() == 0 || strings.HasSuffix(b.String(), "\n")
+			b.WriteString(escapeMarkdown(s.text, lineStart))
+			continue
+		}
+		b.WriteString("[" + escapeMarkdown(s.text, false) + "](" + destination(s.href) + ")")
+	}
+
+	// A line followed by another needs a hard break, blank lines end the
+	// paragraph
+	lines := strings.Split(b.String(), "\n")
+	for i := 0; i < len(lines)-1; i++ {
+		if strings.TrimSpace(lines[i]) != "" && strings.TrimSpace(lines[i+1]) != "" {
+			lines[i] += "\\"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownInline are the characters escaped everywhere in Markdown text
+const markdownInline = "\\`*_[]<>|~&"
+
+// escapeMarkdown backslash-escapes the characters of text that could start
+// Markdown syntax. At the start of a line, block markers like "#", "- " and
+// "1." are escaped too.
+func escapeMarkdown(text string, lineStart bool) string {
+	var b strings.Builder
+	atStart, inNumber := lineStart, false
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(markdownInline, r),
+			atStart && strings.ContainsRune("#>-+=", r),
+			inNumber && (r == '.' || r == ')'):
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+
+		switch {
+		case r == '\n':
+			atStart, inNumber = true, false
+		case atStart && (r == ' ' || r == '\t'):
+		case (atStart || inNumber) && r >= '0' && r <= '9':
+			atStart, inNumber = false, true
+		default:
+			atStart, inNumber = false, false
+		}
+	}
+	return b.String()
+}
+
+var destinationReplacer = strings.NewReplacer(" ", "%20", "<", "%3C", ">", "%3E", "(", "%28", ")", "%29")
+
+// destination percent-encodes the characters of u that would end a Markdown
+// link destination
+func destination(u string) string {
+	return destinationReplacer.Replace(u)
+}
@@ -0,0 +1,134 @@
+// Package render turns tweets into sanitized HTML, CommonMark and plain
+// text. Entities become links with their expanded URLs; HTML and Markdown
+// also show the media and the quoted tweet.
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/models"
+	"twitterx-api/internal/twittertext"
+)
+
+// Format is a text format tweets are rendered to
+type Format string
+
+// Supported formats
+const (
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// ParseFormat validates a format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatHTML, FormatMarkdown, FormatText:
+		return f, nil
+	case "md":
+		return FormatMarkdown, nil
+	case "plain":
+		return FormatText, nil
+	default:
+		return "", &apperror.ValidationError{Field: "format", Message: fmt.Sprintf("unsupported format %q", s)}
+	}
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Render renders tweet in format
+func Render(tweet *models.Tweet, format Format) string {
+	switch format {
+	case FormatHTML:
+		return HTML(tweet)
+	case FormatMarkdown:
+		return Markdown(tweet)
+	default:
+		return Text(tweet)
+	}
+}
+
+// segment is a part of a tweet text: plain text, or an entity with the URL
+// it links to
+type segment struct {
+	text string
+	// href is empty for plain text
+	href string
+	// expanded is set for URLs, which read as their target in plain text
+	expanded bool
+}
+
+// segments splits the text of tweet at its entities, extracting them when
+// the tweet has none
+func segments(tweet *models.Tweet) []segment {
+	entities := tweet.Entities
+	if entities == nil {
+		entities = twittertext.Extract(tweet.Text)
+	}
+
+	type link struct {
+		start, end int
+		href       string
+		display    string
+	}
+	var links []link
+	for _, u := range entities.URLs {
+		links = append(links, link{u.Start, u.End, u.ExpandedURL, u.DisplayURL})
+	}
+	for _, m := range entities.Mentions {
+		links = append(links, link{m.Start, m.End, "https://x.com/" + m.ScreenName, ""})
+	}
+	for _, h := range entities.Hashtags {
+		links = append(links, link{h.Start, h.End, "https://x.com/hashtag/" + url.PathEscape(h.Tag), ""})
+	}
+	for _, c := range entities.Cashtags {
+		links = append(links, link{c.Start, c.End, "https://x.com/search?q=" + url.QueryEscape("$"+c.Tag), ""})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].start < links[j].start })
+
+	runes := []rune(tweet.Text)
+	var segs []segment
+	last := 0
+	for _, l := range links {
+		href := SafeURL(l.href)
+		if l.start < last || l.end > len(runes) || href == "" {
+			continue
+		}
+		if l.start > last {
+			segs = append(segs, segment{text: string(runes[last:l.start])})
+		}
+		s := segment{text: string(runes[l.start:l.end]), href: href}
+		if l.display != "" {
+			s.text, s.expanded = l.display, true
+		}
+		segs = append(segs, s)
+		last = l.end
+	}
+	if last < len(runes) {
+		segs = append(segs, segment{text: string(runes[last:])})
+	}
+	return segs
+}
+
+// SafeURL returns u if it is an absolute http(s) URL, or an empty string
+func SafeURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return ""
+	}
+	return u
+}
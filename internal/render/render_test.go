@@ -0,0 +1,104 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"twitterx-api/internal/models"
+)
+
+func testTweet() *models.Tweet {
+	return &models.Tweet{
+		Text: "# Big news\n1. read go.dev/blog by @rob *now*\n\nhttps://t.co/x",
+		Entities: &models.Entities{
+			Hashtags: []models.Hashtag{},
+			Mentions: []models.Mention{{Start: 34, End: 38, ScreenName: "rob"}},
+			URLs: []models.URLEntity{
+				{Start: 19, End: 30, URL: "go.dev/blog", ExpandedURL: "https://go.dev/blog", DisplayURL: "go.dev/blog"},
+				{Start: 46, End: 60, URL: "https://t.co/x", ExpandedURL: "https://example.com/post", DisplayURL: "example.com/post"},
+			},
+			Cashtags: []models.Cashtag{},
+		},
+		Media: &models.Media{All: []models.MediaItem{
+			{Type: "photo", URL: "https://pbs.twimg.com/media/a.jpg"},
+			{Type: "video", URL: "javascript:alert(1)"},
+		}},
+		Quote: &models.Tweet{
+			Text:   "<b>quoted</b>",
+			Author: models.Author{Name: "Jack", ScreenName: "jack"},
+		},
+	}
+}
+
+func TestTextHTML(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"hi @jack!", `hi <a href="https://x.com/jack" target="_blank" rel="noopener noreferrer nofollow">@jack</a>!`},
+		{"#golang rocks", `<a href="https://x.com/hashtag/golang" target="_blank" rel="noopener noreferrer nofollow">#golang</a> rocks`},
+		{"buy $TSLA", `buy <a href="https://x.com/search?q=%24TSLA" target="_blank" rel="noopener noreferrer nofollow">$TSLA</a>`},
+		{"see https://example.com/a.", `see <a href="https://example.com/a" target="_blank" rel="noopener noreferrer nofollow">example.com/a</a>.`},
+		{"mail a@b.com, #1", "mail a@b.com, #1"},
+		{"🎉 #日本 hi", `🎉 <a href="https://x.com/hashtag/%E6%97%A5%E6%9C%AC" target="_blank" rel="noopener noreferrer nofollow">#日本</a> hi`},
+		{"<script>alert(1)</script>\nbye", "&lt;script&gt;alert(1)&lt;/script&gt;<br>bye"},
+		{`https://x.com/"onmouseover=alert(1)`, `<a href="https://x.com/" target="_blank" rel="noopener noreferrer nofollow">x.com/</a>&#34;onmouseover=alert(1)`},
+	}
+	for _, tt := range tests {
+		if got := string(TextHTML(&models.Tweet{Text: tt.text})); got != tt.want {
+			t.Errorf("TextHTML(%q)\n got %s\nwant %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := HTML(testTweet())
+	for _, want := range []string{
+		`<p># Big news<br>1. read <a href="https://go.dev/blog"`,
+		`<a href="https://example.com/post" target="_blank" rel="noopener noreferrer nofollow">example.com/post</a></p>`,
+		`<img src="https://pbs.twimg.com/media/a.jpg" alt="Photo">`,
+		`<blockquote>`,
+		`<p>&lt;b&gt;quoted&lt;/b&gt;</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "javascript:") {
+		t.Errorf("unsafe URL rendered:\n%s", got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	want := "\\# Big news\\\n" +
+		"1\\. read [go.dev/blog](https://go.dev/blog) by [@rob](https://x.com/rob) \\*now\\*\n" +
+		"\n" +
+		"[example.com/post](https://example.com/post)\n" +
+		"\n" +
+		"![Photo](https://pbs.twimg.com/media/a.jpg)\n" +
+		"\n" +
+		"> **Jack** [@jack](https://x.com/jack)\n" +
+		">\n" +
+		"> \\<b\\>quoted\\</b\\>\n"
+	if got := Markdown(testTweet()); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	want := "# Big news\n1. read https://go.dev/blog by @rob *now*\n\nhttps://example.com/post\n\nQuoting @jack: <b>quoted</b>"
+	if got := Text(testTweet()); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"html": FormatHTML, "md": FormatMarkdown, "Markdown": FormatMarkdown, "plain": FormatText} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for pdf")
+	}
+}
@@ -0,0 +1,29 @@
+package render
+
+import (
+	"strings"
+
+	"twitterx-api/internal/models"
+)
+
+// Text renders tweet as plain text: links read as their expanded URLs and
+// the quoted tweet follows on its own paragraph. Media are left out.
+func Text(tweet *models.Tweet) string {
+	var b strings.Builder
+	writeText(&b, tweet)
+	if quote := tweet.Quote; quote != nil {
+		b.WriteString("\n\nQuoting @" + quote.Author.ScreenName + ": ")
+		writeText(&b, quote)
+	}
+	return b.String()
+}
+
+func writeText(b *strings.Builder, tweet *models.Tweet) {
+	for _, s := range segments(tweet) {
+		if s.expanded {
+			b.WriteString(s.href)
+		} else {
+			b.WriteString(s.text)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/httpclient"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/metrics"
+)
+
+// withCallTimeout derives the deadline of a single upstream call from the
+// request budget carried by ctx. The call never outlives the parent context.
+func withCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// upstreamClient returns the client for a single upstream call. Retries are
+// logged with the fields of ctx and counted per service.
+func upstreamClient(ctx context.Context, httpClient *http.Client, retry httpclient.RetryPolicy, breaker *httpclient.Breaker, m metrics.Recorder, service string) *httpclient.Client {
+	return &httpclient.Client{
+		HTTP:    httpClient,
+		Retry:   retry,
+		Breaker: breaker,
+		OnRetry: func(attempt int, delay time.Duration, cause error) {
+			logger.WarnContext(ctx, "%s: retrying in %s (attempt %d): %v", service, delay, attempt, cause)
+			metrics.OrNop(m).ObserveRetry(strings.ToLower(service))
+		},
+	}
+}
+
+// isResponseError reports whether err was built from an upstream response
+// rather than returned by the transport or the circuit breaker
+func isResponseError(err error) bool {
+	var upstreamErr *apperror.UpstreamError
+	var notFoundErr *apperror.NotFoundError
+	return errors.As(err, &upstreamErr) || errors.As(err, &notFoundErr)
+}
+
+// readError marks a failure to read a response body as transient, unless
+// the body exceeded the size cap and would do so again
+func readError(err error) error {
+	if errors.Is(err, apperror.ErrResponseTooLarge) {
+		return err
+	}
+	return httpclient.Retryable(err)
+}
+
+// recordBodySize adds the size of the upstream response to the current span
+func recordBodySize(ctx context.Context, n int) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.body.size", n))
+}
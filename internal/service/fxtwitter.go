@@ -1,34 +1,85 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/cache"
+	"twitterx-api/internal/httpclient"
 	"twitterx-api/internal/logger"
+	"twitterx-api/internal/metrics"
 	"twitterx-api/internal/models"
+	"twitterx-api/internal/ratelimit"
+	"twitterx-api/internal/tracing"
+	"twitterx-api/internal/twittertext"
 )
 
-const fxTwitterAPIBaseURL = "https://api.fxtwitter.com"
+// DefaultFxTwitterURL is the base URL of the public FxTwitter API
+const DefaultFxTwitterURL = "https://api.fxtwitter.com"
 
 // FxTwitterService handles interactions with FxTwitter API
 type FxTwitterService struct {
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	callTimeout time.Duration
+	metrics     metrics.Recorder
+	limiter     *ratelimit.Concurrency
+	tweetCache  *cache.Cache[*models.FxTwitterResponse]
+	userCache   *cache.Cache[*models.FxTwitterUserResponse]
+	retry       httpclient.RetryPolicy
+	breaker     *httpclient.Breaker
 }
 
 // NewFxTwitterService creates a new FxTwitter service instance
-func NewFxTwitterService() *FxTwitterService {
+func NewFxTwitterService(opts ...Option) *FxTwitterService {
+	o := newOptions(opts)
 	return &FxTwitterService{
+		baseURL: o.fxTwitterURL,
 		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Transport: o.transport,
+			Timeout:   15 * time.Second,
 		},
+		callTimeout: 15 * time.Second,
+		metrics:     o.metrics,
+		limiter:     o.limiter,
+		tweetCache:  cache.New[*models.FxTwitterResponse](o.tweetTTL, defaultCacheEntries),
+		userCache:   cache.New[*models.FxTwitterUserResponse](o.userTTL, defaultCacheEntries),
+		retry:       o.retry,
+		breaker:     o.newBreaker("fxtwitter", "fxtwitter"),
 	}
 }
 
+// TweetCacheTTL returns how long tweets are cached
+func (s *FxTwitterService) TweetCacheTTL() time.Duration {
+	return s.tweetCache.TTL()
+}
+
+// UserCacheTTL returns how long user profiles are cached
+func (s *FxTwitterService) UserCacheTTL() time.Duration {
+	return s.userCache.TTL()
+}
+
+func (s *FxTwitterService) apiURL() string {
+	if s.baseURL == "" {
+		return DefaultFxTwitterURL
+	}
+	return s.baseURL
+}
+
+// BreakerState returns the state of the FxTwitter circuit breaker
+func (s *FxTwitterService) BreakerState() string {
+	return s.breaker.State()
+}
+
 // GetTweetData fetches complete tweet data from FxTwitter API
-func (s *FxTwitterService) GetTweetData(username, tweetID string) (*models.FxTwitterResponse, error) {
+func (s *FxTwitterService) GetTweetData(ctx context.Context, username, tweetID string) (*models.FxTwitterResponse, error) {
 	if username == "" {
 		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
 	}
@@ -36,96 +87,226 @@ func (s *FxTwitterService) GetTweetData(username, tweetID string) (*models.FxTwi
 		return nil, &apperror.ValidationError{Field: "tweetID", Message: "cannot be empty"}
 	}
 
-	// Construct API URL
-	// Format: https://api.fxtwitter.com/{username}/status/{id}
-	apiURL := fxTwitterAPIBaseURL + "/" + username + "/status/" + tweetID
-	logger.Debug("FxTwitter: fetching tweet from %s", apiURL)
+	if tweet, ok := s.tweetCache.Get(tweetID); ok {
+		s.observeCache("tweet", true)
+		return tweet, nil
+	}
+	if s.tweetCache != nil {
+		s.observeCache("tweet", false)
+	}
+	return s.FetchTweetData(ctx, username, tweetID)
+}
 
-	// Make HTTP request
-	resp, err := s.httpClient.Get(apiURL)
-	if err != nil {
-		logger.Error("FxTwitter: failed to fetch tweet data: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to fetch tweet data", Err: err}
+// FetchTweetData fetches tweet data like GetTweetData, bypassing the cache,
+// which it refreshes
+func (s *FxTwitterService) FetchTweetData(ctx context.Context, username, tweetID string) (*models.FxTwitterResponse, error) {
+	if username == "" {
+		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
+	}
+	if tweetID == "" {
+		return nil, &apperror.ValidationError{Field: "tweetID", Message: "cannot be empty"}
 	}
-	defer resp.Body.Close()
 
-	logger.Debug("FxTwitter: received response with status %d", resp.StatusCode)
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "FxTwitter: %v", err)
+		return nil, err
+	}
+	defer release()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	spanCtx, span := tracing.StartClient(ctx, "FxTwitter GET /{username}/status/{id}",
+		attribute.String("twitterx.upstream", "fxtwitter"),
+		attribute.String("twitterx.username", username),
+		attribute.String("twitterx.tweet_id", tweetID),
+	)
+	tweet, err := s.fetchTweetData(spanCtx, username, tweetID)
+	tracing.End(span, err)
+	metrics.OrNop(s.metrics).ObserveUpstream("fxtwitter", apperror.Class(err), time.Since(start))
 	if err != nil {
-		logger.Error("FxTwitter: failed to read response body: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to read response body", Err: err}
+		return nil, err
 	}
 
-	logger.Debug("FxTwitter: received %d bytes", len(body))
+	s.tweetCache.Set(tweetID, tweet)
+	return tweet, nil
+}
 
-	// Parse JSON response
-	var fxResponse models.FxTwitterResponse
-	if err := json.Unmarshal(body, &fxResponse); err != nil {
-		logger.Error("FxTwitter: failed to parse JSON response: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to parse JSON response", Err: err}
-	}
+func (s *FxTwitterService) fetchTweetData(ctx context.Context, username, tweetID string) (*models.FxTwitterResponse, error) {
+	ctx = logger.WithFields(ctx, "upstream", "fxtwitter", "tweet_id", tweetID)
+	ctx, cancel := withCallTimeout(ctx, s.callTimeout)
+	defer cancel()
 
-	// Check for API errors (404 = NOT_FOUND, 401 = PRIVATE_TWEET, 500 = API_FAIL)
-	if fxResponse.Code == 404 {
-		return nil, &apperror.NotFoundError{Resource: "tweet", ID: tweetID}
-	}
-	if fxResponse.Code != 200 {
-		logger.Error("FxTwitter: API error: %s (code: %d)", fxResponse.Message, fxResponse.Code)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", StatusCode: fxResponse.Code, Message: fxResponse.Message}
+	// Construct API URL
+	// Format: https://api.fxtwitter.com/{username}/status/{id}
+	apiURL := s.apiURL() + "/" + username + "/status/" + tweetID
+	logger.DebugContext(ctx, "FxTwitter: fetching tweet from %s", apiURL)
+
+	// Make HTTP request, retrying transient failures
+	var fxResponse models.FxTwitterResponse
+	start := time.Now()
+	client := upstreamClient(ctx, s.httpClient, s.retry, s.breaker, s.metrics, "FxTwitter")
+	err := client.Get(ctx, apiURL, func(resp *http.Response) error {
+		logger.DebugContext(ctx, "FxTwitter: received response with status %d in %s", resp.StatusCode, time.Since(start))
+		fxResponse = models.FxTwitterResponse{}
+		if err := readJSON(ctx, resp, &fxResponse); err != nil {
+			return err
+		}
+		// Check for API errors (404 = NOT_FOUND, 401 = PRIVATE_TWEET, 500 = API_FAIL)
+		return apiError(ctx, fxResponse.Code, fxResponse.Message, "tweet", tweetID)
+	})
+	if err != nil {
+		if !isResponseError(err) {
+			logger.ErrorContext(ctx, "FxTwitter: failed to fetch tweet data: %v", err)
+			return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to fetch tweet data", Err: err}
+		}
+		return nil, err
 	}
 
-	logger.Debug("FxTwitter: successfully fetched tweet %s", tweetID)
+	logger.DebugContext(ctx, "FxTwitter: successfully fetched tweet %s", tweetID)
+	addEntities(fxResponse.Tweet)
 	return &fxResponse, nil
 }
 
 // GetUserData fetches user profile data from FxTwitter API
-func (s *FxTwitterService) GetUserData(username string) (*models.FxTwitterUserResponse, error) {
+func (s *FxTwitterService) GetUserData(ctx context.Context, username string) (*models.FxTwitterUserResponse, error) {
+	if username == "" {
+		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
+	}
+
+	key := strings.ToLower(username)
+	if user, ok := s.userCache.Get(key); ok {
+		s.observeCache("user", true)
+		return user, nil
+	}
+	if s.userCache != nil {
+		s.observeCache("user", false)
+	}
+	return s.FetchUserData(ctx, username)
+}
+
+// FetchUserData fetches user profile data like GetUserData, bypassing the
+// cache, which it refreshes
+func (s *FxTwitterService) FetchUserData(ctx context.Context, username string) (*models.FxTwitterUserResponse, error) {
 	if username == "" {
 		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
 	}
 
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "FxTwitter: %v", err)
+		return nil, err
+	}
+	defer release()
+
+	start := time.Now()
+	spanCtx, span := tracing.StartClient(ctx, "FxTwitter GET /{username}",
+		attribute.String("twitterx.upstream", "fxtwitter"),
+		attribute.String("twitterx.username", username),
+	)
+	user, err := s.fetchUserData(spanCtx, username)
+	tracing.End(span, err)
+	metrics.OrNop(s.metrics).ObserveUpstream("fxtwitter", apperror.Class(err), time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	s.userCache.Set(strings.ToLower(username), user)
+	return user, nil
+}
+
+// Probe checks that the FxTwitter API can serve the profile of username.
+// The cache is bypassed; a missing user still proves the API works.
+func (s *FxTwitterService) Probe(ctx context.Context, username string) error {
+	_, err := s.fetchUserData(ctx, username)
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+func (s *FxTwitterService) observeCache(name string, hit bool) {
+	metrics.OrNop(s.metrics).ObserveCache(name, hit)
+}
+
+func (s *FxTwitterService) fetchUserData(ctx context.Context, username string) (*models.FxTwitterUserResponse, error) {
+	ctx = logger.WithFields(ctx, "upstream", "fxtwitter")
+	ctx, cancel := withCallTimeout(ctx, s.callTimeout)
+	defer cancel()
+
 	// Construct API URL
 	// Format: https://api.fxtwitter.com/{username}
-	apiURL := fxTwitterAPIBaseURL + "/" + username
-	logger.Debug("FxTwitter: fetching user from %s", apiURL)
+	apiURL := s.apiURL() + "/" + username
+	logger.DebugContext(ctx, "FxTwitter: fetching user from %s", apiURL)
 
-	// Make HTTP request
-	resp, err := s.httpClient.Get(apiURL)
+	// Make HTTP request, retrying transient failures
+	var fxUserResponse models.FxTwitterUserResponse
+	start := time.Now()
+	client := upstreamClient(ctx, s.httpClient, s.retry, s.breaker, s.metrics, "FxTwitter")
+	err := client.Get(ctx, apiURL, func(resp *http.Response) error {
+		logger.DebugContext(ctx, "FxTwitter: received response with status %d in %s", resp.StatusCode, time.Since(start))
+		fxUserResponse = models.FxTwitterUserResponse{}
+		if err := readJSON(ctx, resp, &fxUserResponse); err != nil {
+			return err
+		}
+		// Check for API errors (404 = NOT_FOUND, 500 = API_FAIL)
+		return apiError(ctx, fxUserResponse.Code, fxUserResponse.Message, "user", username)
+	})
 	if err != nil {
-		logger.Error("FxTwitter: failed to fetch user data: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to fetch user data", Err: err}
+		if !isResponseError(err) {
+			logger.ErrorContext(ctx, "FxTwitter: failed to fetch user data: %v", err)
+			return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to fetch user data", Err: err}
+		}
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	logger.Debug("FxTwitter: received response with status %d", resp.StatusCode)
+	logger.DebugContext(ctx, "FxTwitter: successfully fetched user %s", username)
+	return &fxUserResponse, nil
+}
 
+// readJSON decodes the body of an FxTwitter response into v
+func readJSON(ctx context.Context, resp *http.Response, v any) error {
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.Error("FxTwitter: failed to read response body: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to read response body", Err: err}
+		logger.ErrorContext(ctx, "FxTwitter: failed to read response body: %v", err)
+		return readError(&apperror.UpstreamError{Service: "FxTwitter", Message: "failed to read response body", Err: err})
 	}
 
-	logger.Debug("FxTwitter: received %d bytes", len(body))
+	logger.DebugContext(ctx, "FxTwitter: received %d bytes", len(body))
+	recordBodySize(ctx, len(body))
 
 	// Parse JSON response
-	var fxUserResponse models.FxTwitterUserResponse
-	if err := json.Unmarshal(body, &fxUserResponse); err != nil {
-		logger.Error("FxTwitter: failed to parse JSON response: %v", err)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to parse JSON response", Err: err}
+	if err := json.Unmarshal(body, v); err != nil {
+		logger.ErrorContext(ctx, "FxTwitter: failed to parse JSON response: %v", err)
+		return &apperror.UpstreamError{Service: "FxTwitter", Message: "failed to parse JSON response", Err: err}
 	}
+	return nil
+}
 
-	// Check for API errors (404 = NOT_FOUND, 500 = API_FAIL)
-	if fxUserResponse.Code == 404 {
-		return nil, &apperror.NotFoundError{Resource: "user", ID: username}
+// apiError converts the status code embedded in an FxTwitter response about
+// resource id into an error. API failures (5xx) are transient and worth
+// retrying.
+func apiError(ctx context.Context, code int, message, resource, id string) error {
+	switch {
+	case code == 200:
+		return nil
+	case code == 404:
+		return &apperror.NotFoundError{Resource: resource, ID: id}
+	case code == 401:
+		return &apperror.NotFoundError{Resource: resource, ID: id, Reason: apperror.ErrProtected}
 	}
-	if fxUserResponse.Code != 200 {
-		logger.Error("FxTwitter: API error: %s (code: %d)", fxUserResponse.Message, fxUserResponse.Code)
-		return nil, &apperror.UpstreamError{Service: "FxTwitter", StatusCode: fxUserResponse.Code, Message: fxUserResponse.Message}
+	logger.ErrorContext(ctx, "FxTwitter: API error: %s (code: %d)", message, code)
+	err := error(&apperror.UpstreamError{Service: "FxTwitter", StatusCode: code, Message: message})
+	if code >= 500 {
+		return httpclient.Retryable(err)
 	}
+	return err
+}
 
-	logger.Debug("FxTwitter: successfully fetched user %s", username)
-	return &fxUserResponse, nil
+// addEntities extracts the entities of the text of tweet and of its quote
+func addEntities(tweet *models.Tweet) {
+	for ; tweet != nil; tweet = tweet.Quote {
+		tweet.Entities = twittertext.Extract(tweet.Text)
+	}
 }
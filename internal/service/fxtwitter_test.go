@@ -2,12 +2,16 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/httpclient"
+	"twitterx-api/internal/metrics"
 )
 
 type roundTripFunc func(*http.Request) (*http.Response, error)
@@ -18,13 +22,13 @@ func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
 
 func TestFxTwitterServiceGetTweetDataValidation(t *testing.T) {
 	svc := &FxTwitterService{}
-	_, err := svc.GetTweetData("", "123")
+	_, err := svc.GetTweetData(context.Background(), "", "123")
 	var vErr *apperror.ValidationError
 	if !errors.As(err, &vErr) {
 		t.Fatalf("expected ValidationError for username, got %v", err)
 	}
 
-	_, err = svc.GetTweetData("user", "")
+	_, err = svc.GetTweetData(context.Background(), "user", "")
 	if !errors.As(err, &vErr) {
 		t.Fatalf("expected ValidationError for tweetID, got %v", err)
 	}
@@ -40,13 +44,29 @@ func TestFxTwitterServiceGetTweetDataNotFound(t *testing.T) {
 		}, nil
 	})}}
 
-	_, err := svc.GetTweetData("user", "123")
+	_, err := svc.GetTweetData(context.Background(), "user", "123")
 	var nfErr *apperror.NotFoundError
 	if !errors.As(err, &nfErr) {
 		t.Fatalf("expected NotFoundError, got %v", err)
 	}
 }
 
+func TestFxTwitterServiceGetTweetDataProtected(t *testing.T) {
+	svc := &FxTwitterService{httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{"code":401,"message":"PRIVATE_TWEET"}`
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}}
+
+	_, err := svc.GetTweetData(context.Background(), "user", "123")
+	if !errors.Is(err, apperror.ErrProtected) || apperror.HTTPStatusCode(err) != http.StatusBadGateway {
+		t.Fatalf("expected protected error, got %v", err)
+	}
+}
+
 func TestFxTwitterServiceGetTweetDataUpstreamError(t *testing.T) {
 	svc := &FxTwitterService{httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
 		body := `{"code":500,"message":"API_FAIL"}`
@@ -57,7 +77,7 @@ func TestFxTwitterServiceGetTweetDataUpstreamError(t *testing.T) {
 		}, nil
 	})}}
 
-	_, err := svc.GetTweetData("user", "123")
+	_, err := svc.GetTweetData(context.Background(), "user", "123")
 	var upErr *apperror.UpstreamError
 	if !errors.As(err, &upErr) {
 		t.Fatalf("expected UpstreamError, got %v", err)
@@ -74,7 +94,7 @@ func TestFxTwitterServiceGetTweetDataSuccess(t *testing.T) {
 		}, nil
 	})}}
 
-	resp, err := svc.GetTweetData("user", "123")
+	resp, err := svc.GetTweetData(context.Background(), "user", "123")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -83,6 +103,29 @@ func TestFxTwitterServiceGetTweetDataSuccess(t *testing.T) {
 	}
 }
 
+func TestFxTwitterServiceGetTweetDataEntities(t *testing.T) {
+	svc := &FxTwitterService{httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{"code":200,"message":"OK","tweet":{"id":"123","text":"#go by @rob","created_at":"Mon Jan 02 15:04:05 -0700 2006","author":{"screen_name":"a"},"quote":{"id":"7","text":"$GOOG","created_at":"Mon Jan 02 15:04:05 -0700 2006","author":{"screen_name":"b"}}}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}}
+
+	resp, err := svc.GetTweetData(context.Background(), "user", "123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entities := resp.Tweet.Entities
+	if entities == nil || len(entities.Hashtags) != 1 || len(entities.Mentions) != 1 || entities.Mentions[0].ScreenName != "rob" {
+		t.Fatalf("unexpected entities: %#v", entities)
+	}
+	if quote := resp.Tweet.Quote.Entities; quote == nil || len(quote.Cashtags) != 1 {
+		t.Fatalf("unexpected quote entities: %#v", quote)
+	}
+}
+
 func TestFxTwitterServiceGetTweetDataWithReply(t *testing.T) {
 	svc := &FxTwitterService{httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
 		body := `{"code":200,"message":"OK","tweet":{"id":"123","text":"hi","author":{"id":"1","name":"A","screen_name":"a","avatar_url":"x","verified":false,"blue_badge":false},"created_at":"Mon Jan 02 15:04:05 -0700 2006","created_timestamp":1,"replies":0,"retweets":0,"likes":0,"possibly_scam":false,"possibly_sensitive":false,"lang":"en","source":"web","replying_to":"otheruser","replying_to_status":"999"}}`
@@ -93,7 +136,7 @@ func TestFxTwitterServiceGetTweetDataWithReply(t *testing.T) {
 		}, nil
 	})}}
 
-	resp, err := svc.GetTweetData("user", "123")
+	resp, err := svc.GetTweetData(context.Background(), "user", "123")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -110,7 +153,7 @@ func TestFxTwitterServiceGetTweetDataWithReply(t *testing.T) {
 
 func TestFxTwitterServiceGetUserDataValidation(t *testing.T) {
 	svc := &FxTwitterService{}
-	_, err := svc.GetUserData("")
+	_, err := svc.GetUserData(context.Background(), "")
 	var vErr *apperror.ValidationError
 	if !errors.As(err, &vErr) {
 		t.Fatalf("expected ValidationError, got %v", err)
@@ -127,7 +170,7 @@ func TestFxTwitterServiceGetUserDataNotFound(t *testing.T) {
 		}, nil
 	})}}
 
-	_, err := svc.GetUserData("missing")
+	_, err := svc.GetUserData(context.Background(), "missing")
 	var nfErr *apperror.NotFoundError
 	if !errors.As(err, &nfErr) {
 		t.Fatalf("expected NotFoundError, got %v", err)
@@ -144,7 +187,7 @@ func TestFxTwitterServiceGetUserDataUpstreamError(t *testing.T) {
 		}, nil
 	})}}
 
-	_, err := svc.GetUserData("user")
+	_, err := svc.GetUserData(context.Background(), "user")
 	var upErr *apperror.UpstreamError
 	if !errors.As(err, &upErr) {
 		t.Fatalf("expected UpstreamError, got %v", err)
@@ -161,7 +204,7 @@ func TestFxTwitterServiceGetUserDataSuccess(t *testing.T) {
 		}, nil
 	})}}
 
-	resp, err := svc.GetUserData("user")
+	resp, err := svc.GetUserData(context.Background(), "user")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -169,3 +212,148 @@ func TestFxTwitterServiceGetUserDataSuccess(t *testing.T) {
 		t.Fatalf("unexpected user response: %#v", resp.User)
 	}
 }
+
+type recordedCall struct {
+	name  string
+	label string
+}
+
+type fakeRecorder struct {
+	metrics.Nop
+	calls []recordedCall
+}
+
+func (f *fakeRecorder) ObserveUpstream(service, errorClass string, _ time.Duration) {
+	f.calls = append(f.calls, recordedCall{"upstream:" + service, errorClass})
+}
+
+func (f *fakeRecorder) ObserveCache(cache string, hit bool) {
+	label := "miss"
+	if hit {
+		label = "hit"
+	}
+	f.calls = append(f.calls, recordedCall{"cache:" + cache, label})
+}
+
+func TestFxTwitterServiceGetTweetDataCached(t *testing.T) {
+	requests := 0
+	recorder := &fakeRecorder{}
+	svc := NewFxTwitterService(WithMetrics(recorder))
+	svc.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		body := `{"code":200,"message":"OK","tweet":{"id":"123","text":"hello","author":{"screen_name":"user"}}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	for i := 0; i < 2; i++ {
+		resp, err := svc.GetTweetData(context.Background(), "user", "123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Tweet == nil || resp.Tweet.ID != "123" {
+			t.Fatalf("unexpected tweet: %#v", resp.Tweet)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", requests)
+	}
+	want := []recordedCall{{"cache:tweet", "miss"}, {"upstream:fxtwitter", ""}, {"cache:tweet", "hit"}}
+	if len(recorder.calls) != len(want) {
+		t.Fatalf("unexpected metrics: %#v", recorder.calls)
+	}
+	for i := range want {
+		if recorder.calls[i] != want[i] {
+			t.Fatalf("unexpected metrics: %#v", recorder.calls)
+		}
+	}
+}
+
+func TestFxTwitterServiceConcurrencyLimit(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	svc := NewFxTwitterService(WithConcurrencyLimit(1, 1, 20*time.Millisecond), WithUserCacheTTL(0))
+	svc.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		close(started)
+		<-unblock
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"code":200,"message":"OK","user":{"screen_name":"user"}}`)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.GetUserData(context.Background(), "user")
+		done <- err
+	}()
+	<-started
+
+	_, err := svc.GetUserData(context.Background(), "other")
+	var rlErr *apperror.RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if code := apperror.HTTPStatusCode(err); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", code)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFxTwitterServiceGetTweetDataRetriesAPIFail(t *testing.T) {
+	calls := 0
+	svc := &FxTwitterService{
+		httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			body := `{"code":500,"message":"API_FAIL"}`
+			if calls == 2 {
+				body = `{"code":200,"message":"OK","tweet":{"id":"123"}}`
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}, nil
+		})},
+		retry: httpclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	}
+
+	tweet, err := svc.GetTweetData(context.Background(), "user", "123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tweet.Tweet.ID != "123" || calls != 2 {
+		t.Fatalf("expected tweet 123 after 2 calls, got %q after %d", tweet.Tweet.ID, calls)
+	}
+}
+
+func TestFxTwitterServiceCircuitOpen(t *testing.T) {
+	calls := 0
+	svc := &FxTwitterService{
+		httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			return nil, errors.New("connection refused")
+		})},
+		breaker: httpclient.NewBreaker(1, time.Minute, nil),
+	}
+
+	if _, err := svc.GetUserData(context.Background(), "user"); err == nil {
+		t.Fatal("expected an error")
+	}
+	_, err := svc.GetUserData(context.Background(), "user")
+	if !errors.Is(err, apperror.ErrCircuitOpen) || apperror.HTTPStatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("expected ErrCircuitOpen with 503, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
@@ -1,83 +1,434 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/cache"
+	"twitterx-api/internal/httpclient"
 	"twitterx-api/internal/logger"
+	"twitterx-api/internal/metrics"
+	"twitterx-api/internal/models"
 	"twitterx-api/internal/parser"
+	"twitterx-api/internal/ratelimit"
+	"twitterx-api/internal/tracing"
+	"twitterx-api/internal/twittertext"
 )
 
-// NitterService handles interactions with Nitter API
+// maxErrorPageSize bounds how much of a non-RSS response is inspected
+const maxErrorPageSize = 64 << 10
+
+// NitterService handles interactions with Nitter API. When several instances
+// are configured they are tried in order until one answers.
 type NitterService struct {
-	baseURL    string
-	httpClient *http.Client
+	instances     []string
+	httpClient    *http.Client
+	callTimeout   time.Duration
+	metrics       metrics.Recorder
+	limiter       *ratelimit.Concurrency
+	timelineCache *cache.Cache[*Timeline]
+	retry         httpclient.RetryPolicy
+	breakers      map[string]*httpclient.Breaker
+}
+
+// Timeline is a page of tweets as published in a Nitter RSS feed
+type Timeline struct {
+	TweetIDs []string
+	// Tweets holds the text and entities of every tweet of TweetIDs, in the
+	// same order
+	Tweets []models.TimelineTweet
+	// LastModified is the newest pubDate of the feed (zero when unknown)
+	LastModified time.Time
+	// NextCursor requests the following, older page (empty on the last page)
+	NextCursor string
+}
+
+// PinnedTweetID returns the ID of the tweet pinned to the profile, or an
+// empty string when the page lists none
+func (t *Timeline) PinnedTweetID() string {
+	for _, tweet := range t.Tweets {
+		if tweet.Pinned {
+			return tweet.ID
+		}
+	}
+	return ""
+}
+
+// UnpinnedTweetIDs returns TweetIDs without the pinned tweet, which Nitter
+// lists first whatever its age
+func (t *Timeline) UnpinnedTweetIDs() []string {
+	pinned := t.PinnedTweetID()
+	if pinned == "" {
+		return t.TweetIDs
+	}
+	return slices.DeleteFunc(slices.Clone(t.TweetIDs), func(id string) bool { return id == pinned })
+}
+
+func (t *Timeline) clone() *Timeline {
+	c := *t
+	c.TweetIDs = slices.Clone(t.TweetIDs)
+	c.Tweets = slices.Clone(t.Tweets)
+	return &c
 }
 
 // NewNitterService creates a new Nitter service instance
-func NewNitterService(baseURL string) *NitterService {
+func NewNitterService(instances []string, opts ...Option) *NitterService {
+	o := newOptions(opts)
+	breakers := make(map[string]*httpclient.Breaker, len(instances))
+	for _, instance := range instances {
+		breakers[instance] = o.newBreaker("nitter", instance)
+	}
 	return &NitterService{
-		baseURL: baseURL,
+		instances: slices.Clone(instances),
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Transport: o.transport,
+			Timeout:   10 * time.Second,
 		},
+		callTimeout:   10 * time.Second,
+		metrics:       o.metrics,
+		limiter:       o.limiter,
+		timelineCache: cache.New[*Timeline](o.timelineTTL, defaultCacheEntries),
+		retry:         o.retry,
+		breakers:      breakers,
+	}
+}
+
+// Instances returns the configured Nitter base URLs in failover order
+func (s *NitterService) Instances() []string {
+	return slices.Clone(s.instances)
+}
+
+// CachedTimeline returns the cached first page of the timeline of username,
+// without calling Nitter
+func (s *NitterService) CachedTimeline(username string) (*Timeline, bool) {
+	timeline, ok := s.timelineCache.Get(strings.ToLower(username))
+	if !ok {
+		return nil, false
 	}
+	return timeline.clone(), true
+}
+
+// TimelineCacheTTL returns how long timelines are cached
+func (s *NitterService) TimelineCacheTTL() time.Duration {
+	return s.timelineCache.TTL()
+}
+
+// BreakerState returns the circuit breaker state of instance
+func (s *NitterService) BreakerState(instance string) string {
+	return s.breakers[instance].State()
 }
 
 // GetUserTweetIDs fetches tweet IDs for a given username from Nitter RSS feed
-func (s *NitterService) GetUserTweetIDs(username string) ([]string, error) {
+func (s *NitterService) GetUserTweetIDs(ctx context.Context, username string) ([]string, error) {
+	timeline, err := s.GetUserTimeline(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return timeline.TweetIDs, nil
+}
+
+// GetUserTimeline fetches the recent tweets of username from Nitter RSS feed
+func (s *NitterService) GetUserTimeline(ctx context.Context, username string) (*Timeline, error) {
+	return s.GetUserTimelinePage(ctx, username, "")
+}
+
+// FetchUserTimeline fetches the recent tweets of username like
+// GetUserTimeline, bypassing the cache, which it refreshes
+func (s *NitterService) FetchUserTimeline(ctx context.Context, username string) (*Timeline, error) {
 	if username == "" {
 		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
 	}
+	timeline, err := s.fetchFeed(ctx, userFeed(username, ""))
+	if err != nil {
+		return nil, err
+	}
+	s.timelineCache.Set(strings.ToLower(username), timeline.clone())
+	return timeline, nil
+}
 
-	// Construct RSS URL
-	rssURL := s.baseURL + "/" + username + "/rss"
-	logger.Debug("Nitter: fetching RSS from %s", rssURL)
+// GetUserTimelinePage fetches the page of the timeline of username that
+// starts at cursor. An empty cursor returns the first, cached page.
+func (s *NitterService) GetUserTimelinePage(ctx context.Context, username, cursor string) (*Timeline, error) {
+	if username == "" {
+		return nil, &apperror.ValidationError{Field: "username", Message: "cannot be empty"}
+	}
+
+	key := strings.ToLower(username)
+	if cursor == "" {
+		if timeline, ok := s.timelineCache.Get(key); ok {
+			s.observeCache(true)
+			return timeline.clone(), nil
+		}
+		if s.timelineCache != nil {
+			s.observeCache(false)
+		}
+	}
 
-	// Make HTTP request
-	resp, err := s.httpClient.Get(rssURL)
+	timeline, err := s.fetchFeed(ctx, userFeed(username, cursor))
 	if err != nil {
-		logger.Error("Nitter: failed to fetch RSS feed: %v", err)
-		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "failed to fetch RSS feed", Err: err}
+		return nil, err
+	}
+	if cursor == "" {
+		s.timelineCache.Set(key, timeline.clone())
 	}
-	defer resp.Body.Close()
+	return timeline, nil
+}
 
-	logger.Debug("Nitter: received response with status %d", resp.StatusCode)
+// Search fetches the page of tweets matching query that starts at cursor.
+// Search results are not cached.
+func (s *NitterService) Search(ctx context.Context, query, cursor string) (*Timeline, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, &apperror.ValidationError{Field: "query", Message: "cannot be empty"}
+	}
+	params := url.Values{"f": {"tweets"}, "q": {query}}
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	return s.fetchFeed(ctx, feed{
+		span:     "Nitter GET /search/rss",
+		path:     "/search/rss?" + params.Encode(),
+		resource: "search",
+		id:       query,
+	})
+}
 
-	// Check response status
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, &apperror.NotFoundError{Resource: "user", ID: username}
+// feed identifies a Nitter RSS feed
+type feed struct {
+	// span names the tracing span of the call
+	span string
+	// path is appended to the instance URL
+	path string
+	// resource and id are reported when the feed doesn't exist
+	resource string
+	id       string
+}
+
+func userFeed(username, cursor string) feed {
+	path := "/" + username + "/rss"
+	if cursor != "" {
+		path += "?" + url.Values{"cursor": {cursor}}.Encode()
+	}
+	return feed{span: "Nitter GET /{username}/rss", path: path, resource: "user", id: username}
+}
+
+// fetchFeed fetches f from the first instance that answers
+func (s *NitterService) fetchFeed(ctx context.Context, f feed) (*Timeline, error) {
+	if len(s.instances) == 0 {
+		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "no instances configured"}
+	}
+
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "Nitter: %v", err)
+		return nil, err
+	}
+	defer release()
+
+	for i, instance := range s.instances {
+		var timeline *Timeline
+		timeline, err = s.fetchFromInstance(ctx, instance, f)
+		if err == nil {
+			return timeline, nil
+		}
+		if !shouldFailover(ctx, err) || i == len(s.instances)-1 {
+			break
+		}
+		logger.WarnContext(ctx, "Nitter: instance %s failed, trying next: %v", instance, err)
+	}
+	return nil, err
+}
+
+// Probe checks that instance can serve the RSS feed of username. A missing
+// user still proves the instance works, so only other failures are returned.
+func (s *NitterService) Probe(ctx context.Context, instance, username string) error {
+	_, err := s.fetchFromInstance(ctx, instance, userFeed(username, ""))
+	var notFoundErr *apperror.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+func (s *NitterService) fetchFromInstance(ctx context.Context, instance string, f feed) (*Timeline, error) {
+	start := time.Now()
+	attrs := []attribute.KeyValue{
+		attribute.String("twitterx.upstream", "nitter"),
+		attribute.String("twitterx.instance", instance),
+	}
+	if f.resource == "user" {
+		attrs = append(attrs, attribute.String("twitterx.username", f.id))
+	}
+	spanCtx, span := tracing.StartClient(ctx, f.span, attrs...)
+	timeline, err := s.fetchTimeline(spanCtx, instance, f)
+	tracing.End(span, err)
+	s.observe(instance, err, time.Since(start))
+	return timeline, err
+}
+
+// shouldFailover reports whether another instance may succeed where this one failed
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch apperror.Class(err) {
+	case apperror.ClassNotFound, apperror.ClassSuspended, apperror.ClassValidation, apperror.ClassCanceled:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *NitterService) observeCache(hit bool) {
+	metrics.OrNop(s.metrics).ObserveCache("timeline", hit)
+}
+
+// observe reports the outcome of an upstream call. A missing or suspended
+// user still means the instance answered, so only other failures mark it
+// unhealthy.
+func (s *NitterService) observe(instance string, err error, duration time.Duration) {
+	m := metrics.OrNop(s.metrics)
+	class := apperror.Class(err)
+	m.ObserveUpstream("nitter", class, duration)
+	switch class {
+	case "", apperror.ClassNotFound, apperror.ClassSuspended:
+		m.SetInstanceHealth("nitter", instance, true)
+	case apperror.ClassCanceled:
+	default:
+		m.SetInstanceHealth("nitter", instance, false)
 	}
+}
+
+func (s *NitterService) fetchTimeline(ctx context.Context, baseURL string, f feed) (*Timeline, error) {
+	ctx = logger.WithFields(ctx, "upstream", "nitter", "instance", baseURL)
+	ctx, cancel := withCallTimeout(ctx, s.callTimeout)
+	defer cancel()
+
+	// Construct RSS URL
+	rssURL := baseURL + f.path
+	logger.DebugContext(ctx, "Nitter: fetching RSS from %s", rssURL)
+
+	// Make HTTP request, retrying transient failures
+	var timeline *Timeline
+	start := time.Now()
+	client := upstreamClient(ctx, s.httpClient, s.retry, s.breakers[baseURL], s.metrics, "Nitter")
+	err := client.Get(ctx, rssURL, func(resp *http.Response) error {
+		logger.DebugContext(ctx, "Nitter: received response with status %d in %s", resp.StatusCode, time.Since(start))
+		var err error
+		timeline, err = readTimeline(ctx, resp, f)
+		return err
+	})
+	if err != nil && !isResponseError(err) {
+		logger.ErrorContext(ctx, "Nitter: failed to fetch RSS feed: %v", err)
+		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "failed to fetch RSS feed", Err: err}
+	}
+	return timeline, err
+}
+
+// readTimeline extracts the timeline from an RSS response
+func readTimeline(ctx context.Context, resp *http.Response, f feed) (*Timeline, error) {
+	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		logger.Error("Nitter: unexpected status code: %d", resp.StatusCode)
+		page, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorPageSize))
+		if err := classifyErrorPage(page, resp.StatusCode, f); err != nil {
+			logger.ErrorContext(ctx, "Nitter: error page: %v", err)
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, &apperror.NotFoundError{Resource: f.resource, ID: f.id}
+		}
+		logger.ErrorContext(ctx, "Nitter: unexpected status code: %d", resp.StatusCode)
 		return nil, &apperror.UpstreamError{Service: "Nitter", StatusCode: resp.StatusCode, Message: "unexpected status code"}
 	}
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.Error("Nitter: failed to read response body: %v", err)
-		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "failed to read response body", Err: err}
+		logger.ErrorContext(ctx, "Nitter: failed to read response body: %v", err)
+		return nil, readError(&apperror.UpstreamError{Service: "Nitter", Message: "failed to read response body", Err: err})
 	}
 
-	logger.Debug("Nitter: received %d bytes", len(body))
+	logger.DebugContext(ctx, "Nitter: received %d bytes", len(body))
+	recordBodySize(ctx, len(body))
+
+	// Nitter may answer with an HTML error page instead of a feed
+	if err := classifyErrorPage(body, resp.StatusCode, f); err != nil {
+		logger.ErrorContext(ctx, "Nitter: error page: %v", err)
+		return nil, err
+	}
 
 	// Parse RSS
 	rss, err := parser.ParseRSS(body)
 	if err != nil {
-		logger.Error("Nitter: failed to parse RSS: %v", err)
+		logger.ErrorContext(ctx, "Nitter: failed to parse RSS: %v", err)
 		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "failed to parse RSS", Err: err}
 	}
 
 	// Extract tweet IDs
 	tweetIDs, err := parser.ExtractTweetIDs(rss)
 	if err != nil {
-		logger.Error("Nitter: failed to extract tweet IDs: %v", err)
+		logger.ErrorContext(ctx, "Nitter: failed to extract tweet IDs: %v", err)
 		return nil, &apperror.UpstreamError{Service: "Nitter", Message: "failed to extract tweet IDs", Err: err}
 	}
 
-	logger.Debug("Nitter: extracted %d tweet IDs", len(tweetIDs))
-	return tweetIDs, nil
+	logger.DebugContext(ctx, "Nitter: extracted %d tweet IDs", len(tweetIDs))
+	timeline := &Timeline{TweetIDs: tweetIDs, Tweets: timelineTweets(rss), LastModified: parser.LatestPubDate(rss)}
+	// Nitter sends the cursor of the next page in the Min-Id header
+	if len(tweetIDs) > 0 {
+		timeline.NextCursor = resp.Header.Get("Min-Id")
+	}
+	return timeline, nil
+}
+
+// timelineTweets returns the tweets of the feed items that have a tweet ID,
+// with the entities of their text. The anchors of the description HTML
+// carry the expanded URLs, the other entities are found in the text.
+func timelineTweets(rss *parser.RSS) []models.TimelineTweet {
+	tweets := []models.TimelineTweet{}
+	for _, item := range rss.Channel.Items {
+		id, ok := item.TweetID()
+		if !ok {
+			continue
+		}
+		text, anchors := parser.ParseDescription(item.Description)
+		var links []twittertext.Link
+		for _, a := range anchors {
+			// Nitter links hashtags and mentions to itself
+			if !strings.ContainsAny(text[a.Start:a.Start+1], "#@$") {
+				links = append(links, twittertext.Link{Start: a.Start, End: a.End, URL: a.Href})
+			}
+		}
+		published, _ := parser.ParsePubDate(item.PubDate)
+		tweets = append(tweets, models.TimelineTweet{
+			ID:          id,
+			Author:      strings.TrimPrefix(item.Creator, "@"),
+			Text:        text,
+			Entities:    twittertext.ExtractWithLinks(text, links),
+			PublishedAt: published,
+			RetweetedBy: item.RetweetedBy(),
+			Pinned:      item.Pinned(),
+		})
+	}
+	return tweets
+}
+
+// classifyErrorPage turns a recognized Nitter error page into an error
+func classifyErrorPage(page []byte, statusCode int, f feed) error {
+	switch parser.DetectNitterError(page) {
+	case parser.NitterErrorRateLimited:
+		return &apperror.UpstreamError{Service: "Nitter", StatusCode: statusCode, Message: "instance has no usable sessions", Err: apperror.ErrSessionsExhausted}
+	case parser.NitterErrorNotFound:
+		return &apperror.NotFoundError{Resource: f.resource, ID: f.id}
+	case parser.NitterErrorSuspended:
+		return &apperror.NotFoundError{Resource: f.resource, ID: f.id, Reason: apperror.ErrSuspended}
+	default:
+		return nil
+	}
 }
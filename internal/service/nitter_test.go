@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/httpclient"
 )
 
 const nitterSampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
@@ -28,7 +31,7 @@ const nitterSampleRSS = `<?xml version="1.0" encoding="UTF-8"?>
 
 func TestNitterServiceGetUserTweetIDsValidation(t *testing.T) {
 	svc := &NitterService{}
-	_, err := svc.GetUserTweetIDs("")
+	_, err := svc.GetUserTweetIDs(context.Background(), "")
 	var vErr *apperror.ValidationError
 	if !errors.As(err, &vErr) {
 		t.Fatalf("expected ValidationError, got %v", err)
@@ -41,8 +44,8 @@ func TestNitterServiceGetUserTweetIDsNotFound(t *testing.T) {
 	}))
 	defer server.Close()
 
-	svc := &NitterService{baseURL: server.URL, httpClient: server.Client()}
-	_, err := svc.GetUserTweetIDs("missing")
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "missing")
 	var nfErr *apperror.NotFoundError
 	if !errors.As(err, &nfErr) {
 		t.Fatalf("expected NotFoundError, got %v", err)
@@ -55,8 +58,8 @@ func TestNitterServiceGetUserTweetIDsUpstreamStatus(t *testing.T) {
 	}))
 	defer server.Close()
 
-	svc := &NitterService{baseURL: server.URL, httpClient: server.Client()}
-	_, err := svc.GetUserTweetIDs("user")
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "user")
 	var upErr *apperror.UpstreamError
 	if !errors.As(err, &upErr) {
 		t.Fatalf("expected UpstreamError, got %v", err)
@@ -70,8 +73,8 @@ func TestNitterServiceGetUserTweetIDsParseError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	svc := &NitterService{baseURL: server.URL, httpClient: server.Client()}
-	_, err := svc.GetUserTweetIDs("user")
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "user")
 	var upErr *apperror.UpstreamError
 	if !errors.As(err, &upErr) {
 		t.Fatalf("expected UpstreamError, got %v", err)
@@ -85,8 +88,8 @@ func TestNitterServiceGetUserTweetIDsSuccess(t *testing.T) {
 	}))
 	defer server.Close()
 
-	svc := &NitterService{baseURL: server.URL, httpClient: server.Client()}
-	ids, err := svc.GetUserTweetIDs("user")
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	ids, err := svc.GetUserTweetIDs(context.Background(), "user")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -97,3 +100,221 @@ func TestNitterServiceGetUserTweetIDsSuccess(t *testing.T) {
 		t.Fatalf("unexpected IDs: %#v", ids)
 	}
 }
+
+func TestNitterServiceGetUserTimelineTweets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<rss xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><item>
+      <dc:creator>@rob</dc:creator>
+      <description><![CDATA[<p>Über <a href="https://nitter.net/search?q=%23golang">#golang</a> <a href="https://go.dev/blog/go1.24">go.dev/blog/go1.24</a></p>]]></description>
+      <pubDate>Tue, 11 Feb 2025 10:00:00 GMT</pubDate>
+      <guid>https://nitter.net/rob/status/1889000000000000000#m</guid>
+    </item></channel></rss>`))
+	}))
+	defer server.Close()
+
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	timeline, err := svc.GetUserTimeline(context.Background(), "jack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(timeline.Tweets) != 1 {
+		t.Fatalf("expected 1 tweet, got %#v", timeline.Tweets)
+	}
+	tweet := timeline.Tweets[0]
+	if tweet.ID != "1889000000000000000" || tweet.Author != "rob" || tweet.Text != "Über #golang go.dev/blog/go1.24" {
+		t.Fatalf("unexpected tweet %#v", tweet)
+	}
+	if h := tweet.Entities.Hashtags; len(h) != 1 || h[0].Start != 5 || h[0].Tag != "golang" {
+		t.Fatalf("unexpected hashtags %#v", h)
+	}
+	if u := tweet.Entities.URLs; len(u) != 1 || u[0].Start != 13 || u[0].ExpandedURL != "https://go.dev/blog/go1.24" {
+		t.Fatalf("unexpected URLs %#v", u)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client(), callTimeout: 50 * time.Millisecond}
+	_, err := svc.GetUserTweetIDs(context.Background(), "user")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if code := apperror.HTTPStatusCode(err); code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", code)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsCanceled(t *testing.T) {
+	svc := &NitterService{instances: []string{"http://127.0.0.1:0"}, httpClient: http.DefaultClient}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := svc.GetUserTweetIDs(ctx, "user")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsSessionsExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`<div class="error-panel"><span>Instance has been rate limited.<br>Use another instance or try again later.</span></div>`))
+	}))
+	defer server.Close()
+
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "user")
+	if !errors.Is(err, apperror.ErrSessionsExhausted) {
+		t.Fatalf("expected sessions exhausted error, got %v", err)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsFailover(t *testing.T) {
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(nitterSampleRSS))
+	}))
+	defer healthy.Close()
+
+	svc := &NitterService{instances: []string{broken.URL, healthy.URL}, httpClient: http.DefaultClient}
+	ids, err := svc.GetUserTweetIDs(context.Background(), "user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 tweet IDs, got %d", len(ids))
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsNoFailoverOnNotFound(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	svc := &NitterService{instances: []string{server.URL, server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "missing")
+	var nfErr *apperror.NotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsSuspended(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<div class="error-panel"><span>User "bad" has been suspended</span></div>`))
+	}))
+	defer server.Close()
+
+	svc := &NitterService{instances: []string{server.URL, server.URL}, httpClient: server.Client()}
+	_, err := svc.GetUserTweetIDs(context.Background(), "bad")
+	if !errors.Is(err, apperror.ErrSuspended) || apperror.Class(err) != apperror.ClassSuspended {
+		t.Fatalf("expected suspended error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestNitterServiceGetUserTweetIDsSkipsOpenCircuit(t *testing.T) {
+	brokenCalls := 0
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenCalls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(nitterSampleRSS))
+	}))
+	defer healthy.Close()
+
+	svc := NewNitterService([]string{broken.URL, healthy.URL},
+		WithTimelineCacheTTL(0),
+		WithRetryPolicy(httpclient.RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(1, time.Minute),
+	)
+	for i := 0; i < 3; i++ {
+		if _, err := svc.GetUserTweetIDs(context.Background(), "user"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if brokenCalls != 1 {
+		t.Fatalf("expected the open breaker to skip the broken instance, got %d calls", brokenCalls)
+	}
+	if state := svc.BreakerState(broken.URL); state != httpclient.StateOpen {
+		t.Fatalf("expected open breaker, got %s", state)
+	}
+}
+
+func TestNitterServiceGetUserTimelinePage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user/rss" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Min-Id", "page-2")
+			_, _ = w.Write([]byte(nitterSampleRSS))
+			return
+		}
+		w.Header().Set("Min-Id", "page-3")
+		_, _ = w.Write([]byte(`<rss><channel></channel></rss>`))
+	}))
+	defer server.Close()
+
+	svc := NewNitterService([]string{server.URL})
+	first, err := svc.GetUserTimelinePage(context.Background(), "user", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.NextCursor != "page-2" || len(first.TweetIDs) != 2 {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	last, err := svc.GetUserTimelinePage(context.Background(), "user", first.NextCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last.NextCursor != "" || len(last.TweetIDs) != 0 {
+		t.Fatalf("expected an empty last page, got %+v", last)
+	}
+}
+
+func TestNitterServiceSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/rss" || r.URL.Query().Get("q") != "#golang" || r.URL.Query().Get("f") != "tweets" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(nitterSampleRSS))
+	}))
+	defer server.Close()
+
+	svc := NewNitterService([]string{server.URL})
+	timeline, err := svc.Search(context.Background(), "#golang", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(timeline.TweetIDs) != 2 {
+		t.Fatalf("expected 2 tweet IDs, got %v", timeline.TweetIDs)
+	}
+
+	_, err = svc.Search(context.Background(), " ", "")
+	var vErr *apperror.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
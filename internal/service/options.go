@@ -0,0 +1,136 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"twitterx-api/internal/httpclient"
+	"twitterx-api/internal/metrics"
+	"twitterx-api/internal/ratelimit"
+)
+
+// Default cache lifetimes per resource type
+const (
+	DefaultTweetCacheTTL    = 5 * time.Minute
+	DefaultUserCacheTTL     = 5 * time.Minute
+	DefaultTimelineCacheTTL = time.Minute
+
+	defaultCacheEntries = 10000
+
+	// DefaultBreakerThreshold is the number of consecutive failed calls
+	// after which an upstream is no longer called for DefaultBreakerOpenTimeout
+	DefaultBreakerThreshold   = 5
+	DefaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// Option configures optional behavior of the services
+type Option func(*options)
+
+type options struct {
+	metrics     metrics.Recorder
+	limiter     *ratelimit.Concurrency
+	tweetTTL    time.Duration
+	userTTL     time.Duration
+	timelineTTL time.Duration
+
+	retry              httpclient.RetryPolicy
+	breakerThreshold   int
+	breakerOpenTimeout time.Duration
+
+	transport    http.RoundTripper
+	fxTwitterURL string
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		metrics:     metrics.Nop{},
+		tweetTTL:    DefaultTweetCacheTTL,
+		userTTL:     DefaultUserCacheTTL,
+		timelineTTL: DefaultTimelineCacheTTL,
+
+		retry:              httpclient.DefaultRetryPolicy,
+		breakerThreshold:   DefaultBreakerThreshold,
+		breakerOpenTimeout: DefaultBreakerOpenTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMetrics reports upstream calls and cache lookups to m
+func WithMetrics(m metrics.Recorder) Option {
+	return func(o *options) {
+		o.metrics = metrics.OrNop(m)
+	}
+}
+
+// WithTweetCacheTTL sets how long tweets are cached (0 disables caching)
+func WithTweetCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.tweetTTL = ttl
+	}
+}
+
+// WithUserCacheTTL sets how long user profiles are cached (0 disables caching)
+func WithUserCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.userTTL = ttl
+	}
+}
+
+// WithTimelineCacheTTL sets how long timelines are cached (0 disables caching)
+func WithTimelineCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.timelineTTL = ttl
+	}
+}
+
+// WithConcurrencyLimit allows at most limit simultaneous upstream calls.
+// Up to maxQueue further calls wait for a free slot for at most maxWait
+// before failing with a rate limit error.
+func WithConcurrencyLimit(limit, maxQueue int, maxWait time.Duration) Option {
+	return func(o *options) {
+		o.limiter = ratelimit.NewConcurrency(limit, maxQueue, maxWait)
+	}
+}
+
+// WithRetryPolicy sets how failed upstream calls are retried
+func WithRetryPolicy(policy httpclient.RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithCircuitBreaker stops calling an upstream for openTimeout after
+// threshold consecutive failures (a threshold of 0 disables the breaker)
+func WithCircuitBreaker(threshold int, openTimeout time.Duration) Option {
+	return func(o *options) {
+		o.breakerThreshold = threshold
+		o.breakerOpenTimeout = openTimeout
+	}
+}
+
+// WithTransport sends upstream requests through rt, typically the shared
+// transport built by httpclient.NewTransport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithFxTwitterURL points the FxTwitter service at a self-hosted API
+func WithFxTwitterURL(baseURL string) Option {
+	return func(o *options) {
+		o.fxTwitterURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// newBreaker creates the circuit breaker of an upstream instance and
+// reports its state changes to the metrics recorder
+func (o options) newBreaker(service, instance string) *httpclient.Breaker {
+	return httpclient.NewBreaker(o.breakerThreshold, o.breakerOpenTimeout, func(state string) {
+		o.metrics.SetCircuitState(service, instance, state)
+	})
+}
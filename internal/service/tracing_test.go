@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
+
+func spanAttr(span tracetest.SpanStub, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestNitterServiceGetUserTweetIDsSpan(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(nitterSampleRSS))
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "handler")
+	svc := &NitterService{instances: []string{server.URL}, httpClient: server.Client()}
+	if _, err := svc.GetUserTweetIDs(ctx, "user"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "Nitter GET /{username}/rss" || span.SpanKind != trace.SpanKindClient {
+		t.Fatalf("unexpected span: %s (%v)", span.Name, span.SpanKind)
+	}
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("expected upstream span to be a child of the handler span")
+	}
+	if v, _ := spanAttr(span, "twitterx.username"); v.AsString() != "user" {
+		t.Fatalf("unexpected username attribute: %v", v)
+	}
+	if v, _ := spanAttr(span, "http.response.status_code"); v.AsInt64() != http.StatusOK {
+		t.Fatalf("unexpected status attribute: %v", v)
+	}
+	if v, _ := spanAttr(span, "http.response.body.size"); v.AsInt64() != int64(len(nitterSampleRSS)) {
+		t.Fatalf("unexpected body size attribute: %v", v)
+	}
+
+	wantTrace := span.SpanContext.TraceID().String()
+	if len(traceparent) < 36 || traceparent[3:35] != wantTrace {
+		t.Fatalf("expected traceparent with trace %s, got %q", wantTrace, traceparent)
+	}
+}
+
+func TestFxTwitterServiceGetTweetDataErrorSpan(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	svc := &FxTwitterService{httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"code":404,"message":"NOT_FOUND"}`)),
+			Header:     make(http.Header),
+		}, nil
+	})}}
+	if _, err := svc.GetTweetData(context.Background(), "user", "123"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if v, _ := spanAttr(spans[0], "twitterx.tweet_id"); v.AsString() != "123" {
+		t.Fatalf("unexpected tweet ID attribute: %v", v)
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Fatalf("expected error status, got %v", spans[0].Status)
+	}
+}
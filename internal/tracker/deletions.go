@@ -0,0 +1,444 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/models"
+	"twitterx-api/pkg/twitterx"
+)
+
+// DefaultRecheckWindow is how long the tweets of watched accounts are
+// rechecked after they were posted
+const DefaultRecheckWindow = 7 * 24 * time.Hour
+
+// deletionMisses is how many rechecks in a row must miss a tweet before it
+// counts as deleted, so a single spurious NOT_FOUND is not a deletion
+const deletionMisses = 2
+
+// subscriberBuffer is how many deletions a slow subscriber may fall behind
+// before events are dropped
+const subscriberBuffer = 16
+
+// WithRecheckWindow sets how long a DeletionTracker rechecks tweets after
+// they were posted
+func WithRecheckWindow(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.window = d
+		}
+	}
+}
+
+// DeletedTweet is a tweet of a watched account that was deleted, with its
+// last known content. It is also the record kept in the journal.
+type DeletedTweet struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	// LastSeen is the last time the tweet was fetched, or the time it was
+	// first listed in the feed
+	LastSeen   time.Time `json:"last_seen"`
+	DetectedAt time.Time `json:"detected_at"`
+	// Text and Entities are from the Nitter feed
+	Text     string           `json:"text"`
+	Entities *models.Entities `json:"entities,omitempty"`
+	// Tweet is the last copy fetched from FxTwitter, missing when the tweet
+	// was deleted before it could be fetched
+	Tweet *models.Tweet `json:"tweet,omitempty"`
+}
+
+// Deletions are the deleted tweets of a watched account
+type Deletions struct {
+	Username string `json:"username"`
+	// Tweets are the most recently detected first
+	Tweets []DeletedTweet `json:"tweets"`
+}
+
+// recentTweet is a tweet of a watched account that still existed when it
+// was last checked
+type recentTweet struct {
+	id        string
+	username  string
+	createdAt time.Time
+	text      string
+	entities  *models.Entities
+	tweet     *models.Tweet
+	lastSeen  time.Time
+	// misses counts the last rechecks in a row that didn't find the tweet
+	misses int
+}
+
+// recentRecord is a recent tweet as kept in the journal of the recent
+// tweets, so they are still rechecked after a restart once they left the
+// feed
+type recentRecord struct {
+	ID        string           `json:"id"`
+	Username  string           `json:"username"`
+	CreatedAt time.Time        `json:"created_at"`
+	Text      string           `json:"text"`
+	Entities  *models.Entities `json:"entities,omitempty"`
+	Tweet     *models.Tweet    `json:"tweet,omitempty"`
+	LastSeen  time.Time        `json:"last_seen"`
+	Misses    int              `json:"misses,omitempty"`
+}
+
+// subscriber receives the deletions of key, or of every account for an
+// empty key
+type subscriber struct {
+	key    string
+	events chan DeletedTweet
+}
+
+// DeletionTracker detects the deletions of the recent tweets of watched
+// accounts. Tweets listed in their Nitter feeds are remembered with their
+// content and fetched again from FxTwitter every interval until they
+// outgrow the recheck window. A tweet FxTwitter no longer finds at two
+// rechecks in a row counts as deleted, and only while its account is still
+// public: tweets hidden by a protected or suspended account are checked
+// again later. The recent tweets are saved after every poll next to the
+// deletions, so a restart resumes their rechecks. A nil *DeletionTracker
+// tracks nobody.
+type DeletionTracker struct {
+	client    *twitterx.Client
+	usernames map[string]string
+	interval  time.Duration
+	window    time.Duration
+	now       func() time.Time
+	journal   *journal
+	// recentJournal holds the recent tweets as of the last poll
+	recentJournal *journal
+
+	mu          sync.RWMutex
+	recent      map[string]*recentTweet
+	deleted     map[string][]DeletedTweet
+	deletedIDs  map[string]bool
+	subscribers map[*subscriber]struct{}
+}
+
+// NewDeletionTracker watches the tweets of usernames, loading the deletions
+// and the recent tweets of the journals in the data directory
+func NewDeletionTracker(client *twitterx.Client, usernames []string, opts ...Option) (*DeletionTracker, error) {
+	o := newOptions(opts)
+	t := &DeletionTracker{
+		client:      client,
+		usernames:   make(map[string]string, len(usernames)),
+		interval:    o.interval,
+		window:      o.window,
+		now:         o.now,
+		recent:      make(map[string]*recentTweet),
+		deleted:     make(map[string][]DeletedTweet),
+		deletedIDs:  make(map[string]bool),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	for _, username := range usernames {
+		username = strings.TrimPrefix(username, "@")
+		if key := normalize(username); key != "" && t.usernames[key] == "" {
+			t.usernames[key] = username
+		}
+	}
+
+	var err error
+	t.journal, err = openJournal(o.dataDir, "deletions.jsonl", func(data []byte) error {
+		var rec DeletedTweet
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		// Accounts no longer watched are dropped
+		if key := normalize(rec.Username); t.usernames[key] != "" && !t.deletedIDs[rec.ID] {
+			t.deleted[key] = append(t.deleted[key], rec)
+			t.deletedIDs[rec.ID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening the deletion journal: %w", err)
+	}
+	t.recentJournal, err = openJournal(o.dataDir, "recent.jsonl", func(data []byte) error {
+		var rec recentRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if t.usernames[normalize(rec.Username)] != "" && !t.deletedIDs[rec.ID] {
+			t.recent[rec.ID] = &recentTweet{
+				id:        rec.ID,
+				username:  rec.Username,
+				createdAt: rec.CreatedAt,
+				text:      rec.Text,
+				entities:  rec.Entities,
+				tweet:     rec.Tweet,
+				lastSeen:  rec.LastSeen,
+				misses:    rec.Misses,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.journal.Close()
+		return nil, fmt.Errorf("opening the recent tweet journal: %w", err)
+	}
+	return t, nil
+}
+
+// Run polls now and then every interval until ctx is done
+func (t *DeletionTracker) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		t.Poll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll reads the feed of every watched account and rechecks its recent
+// tweets, then saves them. The tweets of an account whose feed fails are
+// rechecked at the next poll.
+func (t *DeletionTracker) Poll(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	defer func() {
+		if err := t.saveRecent(); err != nil {
+			logger.WarnContext(ctx, "Saving the recent tweets failed: %v", err)
+		}
+	}()
+	for _, key := range slices.Sorted(maps.Keys(t.usernames)) {
+		if ctx.Err() != nil {
+			return
+		}
+		username := t.usernames[key]
+		if err := t.discover(ctx, username); err != nil {
+			if errors.Is(err, twitterx.ErrSuspended) {
+				logger.InfoContext(ctx, "Account %s is suspended, not rechecking its tweets", username)
+			} else {
+				logger.WarnContext(ctx, "Reading the feed of %s failed: %v", username, err)
+			}
+			continue
+		}
+		for _, tweet := range t.pending(key) {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := t.recheck(ctx, tweet); err != nil {
+				logger.WarnContext(ctx, "Recheck of tweet %s failed: %v", tweet.id, err)
+			}
+		}
+	}
+}
+
+// discover remembers the tweets of the feed of username that are young
+// enough for the window, and forgets the ones that outgrew it
+func (t *DeletionTracker) discover(ctx context.Context, username string) error {
+	timeline, err := t.client.GetTimeline(ctx, username)
+	if err != nil {
+		return err
+	}
+	now := t.now().UTC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range timeline.Tweets {
+		createdAt, ok := twitterx.TweetTime(item.ID)
+		if item.RetweetedBy != "" || !ok || now.Sub(createdAt) >= t.window || t.deletedIDs[item.ID] {
+			continue
+		}
+		// Nitter caches its feeds, so being listed only proves the tweet
+		// existed when it was first seen
+		tweet := t.recent[item.ID]
+		if tweet == nil {
+			tweet = &recentTweet{id: item.ID, username: username, createdAt: createdAt, lastSeen: now}
+			t.recent[item.ID] = tweet
+		}
+		tweet.text, tweet.entities = item.Text, item.Entities
+	}
+	for id, tweet := range t.recent {
+		if now.Sub(tweet.createdAt) >= t.window {
+			delete(t.recent, id)
+		}
+	}
+	return nil
+}
+
+// saveRecent replaces the journal of the recent tweets with the current ones
+func (t *DeletionTracker) saveRecent() error {
+	t.mu.RLock()
+	records := make([]any, 0, len(t.recent))
+	for _, id := range slices.SortedFunc(maps.Keys(t.recent), compareIDs) {
+		tweet := t.recent[id]
+		records = append(records, recentRecord{
+			ID:        tweet.id,
+			Username:  tweet.username,
+			CreatedAt: tweet.createdAt,
+			Text:      tweet.text,
+			Entities:  tweet.entities,
+			Tweet:     tweet.tweet,
+			LastSeen:  tweet.lastSeen,
+			Misses:    tweet.misses,
+		})
+	}
+	t.mu.RUnlock()
+	return t.recentJournal.rewrite(records)
+}
+
+// pending returns the recent tweets of the account key, oldest first
+func (t *DeletionTracker) pending(key string) []*recentTweet {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var pending []*recentTweet
+	for _, id := range slices.SortedFunc(maps.Keys(t.recent), compareIDs) {
+		if tweet := t.recent[id]; normalize(tweet.username) == key {
+			pending = append(pending, tweet)
+		}
+	}
+	return pending
+}
+
+// recheck fetches tweet again, past the caches, and records its deletion
+// when FxTwitter no longer finds it at deletionMisses rechecks in a row and
+// its account is still public
+func (t *DeletionTracker) recheck(ctx context.Context, tweet *recentTweet) error {
+	fetched, err := t.client.FetchTweet(ctx, tweet.username, tweet.id)
+	switch {
+	case err == nil:
+		t.mu.Lock()
+		tweet.tweet, tweet.lastSeen, tweet.misses = fetched, t.now().UTC(), 0
+		t.mu.Unlock()
+		return nil
+	case errors.Is(err, twitterx.ErrProtected):
+		logger.DebugContext(ctx, "Tweet %s is hidden, %s is protected", tweet.id, tweet.username)
+		t.resetMisses(tweet)
+		return nil
+	case !twitterx.IsNotFound(err):
+		return err
+	}
+
+	// Without its account the tweet is hidden rather than deleted
+	user, err := t.client.FetchUser(ctx, tweet.username)
+	switch {
+	case twitterx.IsNotFound(err):
+		logger.DebugContext(ctx, "Tweet %s is hidden with its account: %v", tweet.id, err)
+		t.resetMisses(tweet)
+		return nil
+	case err != nil:
+		return err
+	case user.Protected:
+		logger.DebugContext(ctx, "Tweet %s is hidden, %s is protected", tweet.id, tweet.username)
+		t.resetMisses(tweet)
+		return nil
+	}
+
+	t.mu.Lock()
+	tweet.misses++
+	misses := tweet.misses
+	t.mu.Unlock()
+	if misses < deletionMisses {
+		logger.DebugContext(ctx, "Tweet %s of %s not found, checking again at the next poll", tweet.id, tweet.username)
+		return nil
+	}
+	return t.record(ctx, tweet)
+}
+
+// resetMisses starts the count of misses of tweet over, as a tweet hidden
+// with its account says nothing about its deletion
+func (t *DeletionTracker) resetMisses(tweet *recentTweet) {
+	t.mu.Lock()
+	tweet.misses = 0
+	t.mu.Unlock()
+}
+
+// record keeps the deletion of tweet and sends it to the subscribers
+func (t *DeletionTracker) record(ctx context.Context, tweet *recentTweet) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := DeletedTweet{
+		ID:         tweet.id,
+		Username:   tweet.username,
+		CreatedAt:  tweet.createdAt,
+		LastSeen:   tweet.lastSeen,
+		DetectedAt: t.now().UTC(),
+		Text:       tweet.text,
+		Entities:   tweet.entities,
+		Tweet:      tweet.tweet,
+	}
+	key := normalize(tweet.username)
+	t.deleted[key] = append(t.deleted[key], rec)
+	t.deletedIDs[tweet.id] = true
+	delete(t.recent, tweet.id)
+	logger.InfoContext(ctx, "Tweet %s of %s was deleted", tweet.id, tweet.username)
+
+	for sub := range t.subscribers {
+		if sub.key != "" && sub.key != key {
+			continue
+		}
+		select {
+		case sub.events <- rec:
+		default:
+			logger.WarnContext(ctx, "Dropped the deletion of tweet %s for a slow subscriber", tweet.id)
+		}
+	}
+	return t.journal.append(rec)
+}
+
+// Deleted returns the deleted tweets of username
+func (t *DeletionTracker) Deleted(username string) (*Deletions, error) {
+	if t == nil || t.usernames[normalize(username)] == "" {
+		return nil, notTracked(username)
+	}
+	key := normalize(username)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	d := &Deletions{Username: t.usernames[key], Tweets: make([]DeletedTweet, 0, len(t.deleted[key]))}
+	for _, rec := range slices.Backward(t.deleted[key]) {
+		d.Tweets = append(d.Tweets, rec)
+	}
+	return d, nil
+}
+
+// Subscribe returns a channel receiving the deletions of username detected
+// from now on, or those of every watched account for an empty username, and
+// a function ending the subscription. Deletions are dropped for a
+// subscriber that falls behind. A nil tracker accepts subscriptions to
+// every account but sends nothing.
+func (t *DeletionTracker) Subscribe(username string) (<-chan DeletedTweet, func(), error) {
+	key := normalize(username)
+	if key != "" && (t == nil || t.usernames[key] == "") {
+		return nil, nil, notTracked(username)
+	}
+	sub := &subscriber{key: key, events: make(chan DeletedTweet, subscriberBuffer)}
+	if t == nil {
+		return sub.events, func() {}, nil
+	}
+
+	t.mu.Lock()
+	t.subscribers[sub] = struct{}{}
+	t.mu.Unlock()
+	return sub.events, func() {
+		t.mu.Lock()
+		delete(t.subscribers, sub)
+		t.mu.Unlock()
+	}, nil
+}
+
+// Close closes the journals
+func (t *DeletionTracker) Close() error {
+	if t == nil {
+		return nil
+	}
+	return errors.Join(t.journal.Close(), t.recentJournal.Close())
+}
@@ -0,0 +1,257 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/pkg/twitterx"
+)
+
+func TestDeletionTracker(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	gone, kept := snowflake(start.Add(-time.Hour)), snowflake(start.Add(-2*time.Hour))
+	old, retweet := snowflake(start.Add(-8*24*time.Hour)), snowflake(start.Add(-time.Minute))
+
+	var suspended, deleted, protected, unlisted atomic.Bool
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if suspended.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<div class="error-panel"><span>User "jack" has been suspended</span></div>`))
+			return
+		}
+		item := fmt.Sprintf(`<item><title>bye #now</title><description>&lt;p&gt;bye #now&lt;/p&gt;</description><guid>https://nitter.net/jack/status/%s#m</guid></item>`, gone)
+		if unlisted.Load() {
+			item = ""
+		}
+		fmt.Fprintf(w, `<rss><channel>
+<item><title>RT by @jack: hi</title><guid>https://nitter.net/ev/status/%s#m</guid></item>
+%s
+<item><title>stays</title><guid>https://nitter.net/jack/status/%s#m</guid></item>
+<item><title>old</title><guid>https://nitter.net/jack/status/%s#m</guid></item>
+</channel></rss>`, retweet, item, kept, old)
+	}))
+	defer nitter.Close()
+	var fetches atomic.Int32
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(r.URL.Path) {
+		case "/jack":
+			fmt.Fprintf(w, `{"code":200,"message":"OK","user":{"screen_name":"jack","protected":%t}}`, protected.Load())
+			return
+		case "/jack/status/" + kept:
+			fetches.Add(1)
+			fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"stays"}}`, kept)
+			return
+		case "/jack/status/" + gone:
+			fetches.Add(1)
+			if !deleted.Load() {
+				fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"bye #now","likes":3}}`, gone)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+	}))
+	defer fx.Close()
+	client, err := twitterx.New(
+		twitterx.WithNitterInstances(nitter.URL),
+		twitterx.WithFxTwitterURL(fx.URL),
+		twitterx.WithTweetCacheTTL(0),
+		twitterx.WithUserCacheTTL(0),
+		twitterx.WithTimelineCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	defer client.Close()
+
+	clock := &manualClock{t: start}
+	dir := t.TempDir()
+	tracker, err := NewDeletionTracker(client, []string{"@Jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewDeletionTracker: %v", err)
+	}
+	events, unsubscribe, err := tracker.Subscribe("jack")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer unsubscribe()
+	ctx := context.Background()
+	tracker.Poll(ctx)
+	if fetches.Load() != 2 {
+		t.Fatalf("expected the 2 recent tweets of jack to be fetched, got %d fetches", fetches.Load())
+	}
+
+	// Hidden tweets are not deleted ones
+	deleted.Store(true)
+	suspended.Store(true)
+	clock.t = start.Add(15 * time.Minute)
+	tracker.Poll(ctx)
+	if fetches.Load() != 2 {
+		t.Fatalf("expected no recheck while jack is suspended, got %d fetches", fetches.Load())
+	}
+	suspended.Store(false)
+	protected.Store(true)
+	tracker.Poll(ctx)
+	if d, _ := tracker.Deleted("jack"); len(d.Tweets) != 0 {
+		t.Fatalf("expected no deletion while jack is protected, got %+v", d.Tweets)
+	}
+
+	// A single miss is not a deletion
+	protected.Store(false)
+	clock.t = start.Add(30 * time.Minute)
+	tracker.Poll(ctx)
+	if d, _ := tracker.Deleted("jack"); len(d.Tweets) != 0 {
+		t.Fatalf("expected no deletion after a single miss, got %+v", d.Tweets)
+	}
+
+	// The recent tweets survive a restart, even once out of the feed
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	unlisted.Store(true)
+	tracker, err = NewDeletionTracker(client, []string{"@Jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	events, unsubscribe, err = tracker.Subscribe("jack")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer unsubscribe()
+	clock.t = start.Add(45 * time.Minute)
+	tracker.Poll(ctx)
+	d, err := tracker.Deleted("JACK")
+	if err != nil {
+		t.Fatalf("Deleted: %v", err)
+	}
+	if d.Username != "Jack" || len(d.Tweets) != 1 {
+		t.Fatalf("expected 1 deleted tweet of Jack, got %+v", d)
+	}
+	rec := d.Tweets[0]
+	if rec.ID != gone || rec.Text != "bye #now" || rec.Entities == nil || len(rec.Entities.Hashtags) != 1 {
+		t.Fatalf("expected the content of the feed, got %+v", rec)
+	}
+	if rec.Tweet == nil || rec.Tweet.Likes != 3 || !rec.LastSeen.Equal(start) || !rec.DetectedAt.Equal(clock.t) {
+		t.Fatalf("expected the last fetched copy, got %+v", rec)
+	}
+	select {
+	case event := <-events:
+		if event.ID != gone {
+			t.Fatalf("expected an event for tweet %s, got %+v", gone, event)
+		}
+	default:
+		t.Fatal("expected a deletion event")
+	}
+
+	// Deletions are detected once and survive a restart
+	clock.t = start.Add(time.Hour)
+	tracker.Poll(ctx)
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reopened, err := NewDeletionTracker(client, []string{"jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer reopened.Close()
+	reopened.Poll(ctx)
+	if d, _ := reopened.Deleted("jack"); len(d.Tweets) != 1 || d.Tweets[0].Tweet == nil {
+		t.Fatalf("expected the deletion from the journal, got %+v", d)
+	}
+}
+
+func TestDeletionTrackerBypassesCache(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	id := snowflake(start.Add(-time.Hour))
+
+	var deleted, protected atomic.Bool
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<rss><channel><item><title>oops</title><guid>https://nitter.net/jack/status/%s#m</guid></item></channel></rss>`, id)
+	}))
+	defer nitter.Close()
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(r.URL.Path) {
+		case "/jack":
+			fmt.Fprintf(w, `{"code":200,"message":"OK","user":{"screen_name":"jack","protected":%t}}`, protected.Load())
+			return
+		case "/jack/status/" + id:
+			if !deleted.Load() {
+				fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"text":"oops"}}`, id)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+	}))
+	defer fx.Close()
+	client, err := twitterx.New(
+		twitterx.WithNitterInstances(nitter.URL),
+		twitterx.WithFxTwitterURL(fx.URL),
+		twitterx.WithTweetCacheTTL(time.Hour),
+		twitterx.WithUserCacheTTL(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	defer client.Close()
+
+	clock := &manualClock{t: start}
+	tracker, err := NewDeletionTracker(client, []string{"jack"}, withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewDeletionTracker: %v", err)
+	}
+	defer tracker.Close()
+	ctx := context.Background()
+
+	// The cached profile still says protected once jack went public again
+	protected.Store(true)
+	if _, err := client.GetUser(ctx, "jack"); err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	tracker.Poll(ctx)
+	protected.Store(false)
+	deleted.Store(true)
+	if _, err := client.GetTweet(ctx, "jack", id); err != nil {
+		t.Fatalf("expected the tweet to be cached, got %v", err)
+	}
+
+	clock.t = start.Add(time.Minute)
+	tracker.Poll(ctx)
+	clock.t = start.Add(2 * time.Minute)
+	tracker.Poll(ctx)
+	if d, _ := tracker.Deleted("jack"); len(d.Tweets) != 1 || d.Tweets[0].ID != id {
+		t.Fatalf("expected the deletion despite the caches, got %+v", d)
+	}
+}
+
+func TestDeletionTrackerNotTracked(t *testing.T) {
+	var nilTracker *DeletionTracker
+	var notFound *apperror.NotFoundError
+	if _, err := nilTracker.Deleted("jack"); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found from a nil tracker, got %v", err)
+	}
+	if _, _, err := nilTracker.Subscribe("jack"); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found from a nil tracker, got %v", err)
+	}
+	if _, cancel, err := nilTracker.Subscribe(""); err != nil {
+		t.Fatalf("expected a subscription to every account, got %v", err)
+	} else {
+		cancel()
+	}
+
+	tracker, err := NewDeletionTracker(nil, []string{"jack"})
+	if err != nil {
+		t.Fatalf("NewDeletionTracker: %v", err)
+	}
+	if _, err := tracker.Deleted("ev"); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found for an untracked user, got %v", err)
+	}
+}
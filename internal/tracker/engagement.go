@@ -0,0 +1,404 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"twitterx-api/internal/logger"
+	"twitterx-api/pkg/twitterx"
+)
+
+// Step is a stage of the schedule of EngagementTracker: tweets younger than
+// Age are fetched again every Every
+type Step struct {
+	Age   time.Duration
+	Every time.Duration
+}
+
+// DefaultSchedule fetches tweets every 5 minutes in their first hour, then
+// less and less often until they are a week old
+var DefaultSchedule = []Step{
+	{Age: time.Hour, Every: 5 * time.Minute},
+	{Age: 6 * time.Hour, Every: 15 * time.Minute},
+	{Age: 24 * time.Hour, Every: time.Hour},
+	{Age: 7 * 24 * time.Hour, Every: 6 * time.Hour},
+}
+
+// WithSchedule sets the schedule of an EngagementTracker, steps ordered by
+// age
+func WithSchedule(steps ...Step) Option {
+	return func(o *options) {
+		if len(steps) > 0 {
+			o.schedule = steps
+		}
+	}
+}
+
+// every returns how often a tweet of age is fetched, and false once it has
+// outgrown the schedule
+func every(schedule []Step, age time.Duration) (time.Duration, bool) {
+	for _, step := range schedule {
+		if age < step.Age {
+			return step.Every, true
+		}
+	}
+	return 0, false
+}
+
+// TweetMetrics are the engagement curves of a tracked tweet
+type TweetMetrics struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	// Tracking is false once the tweet was deleted. Tweets that outgrew the
+	// schedule are forgotten.
+	Tracking bool   `json:"tracking"`
+	Replies  Growth `json:"replies"`
+	Retweets Growth `json:"retweets"`
+	Likes    Growth `json:"likes"`
+	// Views has no points when FxTwitter doesn't count the views
+	Views Growth `json:"views"`
+}
+
+// Growth is the curve of an engagement count. Velocities are in units per
+// hour.
+type Growth struct {
+	Latest int64 `json:"latest"`
+	// Velocity is the growth rate since the previous snapshot
+	Velocity     float64       `json:"velocity"`
+	PeakVelocity float64       `json:"peak_velocity"`
+	Points       []GrowthPoint `json:"points"`
+}
+
+// GrowthPoint is a snapshot of an engagement count
+type GrowthPoint struct {
+	Time time.Time `json:"time"`
+	// AgeSeconds is the age of the tweet at Time
+	AgeSeconds int64 `json:"age_seconds"`
+	Value      int64 `json:"value"`
+	// Velocity is the growth rate since the previous point, or since the
+	// creation of the tweet for the first one
+	Velocity float64 `json:"velocity"`
+}
+
+// engagementRecord is a snapshot of a tweet as kept in the journal
+type engagementRecord struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	Time      time.Time `json:"time"`
+	Replies   int64     `json:"replies"`
+	Retweets  int64     `json:"retweets"`
+	Likes     int64     `json:"likes"`
+	Views     *int64    `json:"views,omitempty"`
+}
+
+// trackedTweet is the state of a tweet of a tracked account
+type trackedTweet struct {
+	id        string
+	username  string
+	createdAt time.Time
+	snapshots []engagementRecord
+	// deleted stops the fetches
+	deleted bool
+}
+
+// EngagementTracker follows the engagement counts of the recent tweets of
+// watched accounts. New tweets are found in the timelines and fetched on a
+// decaying schedule, dense while they are young and sparse later; retweets
+// of other accounts are left out. Tweets that outgrow the schedule are
+// forgotten, deleted ones included, and the journal is compacted once most
+// of its records are of forgotten tweets. A nil *EngagementTracker tracks
+// nothing.
+type EngagementTracker struct {
+	client    *twitterx.Client
+	usernames []string
+	schedule  []Step
+	now       func() time.Time
+	journal   *journal
+
+	mu     sync.RWMutex
+	tweets map[string]*trackedTweet
+	// live and dead count the records of the journal of tracked and of
+	// forgotten tweets
+	live, dead int
+}
+
+// NewEngagementTracker tracks the tweets of usernames, loading the
+// snapshots of the journal in the data directory
+func NewEngagementTracker(client *twitterx.Client, usernames []string, opts ...Option) (*EngagementTracker, error) {
+	o := newOptions(opts)
+	t := &EngagementTracker{
+		client:   client,
+		schedule: o.schedule,
+		now:      o.now,
+		tweets:   make(map[string]*trackedTweet),
+	}
+	tracked := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		if key := normalize(username); key != "" && !tracked[key] {
+			tracked[key] = true
+			t.usernames = append(t.usernames, strings.TrimPrefix(username, "@"))
+		}
+	}
+
+	var err error
+	t.journal, err = openJournal(o.dataDir, "engagement.jsonl", func(data []byte) error {
+		var rec engagementRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		// Tweets of accounts no longer watched are dropped
+		if tracked[normalize(rec.Username)] {
+			tweet := t.tweet(rec.ID, rec.Username, rec.CreatedAt)
+			tweet.snapshots = append(tweet.snapshots, rec)
+			t.live++
+		} else {
+			t.dead++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening the engagement journal: %w", err)
+	}
+	if err := t.prune(); err != nil {
+		t.journal.Close()
+		return nil, fmt.Errorf("compacting the engagement journal: %w", err)
+	}
+	return t, nil
+}
+
+// tweet returns the state of tweet id, adding it when it is new. The caller
+// holds the lock.
+func (t *EngagementTracker) tweet(id, username string, createdAt time.Time) *trackedTweet {
+	tweet := t.tweets[id]
+	if tweet == nil {
+		tweet = &trackedTweet{id: id, username: username, createdAt: createdAt}
+		t.tweets[id] = tweet
+	}
+	return tweet
+}
+
+// Run polls at the pace of the first step of the schedule until ctx is done
+func (t *EngagementTracker) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ticker := time.NewTicker(t.schedule[0].Every)
+	defer ticker.Stop()
+	for {
+		t.Poll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll looks for new tweets in the timelines of the tracked accounts and
+// fetches the tweets that are due. Failed fetches are retried at the next
+// poll.
+func (t *EngagementTracker) Poll(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	for _, username := range t.usernames {
+		if err := t.discover(ctx, username); err != nil {
+			logger.WarnContext(ctx, "Looking for new tweets of %s failed: %v", username, err)
+		}
+	}
+	if err := t.prune(); err != nil {
+		logger.WarnContext(ctx, "Compacting the engagement journal failed: %v", err)
+	}
+	for _, tweet := range t.due() {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := t.fetch(ctx, tweet); err != nil {
+			logger.WarnContext(ctx, "Engagement snapshot of tweet %s failed: %v", tweet.id, err)
+		}
+	}
+}
+
+// discover starts tracking the tweets of the timeline of username that are
+// young enough for the schedule
+func (t *EngagementTracker) discover(ctx context.Context, username string) error {
+	timeline, err := t.client.GetTimeline(ctx, username)
+	if err != nil {
+		return err
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range timeline.Tweets {
+		if item.RetweetedBy != "" {
+			continue
+		}
+		createdAt, ok := twitterx.TweetTime(item.ID)
+		if _, young := every(t.schedule, now.Sub(createdAt)); !ok || !young {
+			continue
+		}
+		t.tweet(item.ID, username, createdAt)
+	}
+	return nil
+}
+
+// prune forgets the tweets that outgrew the schedule, as the deletion
+// tracker does, and rewrites the journal once it holds more records of
+// forgotten tweets than of tracked ones
+func (t *EngagementTracker) prune() error {
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, tweet := range t.tweets {
+		if _, young := every(t.schedule, now.Sub(tweet.createdAt)); !young {
+			delete(t.tweets, id)
+			t.live -= len(tweet.snapshots)
+			t.dead += len(tweet.snapshots)
+		}
+	}
+	if t.dead == 0 || t.dead < t.live {
+		return nil
+	}
+
+	records := make([]any, 0, t.live)
+	for _, id := range slices.SortedFunc(maps.Keys(t.tweets), compareIDs) {
+		for _, rec := range t.tweets[id].snapshots {
+			records = append(records, rec)
+		}
+	}
+	if err := t.journal.rewrite(records); err != nil {
+		return err
+	}
+	t.dead = 0
+	return nil
+}
+
+// due returns the tweets whose next snapshot is due, oldest first
+func (t *EngagementTracker) due() []*trackedTweet {
+	now := t.now()
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var due []*trackedTweet
+	for _, id := range slices.SortedFunc(maps.Keys(t.tweets), compareIDs) {
+		tweet := t.tweets[id]
+		interval, ok := every(t.schedule, now.Sub(tweet.createdAt))
+		if tweet.deleted || !ok {
+			continue
+		}
+		if n := len(tweet.snapshots); n == 0 || now.Sub(tweet.snapshots[n-1].Time) >= interval {
+			due = append(due, tweet)
+		}
+	}
+	return due
+}
+
+// fetch records a snapshot of tweet. A tweet that no longer exists is not
+// fetched again. The tweet cache is bypassed: a cached copy could be as old
+// as the snapshot interval and skew the velocities.
+func (t *EngagementTracker) fetch(ctx context.Context, tweet *trackedTweet) error {
+	fetched, err := t.client.FetchTweet(ctx, tweet.username, tweet.id)
+	if twitterx.IsNotFound(err) {
+		logger.DebugContext(ctx, "Tweet %s is gone, no longer tracking it", tweet.id)
+		t.mu.Lock()
+		tweet.deleted = true
+		t.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rec := engagementRecord{
+		ID:        tweet.id,
+		Username:  tweet.username,
+		CreatedAt: tweet.createdAt,
+		Time:      t.now().UTC(),
+		Replies:   fetched.Replies,
+		Retweets:  fetched.Retweets,
+		Likes:     fetched.Likes,
+		Views:     fetched.Views,
+	}
+	t.mu.Lock()
+	tweet.snapshots = append(tweet.snapshots, rec)
+	t.live++
+	t.mu.Unlock()
+	return t.journal.append(rec)
+}
+
+// Metrics returns the engagement curves of tweet id
+func (t *EngagementTracker) Metrics(id string) (*TweetMetrics, error) {
+	if t == nil {
+		return nil, notTrackedTweet(id)
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tweet := t.tweets[id]
+	if tweet == nil {
+		return nil, notTrackedTweet(id)
+	}
+
+	_, young := every(t.schedule, t.now().Sub(tweet.createdAt))
+	m := &TweetMetrics{
+		ID:        tweet.id,
+		Username:  tweet.username,
+		CreatedAt: tweet.createdAt,
+		Tracking:  young && !tweet.deleted,
+	}
+	m.Replies = growth(tweet, func(r engagementRecord) (int64, bool) { return r.Replies, true })
+	m.Retweets = growth(tweet, func(r engagementRecord) (int64, bool) { return r.Retweets, true })
+	m.Likes = growth(tweet, func(r engagementRecord) (int64, bool) { return r.Likes, true })
+	m.Views = growth(tweet, func(r engagementRecord) (int64, bool) {
+		if r.Views == nil {
+			return 0, false
+		}
+		return *r.Views, true
+	})
+	return m, nil
+}
+
+// growth builds the curve of the count that value returns, skipping the
+// snapshots without it
+func growth(tweet *trackedTweet, value func(engagementRecord) (int64, bool)) Growth {
+	g := Growth{Points: []GrowthPoint{}}
+	prevTime, prevValue := tweet.createdAt, int64(0)
+	for _, rec := range tweet.snapshots {
+		v, ok := value(rec)
+		if !ok {
+			continue
+		}
+		p := GrowthPoint{Time: rec.Time, AgeSeconds: int64(rec.Time.Sub(tweet.createdAt) / time.Second), Value: v}
+		if hours := rec.Time.Sub(prevTime).Hours(); hours > 0 {
+			p.Velocity = float64(v-prevValue) / hours
+		}
+		g.Points = append(g.Points, p)
+		g.Latest, g.Velocity = v, p.Velocity
+		g.PeakVelocity = max(g.PeakVelocity, p.Velocity)
+		prevTime, prevValue = rec.Time, v
+	}
+	return g
+}
+
+// Close closes the journal
+func (t *EngagementTracker) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.journal.Close()
+}
+
+// compareIDs orders tweet IDs numerically
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,146 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/pkg/twitterx"
+)
+
+// manualClock returns the time it is set to
+type manualClock struct {
+	t time.Time
+}
+
+func (c *manualClock) now() time.Time {
+	return c.t
+}
+
+// snowflake returns a tweet ID created at t
+func snowflake(t time.Time) string {
+	return strconv.FormatInt((t.UnixMilli()-1288834974657)<<22, 10)
+}
+
+func TestEngagementTracker(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fresh, old, retweet := snowflake(start.Add(-10*time.Minute)), snowflake(start.Add(-8*24*time.Hour)), snowflake(start.Add(-time.Minute))
+
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<rss><channel>
+<item><title>RT by @jack: hi</title><guid>https://nitter.net/ev/status/%s#m</guid></item>
+<item><title>new</title><guid>https://nitter.net/jack/status/%s#m</guid></item>
+<item><title>old</title><guid>https://nitter.net/jack/status/%s#m</guid></item>
+</channel></rss>`, retweet, fresh, old)
+	}))
+	defer nitter.Close()
+	var fetches atomic.Int32
+	var deleted atomic.Bool
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jack/status/"+fresh || deleted.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+			return
+		}
+		n := fetches.Add(1)
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q,"likes":%d,"retweets":1}}`, fresh, 10*n)
+	}))
+	defer fx.Close()
+	// Snapshots bypass the tweet cache, which would serve the same counts
+	// for its whole TTL
+	client, err := twitterx.New(twitterx.WithNitterInstances(nitter.URL), twitterx.WithFxTwitterURL(fx.URL), twitterx.WithTweetCacheTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	defer client.Close()
+
+	clock := &manualClock{t: start}
+	dir := t.TempDir()
+	tracker, err := NewEngagementTracker(client, []string{"jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewEngagementTracker: %v", err)
+	}
+	ctx := context.Background()
+	tracker.Poll(ctx)
+	clock.t = start.Add(2 * time.Minute)
+	tracker.Poll(ctx)
+	if fetches.Load() != 1 {
+		t.Fatalf("expected 1 fetch before the next step, got %d", fetches.Load())
+	}
+	clock.t = start.Add(5 * time.Minute)
+	tracker.Poll(ctx)
+
+	metrics, err := tracker.Metrics(fresh)
+	if err != nil {
+		t.Fatalf("Metrics: %v", err)
+	}
+	likes := metrics.Likes
+	if !metrics.Tracking || len(likes.Points) != 2 || likes.Latest != 20 || likes.Points[1].AgeSeconds != 15*60 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	if math.Abs(likes.Points[0].Velocity-60) > 1e-9 || math.Abs(likes.Velocity-120) > 1e-9 || likes.PeakVelocity != likes.Velocity {
+		t.Fatalf("unexpected velocities %+v", likes)
+	}
+	if len(metrics.Views.Points) != 0 || metrics.Retweets.Velocity != 0 {
+		t.Fatalf("unexpected views or retweets %+v", metrics)
+	}
+
+	var notFound *apperror.NotFoundError
+	for _, id := range []string{old, retweet} {
+		if _, err := tracker.Metrics(id); !errors.As(err, &notFound) {
+			t.Fatalf("tweet %s must not be tracked, got %v", id, err)
+		}
+	}
+
+	// A deleted tweet keeps its curves but is no longer fetched
+	deleted.Store(true)
+	clock.t = start.Add(10 * time.Minute)
+	tracker.Poll(ctx)
+	if metrics, err := tracker.Metrics(fresh); err != nil || metrics.Tracking || len(metrics.Likes.Points) != 2 {
+		t.Fatalf("unexpected metrics of a deleted tweet %+v, %v", metrics, err)
+	}
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewEngagementTracker(client, []string{"jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewEngagementTracker: %v", err)
+	}
+	defer reopened.Close()
+	if metrics, err := reopened.Metrics(fresh); err != nil || metrics.Likes.Latest != 20 {
+		t.Fatalf("unexpected metrics after reopening %+v, %v", metrics, err)
+	}
+
+	// Tweets that outgrew the schedule are forgotten and leave the journal
+	clock.t = start.Add(8 * 24 * time.Hour)
+	reopened.Poll(ctx)
+	if _, err := reopened.Metrics(fresh); !errors.As(err, &notFound) {
+		t.Fatalf("expected an outgrown tweet to be forgotten, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "engagement.jsonl")); err != nil || len(data) != 0 {
+		t.Fatalf("expected an empty journal, got %q, %v", data, err)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	if d, ok := every(DefaultSchedule, 30*time.Minute); !ok || d != 5*time.Minute {
+		t.Fatalf("expected 5m in the first hour, got %s", d)
+	}
+	if d, ok := every(DefaultSchedule, 2*24*time.Hour); !ok || d != 6*time.Hour {
+		t.Fatalf("expected 6h after a day, got %s", d)
+	}
+	if _, ok := every(DefaultSchedule, 8*24*time.Hour); ok {
+		t.Fatal("tweets older than a week are not fetched")
+	}
+}
@@ -0,0 +1,137 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"twitterx-api/internal/logger"
+)
+
+// maxJournalLine bounds a journal record
+const maxJournalLine = 1 << 20
+
+// journal is an append-only file of JSON records, one per line, that can be
+// rewritten to drop the records no longer needed. A nil *journal is valid
+// and keeps nothing.
+type journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openJournal opens the journal name in dir, calling replay with every
+// record already in it. An empty dir returns a nil journal. Lines that
+// don't decode, like one cut short by a crash, are skipped.
+func openJournal(dir, name string, replay func(data []byte) error) (*journal, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, name)
+
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64<<10), maxJournalLine)
+		for line := 1; scanner.Scan(); line++ {
+			if err := replay(scanner.Bytes()); err != nil {
+				logger.Warn("Skipping record %d of %s: %v", line, path, err)
+			}
+		}
+		err := scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{file: file}, nil
+}
+
+// rewrite replaces the records of the journal with records. They are written
+// to a temporary file renamed over the journal, so a crash leaves either the
+// old or the new records.
+func (j *journal) rewrite(records []any) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	path := j.file.Name()
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file.Close()
+	j.file = file
+	return nil
+}
+
+// append writes record as a line
+func (j *journal) append(record any) error {
+	if j == nil {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file
+func (j *journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
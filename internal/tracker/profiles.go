@@ -0,0 +1,455 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/internal/logger"
+	"twitterx-api/internal/models"
+	"twitterx-api/pkg/twitterx"
+)
+
+// DefaultRetention is how long the snapshots of profiles are kept
+const DefaultRetention = 90 * 24 * time.Hour
+
+// WithRetention sets how long a ProfileTracker keeps snapshots
+func WithRetention(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.retention = d
+		}
+	}
+}
+
+// Profile counters a series can be requested for
+const (
+	MetricFollowers = "followers"
+	MetricFollowing = "following"
+	MetricTweets    = "tweets"
+	MetricLikes     = "likes"
+	MetricMedia     = "media"
+)
+
+// Counts are the counters of a profile at a point in time
+type Counts struct {
+	Followers int64 `json:"followers"`
+	Following int64 `json:"following"`
+	Tweets    int64 `json:"tweets"`
+	Likes     int64 `json:"likes"`
+	Media     int64 `json:"media"`
+}
+
+func (c Counts) metric(name string) (int64, bool) {
+	switch name {
+	case MetricFollowers:
+		return c.Followers, true
+	case MetricFollowing:
+		return c.Following, true
+	case MetricTweets:
+		return c.Tweets, true
+	case MetricLikes:
+		return c.Likes, true
+	case MetricMedia:
+		return c.Media, true
+	}
+	return 0, false
+}
+
+// Change is a field of a profile that differs from the previous snapshot.
+// Values are strings: booleans read "true" or "false" and a missing value,
+// like a removed website, is empty.
+type Change struct {
+	Time  time.Time `json:"time"`
+	Field string    `json:"field"`
+	Old   string    `json:"old"`
+	New   string    `json:"new"`
+}
+
+// History is the change log of a tracked profile
+type History struct {
+	Username string `json:"username"`
+	// TrackedSince is the time of the oldest snapshot kept
+	TrackedSince *time.Time `json:"tracked_since,omitempty"`
+	// Current holds the tracked fields of the latest snapshot
+	Current map[string]string `json:"current"`
+	// Changes are newest first
+	Changes []Change `json:"changes"`
+}
+
+// Series is a downsampled time series of a profile counter
+type Series struct {
+	Username string `json:"username"`
+	Metric   string `json:"metric"`
+	// ResolutionSeconds is the width of a point
+	ResolutionSeconds int64 `json:"resolution_seconds"`
+	// Change is the difference between the last and the first sample
+	Change int64   `json:"change"`
+	Points []Point `json:"points"`
+}
+
+// profileRecord is a snapshot as kept in the journal: the counts, and the
+// fields that changed since the previous snapshot
+type profileRecord struct {
+	Username string            `json:"username"`
+	Time     time.Time         `json:"time"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Counts   Counts            `json:"counts"`
+}
+
+// profile is the state of a tracked account
+type profile struct {
+	username string
+	// fields are the tracked fields of the latest snapshot, nil before the
+	// first one
+	fields  map[string]string
+	changes []Change
+	counts  []countsSample
+}
+
+type countsSample struct {
+	time   time.Time
+	counts Counts
+}
+
+// apply adds a snapshot. Fields seen for the first time are not changes.
+func (p *profile) apply(rec profileRecord) {
+	if p.fields == nil {
+		p.fields = make(map[string]string, len(rec.Fields))
+	}
+	for _, field := range slices.Sorted(maps.Keys(rec.Fields)) {
+		value := rec.Fields[field]
+		if old, ok := p.fields[field]; ok && old != value {
+			p.changes = append(p.changes, Change{Time: rec.Time, Field: field, Old: old, New: value})
+		}
+		p.fields[field] = value
+	}
+	p.counts = append(p.counts, countsSample{time: rec.Time, counts: rec.Counts})
+}
+
+// prune drops the samples older than cutoff but the last of them, which
+// holds the fields the newer changes start from, and the changes that are
+// not newer than it. It returns how many samples were dropped.
+func (p *profile) prune(cutoff time.Time) int {
+	i, _ := slices.BinarySearchFunc(p.counts, cutoff, func(s countsSample, t time.Time) int {
+		return s.time.Compare(t)
+	})
+	dropped := max(i-1, 0)
+	if dropped == 0 {
+		return 0
+	}
+	p.counts = slices.Delete(p.counts, 0, dropped)
+	first := p.counts[0].time
+	p.changes = slices.DeleteFunc(p.changes, func(c Change) bool {
+		return !c.Time.After(first)
+	})
+	return dropped
+}
+
+// records returns the snapshots of p as journal records. The first one
+// holds every field, the others the fields that changed.
+func (p *profile) records() []profileRecord {
+	if len(p.counts) == 0 {
+		return nil
+	}
+	fields := maps.Clone(p.fields)
+	for _, c := range slices.Backward(p.changes) {
+		fields[c.Field] = c.Old
+	}
+	records := make([]profileRecord, len(p.counts))
+	changes := p.changes
+	for i, s := range p.counts {
+		records[i] = profileRecord{Username: p.username, Time: s.time, Counts: s.counts}
+		if i == 0 {
+			records[i].Fields = fields
+			continue
+		}
+		for len(changes) > 0 && !changes[0].Time.After(s.time) {
+			if records[i].Fields == nil {
+				records[i].Fields = make(map[string]string)
+			}
+			records[i].Fields[changes[0].Field] = changes[0].New
+			changes = changes[1:]
+		}
+	}
+	return records
+}
+
+// diff returns the fields that differ from the latest snapshot
+func (p *profile) diff(fields map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for field, value := range fields {
+		if old, ok := p.fields[field]; !ok || old != value {
+			changed[field] = value
+		}
+	}
+	return changed
+}
+
+// profileFields returns the tracked fields of user
+func profileFields(u *models.User) map[string]string {
+	fields := map[string]string{
+		"id":          u.ID,
+		"screen_name": u.ScreenName,
+		"name":        u.Name,
+		"description": u.Description,
+		"location":    u.Location,
+		"website":     "",
+		"avatar_url":  u.AvatarURL,
+		"banner_url":  u.BannerURL,
+		"protected":   strconv.FormatBool(u.Protected),
+		"verified":    "false",
+
+		"verification_type": "",
+	}
+	if u.Website != nil {
+		fields["website"] = *u.Website
+	}
+	if u.Verification != nil {
+		fields["verified"] = strconv.FormatBool(u.Verification.Verified)
+		fields["verification_type"] = u.Verification.Type
+	}
+	return fields
+}
+
+// ProfileTracker snapshots the profiles of watched accounts. It records the
+// changes of their name, handle, bio, avatar, banner, website, protection,
+// verification and pinned tweet, and the series of their counts. Snapshots
+// older than the retention are dropped, and the journal is compacted once
+// most of its records are of dropped snapshots. A nil *ProfileTracker
+// tracks nobody.
+type ProfileTracker struct {
+	client    *twitterx.Client
+	interval  time.Duration
+	retention time.Duration
+	now       func() time.Time
+	journal   *journal
+
+	mu       sync.RWMutex
+	profiles map[string]*profile
+	// live and dead count the records of the journal of kept and of dropped
+	// snapshots
+	live, dead int
+}
+
+// NewProfileTracker tracks the profiles of usernames, loading the snapshots
+// of the journal in the data directory
+func NewProfileTracker(client *twitterx.Client, usernames []string, opts ...Option) (*ProfileTracker, error) {
+	o := newOptions(opts)
+	t := &ProfileTracker{
+		client:    client,
+		interval:  o.interval,
+		retention: o.retention,
+		now:       o.now,
+		profiles:  make(map[string]*profile, len(usernames)),
+	}
+	for _, username := range usernames {
+		username = strings.TrimPrefix(username, "@")
+		if key := normalize(username); key != "" && t.profiles[key] == nil {
+			t.profiles[key] = &profile{username: username}
+		}
+	}
+
+	var err error
+	t.journal, err = openJournal(o.dataDir, "profiles.jsonl", func(data []byte) error {
+		var rec profileRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		// Accounts no longer watched are dropped
+		if p := t.profiles[normalize(rec.Username)]; p != nil {
+			p.apply(rec)
+			t.live++
+		} else {
+			t.dead++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening the profile journal: %w", err)
+	}
+	if err := t.prune(); err != nil {
+		t.journal.Close()
+		return nil, fmt.Errorf("compacting the profile journal: %w", err)
+	}
+	return t, nil
+}
+
+// Run snapshots every profile now and then every interval until ctx is done
+func (t *ProfileTracker) Run(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		t.Snapshot(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Snapshot fetches every tracked profile once and records what changed.
+// Profiles that fail to load are retried at the next snapshot.
+func (t *ProfileTracker) Snapshot(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	for _, key := range slices.Sorted(maps.Keys(t.profiles)) {
+		if ctx.Err() != nil {
+			return
+		}
+		p := t.profiles[key]
+		if err := t.snapshot(ctx, p); err != nil {
+			logger.WarnContext(ctx, "Snapshot of the profile of %s failed: %v", p.username, err)
+		}
+	}
+	if err := t.prune(); err != nil {
+		logger.WarnContext(ctx, "Compacting the profile journal failed: %v", err)
+	}
+}
+
+// prune drops the snapshots that outgrew the retention and rewrites the
+// journal once it holds more records of dropped snapshots than of kept
+// ones, as the engagement tracker does
+func (t *ProfileTracker) prune() error {
+	cutoff := t.now().Add(-t.retention)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range t.profiles {
+		dropped := p.prune(cutoff)
+		t.live -= dropped
+		t.dead += dropped
+	}
+	if t.dead == 0 || t.dead < t.live {
+		return nil
+	}
+
+	records := make([]any, 0, t.live)
+	for _, key := range slices.Sorted(maps.Keys(t.profiles)) {
+		for _, rec := range t.profiles[key].records() {
+			records = append(records, rec)
+		}
+	}
+	if err := t.journal.rewrite(records); err != nil {
+		return err
+	}
+	t.dead = 0
+	return nil
+}
+
+func (t *ProfileTracker) snapshot(ctx context.Context, p *profile) error {
+	// Both bypass the caches, which would repeat stale samples
+	user, err := t.client.FetchUser(ctx, p.username)
+	if err != nil {
+		return err
+	}
+	fields := profileFields(user)
+	// Without the feed the pinned tweet is unknown rather than unpinned
+	if pinned, err := t.client.PinnedTweetID(ctx, p.username); err == nil {
+		fields["pinned_tweet_id"] = pinned
+	} else {
+		logger.DebugContext(ctx, "Pinned tweet of %s unknown: %v", p.username, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := profileRecord{
+		Username: p.username,
+		Time:     t.now().UTC(),
+		Fields:   p.diff(fields),
+		Counts: Counts{
+			Followers: user.Followers,
+			Following: user.Following,
+			Tweets:    user.Tweets,
+			Likes:     user.Likes,
+			Media:     user.MediaCount,
+		},
+	}
+	return t.add(p, rec)
+}
+
+// add applies the snapshot rec to p and journals it. The caller holds the
+// lock.
+func (t *ProfileTracker) add(p *profile, rec profileRecord) error {
+	p.apply(rec)
+	t.live++
+	return t.journal.append(rec)
+}
+
+// History returns the change log of username since since, or all of it for
+// a zero since
+func (t *ProfileTracker) History(username string, since time.Time) (*History, error) {
+	if t == nil || t.profiles[normalize(username)] == nil {
+		return nil, notTracked(username)
+	}
+	p := t.profiles[normalize(username)]
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	h := &History{Username: p.username, Current: maps.Clone(p.fields), Changes: []Change{}}
+	if h.Current == nil {
+		h.Current = map[string]string{}
+	}
+	if len(p.counts) > 0 {
+		first := p.counts[0].time
+		h.TrackedSince = &first
+	}
+	for _, c := range slices.Backward(p.changes) {
+		if c.Time.Before(since) {
+			break
+		}
+		h.Changes = append(h.Changes, c)
+	}
+	return h, nil
+}
+
+// Stats returns the series of metric for username since since, downsampled
+// to at most maxPoints points
+func (t *ProfileTracker) Stats(username, metric string, since time.Time, maxPoints int) (*Series, error) {
+	if _, ok := (Counts{}).metric(metric); !ok {
+		return nil, &apperror.ValidationError{Field: "metric", Message: fmt.Sprintf("unsupported metric %q", metric)}
+	}
+	if maxPoints < 1 || maxPoints > MaxPoints {
+		return nil, &apperror.ValidationError{Field: "points", Message: fmt.Sprintf("must be between 1 and %d", MaxPoints)}
+	}
+	if t == nil || t.profiles[normalize(username)] == nil {
+		return nil, notTracked(username)
+	}
+	p := t.profiles[normalize(username)]
+
+	t.mu.RLock()
+	var samples []sample
+	for _, s := range p.counts {
+		if !s.time.Before(since) {
+			value, _ := s.counts.metric(metric)
+			samples = append(samples, sample{time: s.time, value: value})
+		}
+	}
+	t.mu.RUnlock()
+
+	points, width := downsample(samples, maxPoints)
+	series := &Series{Username: p.username, Metric: metric, ResolutionSeconds: int64(width / time.Second), Points: points}
+	if len(samples) > 0 {
+		series.Change = samples[len(samples)-1].value - samples[0].value
+	}
+	return series, nil
+}
+
+// Close closes the journal
+func (t *ProfileTracker) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.journal.Close()
+}
@@ -0,0 +1,215 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/apperror"
+	"twitterx-api/pkg/twitterx"
+)
+
+// fakeClock advances by a minute on every call
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(time.Minute)
+	return c.t
+}
+
+// newProfileClient fakes a profile that gains followers on every call and
+// changes its name and pinned tweet on the third one. The caches are on, so
+// snapshots only see the changes when they bypass them.
+func newProfileClient(t *testing.T) *twitterx.Client {
+	t.Helper()
+	var calls atomic.Int32
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jack" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+			return
+		}
+		n := calls.Add(1)
+		name := "jack"
+		if n >= 3 {
+			name = "jack 🐦"
+		}
+		fmt.Fprintf(w, `{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12","name":%q,"followers":%d}}`, name, 100*n)
+	}))
+	t.Cleanup(fx.Close)
+	var feeds atomic.Int32
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinned := 20
+		if feeds.Add(1) >= 3 {
+			pinned = 30
+		}
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>jack</title>`+
+			`<item><title>Pinned: hello</title><guid>https://nitter.net/jack/status/%d#m</guid></item></channel></rss>`, pinned)
+	}))
+	t.Cleanup(nitter.Close)
+
+	client, err := twitterx.New(
+		twitterx.WithFxTwitterURL(fx.URL),
+		twitterx.WithNitterInstances(nitter.URL),
+		twitterx.WithUserCacheTTL(time.Hour),
+		twitterx.WithTimelineCacheTTL(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("twitterx.New: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestProfileTracker(t *testing.T) {
+	client := newProfileClient(t)
+	clock := &fakeClock{t: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	dir := t.TempDir()
+	tracker, err := NewProfileTracker(client, []string{"jack", "JACK", "missing"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewProfileTracker: %v", err)
+	}
+	for range 3 {
+		tracker.Snapshot(context.Background())
+	}
+
+	history, err := tracker.History("Jack", time.Time{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history.Changes) != 2 || history.Changes[1].Field != "name" || history.Changes[1].Old != "jack" || history.Changes[1].New != "jack 🐦" {
+		t.Fatalf("unexpected changes %+v", history.Changes)
+	}
+	if c := history.Changes[0]; c.Field != "pinned_tweet_id" || c.Old != "20" || c.New != "30" {
+		t.Fatalf("unexpected pinned tweet change %+v", c)
+	}
+	if history.Current["pinned_tweet_id"] != "30" || history.TrackedSince == nil || !history.TrackedSince.Equal(time.Date(2024, 3, 1, 0, 2, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	series, err := tracker.Stats("jack", MetricFollowers, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if len(series.Points) != 3 || series.Points[2].Value != 300 || series.Change != 200 {
+		t.Fatalf("unexpected series %+v", series)
+	}
+
+	// "missing" is tracked but has no snapshots
+	if history, err := tracker.History("missing", time.Time{}); err != nil || len(history.Changes) != 0 || history.TrackedSince != nil {
+		t.Fatalf("unexpected history %+v, %v", history, err)
+	}
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// The journal restores the history
+	reopened, err := NewProfileTracker(client, []string{"jack"}, WithDataDir(dir), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewProfileTracker: %v", err)
+	}
+	defer reopened.Close()
+	history, err = reopened.History("jack", time.Time{})
+	if err != nil || len(history.Changes) != 2 || history.Current["name"] != "jack 🐦" {
+		t.Fatalf("unexpected history after reopening %+v, %v", history, err)
+	}
+	series, err = reopened.Stats("jack", MetricFollowers, time.Date(2024, 3, 1, 0, 4, 0, 0, time.UTC), 1)
+	if err != nil || len(series.Points) != 1 || series.Points[0].Min != 200 || series.Points[0].Max != 300 {
+		t.Fatalf("unexpected series after reopening %+v, %v", series, err)
+	}
+}
+
+func TestProfileTrackerRetention(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	clock := &manualClock{t: start}
+	dir := t.TempDir()
+	tracker, err := NewProfileTracker(nil, []string{"jack"}, WithDataDir(dir), WithRetention(time.Hour), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewProfileTracker: %v", err)
+	}
+	p := tracker.profiles["jack"]
+	for i, rec := range []profileRecord{
+		{Time: start, Fields: map[string]string{"name": "jack", "id": "12"}},
+		{Time: start.Add(30 * time.Minute), Fields: map[string]string{"name": "jack 🐦"}},
+		{Time: start.Add(time.Hour)},
+		{Time: start.Add(90 * time.Minute), Fields: map[string]string{"name": "jack"}},
+		{Time: start.Add(150 * time.Minute), Fields: map[string]string{"name": "jack 🐦"}},
+		{Time: start.Add(165 * time.Minute)},
+	} {
+		rec.Username, rec.Counts.Followers = "jack", int64(i+1)
+		if err := tracker.add(p, rec); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	// The snapshot at 1h30 is the last one older than the retention, so it
+	// stays as the baseline of the newer changes
+	clock.t = start.Add(3 * time.Hour)
+	if err := tracker.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "profiles.jsonl"))
+	if err != nil {
+		t.Fatalf("reading the journal: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Fatalf("expected the journal to be compacted to 3 records, got %d:\n%s", lines, data)
+	}
+
+	reopened, err := NewProfileTracker(nil, []string{"jack"}, WithDataDir(dir), WithRetention(time.Hour), withClock(clock.now))
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer reopened.Close()
+	history, err := reopened.History("jack", time.Time{})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history.Changes) != 1 || history.Changes[0].Old != "jack" || history.Changes[0].New != "jack 🐦" || !history.Changes[0].Time.Equal(start.Add(150*time.Minute)) {
+		t.Fatalf("unexpected changes %+v", history.Changes)
+	}
+	if history.Current["id"] != "12" || !history.TrackedSince.Equal(start.Add(90*time.Minute)) {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	series, err := reopened.Stats("jack", MetricFollowers, time.Time{}, 10)
+	if err != nil || len(series.Points) != 3 || series.Points[0].Value != 4 || series.Change != 2 {
+		t.Fatalf("unexpected series %+v, %v", series, err)
+	}
+}
+
+func TestProfileTrackerErrors(t *testing.T) {
+	tracker, err := NewProfileTracker(nil, []string{"jack"})
+	if err != nil {
+		t.Fatalf("NewProfileTracker: %v", err)
+	}
+
+	var notFound *apperror.NotFoundError
+	if _, err := tracker.History("ev", time.Time{}); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found for an untracked user, got %v", err)
+	}
+	var nilTracker *ProfileTracker
+	if _, err := nilTracker.Stats("jack", MetricFollowers, time.Time{}, 10); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found without a tracker, got %v", err)
+	}
+
+	var validation *apperror.ValidationError
+	if _, err := tracker.Stats("jack", "bananas", time.Time{}, 10); !errors.As(err, &validation) || validation.Field != "metric" {
+		t.Fatalf("expected a metric validation error, got %v", err)
+	}
+	if _, err := tracker.Stats("jack", MetricFollowers, time.Time{}, 0); !errors.As(err, &validation) || validation.Field != "points" {
+		t.Fatalf("expected a points validation error, got %v", err)
+	}
+}
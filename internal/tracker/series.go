@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"time"
+)
+
+// Limits of the number of points of a downsampled series
+const (
+	DefaultMaxPoints = 100
+	MaxPoints        = 1000
+)
+
+// Point is a bucket of a downsampled series
+type Point struct {
+	// Time is the start of the bucket
+	Time time.Time `json:"time"`
+	// Value is the last sample of the bucket; Min and Max bound its samples
+	Value int64 `json:"value"`
+	Min   int64 `json:"min"`
+	Max   int64 `json:"max"`
+}
+
+// sample is a value at a point in time
+type sample struct {
+	time  time.Time
+	value int64
+}
+
+// downsample groups samples, sorted by time, into at most maxPoints buckets
+// of equal width starting at the first sample; the last bucket also holds
+// the samples at its end. It returns the buckets that hold samples and their
+// width, a whole number of seconds.
+func downsample(samples []sample, maxPoints int) ([]Point, time.Duration) {
+	points := []Point{}
+	if len(samples) == 0 {
+		return points, 0
+	}
+	maxPoints = max(maxPoints, 1)
+	start := samples[0].time
+	span := samples[len(samples)-1].time.Sub(start)
+	width := max((span/time.Duration(maxPoints) + time.Second - 1).Truncate(time.Second), time.Second)
+
+	for _, s := range samples {
+		index := min(int64(s.time.Sub(start)/width), int64(maxPoints-1))
+		bucket := start.Add(time.Duration(index) * width)
+		if n := len(points); n > 0 && points[n-1].Time.Equal(bucket) {
+			p := &points[n-1]
+			p.Value = s.value
+			p.Min = min(p.Min, s.value)
+			p.Max = max(p.Max, s.value)
+			continue
+		}
+		points = append(points, Point{Time: bucket, Value: s.value, Min: s.value, Max: s.value})
+	}
+	return points, width
+}
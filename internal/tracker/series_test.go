@@ -0,0 +1,38 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var samples []sample
+	for i := range 10 {
+		samples = append(samples, sample{time: start.Add(time.Duration(i) * time.Hour), value: int64(i * i)})
+	}
+
+	points, width := downsample(samples, 3)
+	if width != 3*time.Hour {
+		t.Fatalf("unexpected width %s", width)
+	}
+	if len(points) != 3 {
+		t.Fatalf("expected 3 points, got %+v", points)
+	}
+	if p := points[0]; !p.Time.Equal(start) || p.Min != 0 || p.Max != 4 || p.Value != 4 {
+		t.Fatalf("unexpected first point %+v", p)
+	}
+	if p := points[2]; !p.Time.Equal(start.Add(6*time.Hour)) || p.Value != 81 || p.Min != 36 {
+		t.Fatalf("unexpected last point %+v", p)
+	}
+
+	if points, _ := downsample(samples, 100); len(points) != 10 {
+		t.Fatalf("expected every sample, got %d points", len(points))
+	}
+	if points, width := downsample(samples[:1], 10); len(points) != 1 || width != time.Second {
+		t.Fatalf("expected a single point, got %+v, %s", points, width)
+	}
+	if points, width := downsample(nil, 10); len(points) != 0 || width != 0 {
+		t.Fatalf("expected no points, got %+v", points)
+	}
+}
@@ -0,0 +1,86 @@
+// Package tracker follows watched accounts in the background and keeps what
+// the upstreams only show at one point in time: ProfileTracker snapshots
+// profiles on a schedule and records their field-level changes and a time
+// series of their counts, EngagementTracker follows the engagement counts
+// of their recent tweets, and DeletionTracker detects which of those tweets
+// get deleted.
+//
+// Trackers keep their data in memory. With a data directory, every
+// snapshot is also appended to a JSON lines journal that is replayed at
+// startup.
+package tracker
+
+import (
+	"strings"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+// DefaultInterval is how often watched profiles are snapshotted
+const DefaultInterval = 15 * time.Minute
+
+// Option configures a tracker
+type Option func(*options)
+
+type options struct {
+	interval  time.Duration
+	schedule  []Step
+	window    time.Duration
+	retention time.Duration
+	dataDir   string
+	now       func() time.Time
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		interval:  DefaultInterval,
+		schedule:  DefaultSchedule,
+		window:    DefaultRecheckWindow,
+		retention: DefaultRetention,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithInterval sets how often the tracker polls the upstreams
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// WithDataDir keeps a journal in dir so the data outlives restarts. An
+// empty dir keeps the data in memory only.
+func WithDataDir(dir string) Option {
+	return func(o *options) {
+		o.dataDir = dir
+	}
+}
+
+// withClock replaces time.Now in tests
+func withClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// normalize returns the key of a screen name, which is case insensitive
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimPrefix(username, "@"))
+}
+
+// notTracked is the error for accounts that are not watched
+func notTracked(username string) error {
+	return &apperror.NotFoundError{Resource: "tracked user", ID: username}
+}
+
+// notTrackedTweet is the error for tweets that are not tracked
+func notTrackedTweet(id string) error {
+	return &apperror.NotFoundError{Resource: "tracked tweet", ID: id}
+}
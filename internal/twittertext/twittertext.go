@@ -0,0 +1,274 @@
+// Package twittertext extracts the entities of tweet text: hashtags,
+// mentions, URLs and cashtags. It follows the rules of Twitter's twitter-text
+// library closely enough for display: Unicode hashtags, mentions of valid
+// screen names, URLs with or without a scheme and $TICKER cashtags.
+package twittertext
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"twitterx-api/internal/models"
+)
+
+// Link is a URL whose position in the text is already known, like an anchor
+// of the HTML the text was taken from. Start and End are byte offsets.
+type Link struct {
+	Start, End int
+	// URL is the target of the link, the expanded form of a t.co link
+	URL string
+}
+
+// maxDisplayURL is how many characters of a URL are displayed
+const maxDisplayURL = 30
+
+var (
+	// schemeURLPattern matches URLs with a scheme
+	schemeURLPattern = regexp.MustCompile(`(?i)https?://[^\s<>"'\x{3000}]+`)
+	// bareURLPattern matches domains with a well-known top-level domain,
+	// optionally followed by a port and a path
+	bareURLPattern = regexp.MustCompile(`(?i)(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+(?:` + tlds + `)((?::[0-9]{1,5})?(?:/[^\s<>"'\x{3000}]*)?)`)
+
+	hashtagPattern = regexp.MustCompile(`[#＃]([\p{L}\p{M}\p{Nd}_\x{200c}\x{200d}\x{a67e}\x{05be}\x{05f3}\x{05f4}\x{ff5e}\x{301c}\x{309b}\x{309c}\x{30a0}\x{30fb}\x{3003}\x{0f0b}\x{0f0c}\x{00b7}]+)`)
+	mentionPattern = regexp.MustCompile(`[@＠]([A-Za-z0-9_]+)`)
+	cashtagPattern = regexp.MustCompile(`(?i)\$([a-z]{1,6}(?:[._][a-z]{1,2})?)`)
+)
+
+// tlds are the top-level domains recognized in URLs without a scheme
+const tlds = `com|net|org|edu|gov|mil|int|info|biz|io|co|ai|app|dev|me|tv|ly|gg|fm|to|sh|xyz|news|blog|page|site|online|tech|` +
+	`us|uk|ca|au|nz|ie|de|fr|es|it|nl|be|at|ch|se|no|fi|dk|pl|pt|cz|gr|ru|ua|tr|il|jp|cn|kr|tw|hk|sg|in|br|mx|ar|za|eu`
+
+// Extract returns the entities of text
+func Extract(text string) *models.Entities {
+	return ExtractWithLinks(text, nil)
+}
+
+// ExtractWithLinks returns the entities of text, taking URLs from links
+// instead of detecting them where they are known
+func ExtractWithLinks(text string, links []Link) *models.Entities {
+	var spans []span
+	for _, l := range links {
+		if l.Start < 0 || l.End > len(text) || l.Start >= l.End {
+			continue
+		}
+		spans = append(spans, span{start: l.Start, end: l.End, kind: kindURL, value: l.URL})
+	}
+	for _, s := range findURLs(text) {
+		if !overlaps(spans, s) {
+			spans = append(spans, s)
+		}
+	}
+	urls := len(spans)
+	for _, s := range slices.Concat(findHashtags(text), findMentions(text), findCashtags(text)) {
+		if !overlaps(spans[:urls], s) {
+			spans = append(spans, s)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return toEntities(text, spans)
+}
+
+type kind int
+
+const (
+	kindURL kind = iota
+	kindHashtag
+	kindMention
+	kindCashtag
+)
+
+// span is an entity with byte offsets
+type span struct {
+	start, end int
+	kind       kind
+	// value is the tag, the screen name or the expanded URL
+	value string
+}
+
+func overlaps(spans []span, s span) bool {
+	for _, o := range spans {
+		if s.start < o.end && o.start < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// findURLs returns the URLs of text, with and without a scheme
+func findURLs(text string) []span {
+	var spans []span
+	for _, m := range schemeURLPattern.FindAllStringIndex(text, -1) {
+		if isWordRune(before(text, m[0])) {
+			continue
+		}
+		if end := trimURL(text, m[0], m[1]); end > m[0] {
+			spans = append(spans, span{start: m[0], end: end, kind: kindURL})
+		}
+	}
+	for _, m := range bareURLPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, domainEnd := m[0], m[2]
+		if r := before(text, start); r != 0 && (isWordRune(r) || strings.ContainsRune("@＠$#＃.-/:", r)) {
+			continue
+		}
+		// The TLD must end the domain: "example.company" is no URL
+		if r, _ := utf8.DecodeRuneInString(text[domainEnd:]); domainEnd < len(text) && (isWordRune(r) || r == '-') {
+			continue
+		}
+		s := span{start: start, end: trimURL(text, start, m[1]), kind: kindURL}
+		if !overlaps(spans, s) {
+			spans = append(spans, s)
+		}
+	}
+	for i := range spans {
+		spans[i].value = text[spans[i].start:spans[i].end]
+	}
+	return spans
+}
+
+// trimURL drops the trailing punctuation of the URL at text[start:end] and
+// returns its new end. A closing parenthesis stays when it has an opening
+// one in the URL, as in Wikipedia links.
+func trimURL(text string, start, end int) int {
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(text[start:end])
+		switch {
+		case r == ')' && strings.Count(text[start:end], "(") >= strings.Count(text[start:end], ")"):
+			return end
+		case strings.ContainsRune(".,;:!?'\")]}…", r), unicode.Is(unicode.Pf, r), unicode.Is(unicode.Pe, r):
+			end -= size
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// findHashtags returns the hashtags of text. A hashtag needs a letter or a
+// mark and cannot follow a word or an ampersand, as in "&#39;".
+func findHashtags(text string) []span {
+	var spans []span
+	for _, m := range hashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if r := before(text, m[0]); r == '&' || r == '_' || isWordRune(r) {
+			continue
+		}
+		if after := text[m[1]:]; strings.HasPrefix(after, "#") || strings.HasPrefix(after, "＃") || strings.HasPrefix(after, "://") {
+			continue
+		}
+		tag := text[m[2]:m[3]]
+		if strings.IndexFunc(tag, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsMark(r) }) < 0 {
+			continue
+		}
+		spans = append(spans, span{start: m[0], end: m[1], kind: kindHashtag, value: tag})
+	}
+	return spans
+}
+
+// findMentions returns the mentions of valid screen names in text
+func findMentions(text string) []span {
+	var spans []span
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		if r := before(text, m[0]); isWordRune(r) || strings.ContainsRune("_!#$%&*@＠", r) {
+			continue
+		}
+		after, _ := utf8.DecodeRuneInString(text[m[1]:])
+		if m[1] < len(text) && (after == '@' || after == '＠' || unicode.IsLetter(after) || strings.HasPrefix(text[m[1]:], "://")) {
+			continue
+		}
+		if name := text[m[2]:m[3]]; len(name) <= 15 {
+			spans = append(spans, span{start: m[0], end: m[1], kind: kindMention, value: name})
+		}
+	}
+	return spans
+}
+
+// findCashtags returns the cashtags of text. They stand alone: whitespace
+// before and whitespace or punctuation after.
+func findCashtags(text string) []span {
+	var spans []span
+	for _, m := range cashtagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if r := before(text, m[0]); r != 0 && !unicode.IsSpace(r) {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(text[m[1]:]); m[1] < len(text) && !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+			continue
+		}
+		spans = append(spans, span{start: m[0], end: m[1], kind: kindCashtag, value: text[m[2]:m[3]]})
+	}
+	return spans
+}
+
+// before returns the rune ending at i, or 0 at the start of text
+func before(text string, i int) rune {
+	if i == 0 {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// toEntities converts the spans, sorted by start, to entities with code
+// point offsets
+func toEntities(text string, spans []span) *models.Entities {
+	e := &models.Entities{
+		Hashtags: []models.Hashtag{},
+		Mentions: []models.Mention{},
+		URLs:     []models.URLEntity{},
+		Cashtags: []models.Cashtag{},
+	}
+	offset, points := 0, 0
+	codePoint := func(i int) int {
+		points += utf8.RuneCountInString(text[offset:i])
+		offset = i
+		return points
+	}
+	for _, s := range spans {
+		start := codePoint(s.start)
+		end := codePoint(s.end)
+		switch s.kind {
+		case kindURL:
+			written := text[s.start:s.end]
+			e.URLs = append(e.URLs, models.URLEntity{
+				Start:       start,
+				End:         end,
+				URL:         written,
+				ExpandedURL: expand(s.value),
+				DisplayURL:  DisplayURL(s.value),
+			})
+		case kindHashtag:
+			e.Hashtags = append(e.Hashtags, models.Hashtag{Start: start, End: end, Tag: s.value})
+		case kindMention:
+			e.Mentions = append(e.Mentions, models.Mention{Start: start, End: end, ScreenName: s.value})
+		case kindCashtag:
+			e.Cashtags = append(e.Cashtags, models.Cashtag{Start: start, End: end, Tag: s.value})
+		}
+	}
+	return e
+}
+
+// expand returns u with a scheme
+func expand(u string) string {
+	if parsed, err := url.Parse(u); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		return u
+	}
+	return "https://" + u
+}
+
+// DisplayURL shortens a URL for display, as Twitter does
+func DisplayURL(u string) string {
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	u = strings.TrimPrefix(u, "www.")
+	if utf8.RuneCountInString(u) > maxDisplayURL {
+		runes := []rune(u)
+		u = string(runes[:maxDisplayURL-1]) + "…"
+	}
+	return u
+}
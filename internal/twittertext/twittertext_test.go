@@ -0,0 +1,89 @@
+package twittertext
+
+import (
+	"reflect"
+	"testing"
+
+	"twitterx-api/internal/models"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		text string
+		want []models.Hashtag
+	}{
+		{"#go rocks", []models.Hashtag{{Start: 0, End: 3, Tag: "go"}}},
+		{"café #日本語 ok", []models.Hashtag{{Start: 5, End: 9, Tag: "日本語"}}},
+		{"#ĉiuj #Ελλάδα", []models.Hashtag{{Start: 0, End: 5, Tag: "ĉiuj"}, {Start: 6, End: 13, Tag: "Ελλάδα"}}},
+		{"issue #123 and a#b and &#39;", []models.Hashtag{}},
+		{"#1st", []models.Hashtag{{Start: 0, End: 4, Tag: "1st"}}},
+		{"https://example.com/#anchor", []models.Hashtag{}},
+	}
+	for _, tt := range tests {
+		if got := Extract(tt.text).Hashtags; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []models.Mention
+	}{
+		{"RT @jack: hi @Support_1", []models.Mention{{Start: 3, End: 8, ScreenName: "jack"}, {Start: 13, End: 23, ScreenName: "Support_1"}}},
+		{"mail me@example.com", []models.Mention{}},
+		{"@waytoolongscreenname", []models.Mention{}},
+		{"🎉 ＠jack", []models.Mention{{Start: 2, End: 7, ScreenName: "jack"}}},
+	}
+	for _, tt := range tests {
+		if got := Extract(tt.text).Mentions; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []models.URLEntity
+	}{
+		{"see https://t.co/abc123.", []models.URLEntity{{Start: 4, End: 23, URL: "https://t.co/abc123", ExpandedURL: "https://t.co/abc123", DisplayURL: "t.co/abc123"}}},
+		{"(https://en.wikipedia.org/wiki/Go_(language))", []models.URLEntity{{Start: 1, End: 44, URL: "https://en.wikipedia.org/wiki/Go_(language)", ExpandedURL: "https://en.wikipedia.org/wiki/Go_(language)", DisplayURL: "en.wikipedia.org/wiki/Go_(lan…"}}},
+		{"go to example.com/docs!", []models.URLEntity{{Start: 6, End: 22, URL: "example.com/docs", ExpandedURL: "https://example.com/docs", DisplayURL: "example.com/docs"}}},
+		{"example.company and me@example.com", []models.URLEntity{}},
+	}
+	for _, tt := range tests {
+		if got := Extract(tt.text).URLs; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractCashtags(t *testing.T) {
+	tests := []struct {
+		text string
+		want []models.Cashtag
+	}{
+		{"$TSLA and $BRK.A up, $AAPL's too", []models.Cashtag{{Start: 0, End: 5, Tag: "TSLA"}, {Start: 10, End: 16, Tag: "BRK.A"}, {Start: 21, End: 26, Tag: "AAPL"}}},
+		{"costs $5 or US$AAPL or $TOOLONG", []models.Cashtag{}},
+	}
+	for _, tt := range tests {
+		if got := Extract(tt.text).Cashtags; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractWithLinks(t *testing.T) {
+	// An anchor of the Nitter HTML expands the shortened link it displays
+	text := "read example.com/a-long… #news"
+	got := ExtractWithLinks(text, []Link{{Start: 5, End: 26, URL: "https://example.com/a-long-article"}})
+	want := []models.URLEntity{{Start: 5, End: 24, URL: "example.com/a-long…", ExpandedURL: "https://example.com/a-long-article", DisplayURL: "example.com/a-long-article"}}
+	if !reflect.DeepEqual(got.URLs, want) {
+		t.Fatalf("got %+v, want %+v", got.URLs, want)
+	}
+	if len(got.Hashtags) != 1 || got.Hashtags[0].Start != 25 {
+		t.Fatalf("unexpected hashtags %+v", got.Hashtags)
+	}
+}
@@ -0,0 +1,143 @@
+// Package client is a hand-written, typed Go client for the core read
+// operations of the TwitterX API: users, their tweets and single tweets.
+// The other operations of /api/openapi.json are not covered.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize bounds how much of an error response is kept
+const maxErrorBodySize = 4 << 10
+
+// Client calls a TwitterX API server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	userAgent  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends requests through httpClient instead of a client with
+// a 30 second timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates every request with key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "https://twitterx.example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  "twitterx-api-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is returned when the server answers with a non-2xx status
+type Error struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is the wait requested by a 429 response
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("twitterx: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// NotFound reports whether the user or tweet does not exist
+func (e *Error) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// GetUser returns the profile of username
+func (c *Client) GetUser(ctx context.Context, username string) (*UserResponse, error) {
+	var resp UserResponse
+	if err := c.get(ctx, "/api/users/"+url.PathEscape(username), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetUserTweets returns the recent tweet IDs of username, newest first
+func (c *Client) GetUserTweets(ctx context.Context, username string) (*TweetsResponse, error) {
+	var resp TweetsResponse
+	if err := c.get(ctx, "/api/users/"+url.PathEscape(username)+"/tweets", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetTweet returns the tweet id posted by username
+func (c *Client) GetTweet(ctx context.Context, username, id string) (*TweetResponse, error) {
+	var resp TweetResponse
+	if err := c.get(ctx, "/api/users/"+url.PathEscape(username)+"/tweets/"+url.PathEscape(id), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("twitterx: invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"twitterx-api/internal/models"
+)
+
+func TestClientGetTweet(t *testing.T) {
+	created := time.Date(2025, time.October, 8, 9, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/users/jack/tweets/20" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") != "secret" {
+			t.Errorf("expected the API key to be sent")
+		}
+		json.NewEncoder(w).Encode(models.FxTwitterResponse{
+			Code:    200,
+			Message: "OK",
+			Tweet:   &models.Tweet{ID: "20", Text: "just setting up my twttr", CreatedAt: models.TwitterTime{Time: created}},
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/", WithAPIKey("secret"))
+	resp, err := c.GetTweet(context.Background(), "jack", "20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Tweet.ID != "20" || !resp.Tweet.CreatedAt.Equal(created) {
+		t.Fatalf("unexpected tweet %+v", resp.Tweet)
+	}
+}
+
+func TestClientGetUserTweets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/users/a%2Fb/tweets" {
+			t.Errorf("expected an escaped username, got %s", r.URL.EscapedPath())
+		}
+		json.NewEncoder(w).Encode(models.TweetsResponse{Username: "a/b", TweetIDs: []string{"1", "2"}})
+	}))
+	defer server.Close()
+
+	resp, err := New(server.URL).GetUserTweets(context.Background(), "a/b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.TweetIDs) != 2 {
+		t.Fatalf("expected 2 tweet IDs, got %v", resp.TweetIDs)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/users/missing" {
+			http.Error(w, "user not found: missing", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	_, err := c.GetUser(context.Background(), "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !apiErr.NotFound() || apiErr.Message != "user not found: missing" {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	_, err = c.GetUser(context.Background(), "busy")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != 7*time.Second {
+		t.Fatalf("expected a rate limit error with Retry-After, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package client
+
+import "twitterx-api/internal/models"
+
+// Response and model types, as described by the OpenAPI document
+type (
+	TweetsResponse = models.TweetsResponse
+	TweetResponse  = models.FxTwitterResponse
+	UserResponse   = models.FxTwitterUserResponse
+	Tweet          = models.Tweet
+	Author         = models.Author
+	Media          = models.Media
+	MediaItem      = models.MediaItem
+	Photo          = models.Photo
+	Video          = models.Video
+	MosaicInfo     = models.MosaicInfo
+	ExternalMedia  = models.ExternalMedia
+	Poll           = models.Poll
+	PollChoice     = models.PollChoice
+	Translation    = models.Translation
+	User           = models.User
+	Verification   = models.Verification
+	TwitterTime    = models.TwitterTime
+	Entities       = models.Entities
+	Hashtag        = models.Hashtag
+	Mention        = models.Mention
+	URLEntity      = models.URLEntity
+	Cashtag        = models.Cashtag
+	TimelineTweet  = models.TimelineTweet
+)
@@ -0,0 +1,51 @@
+package twitterx
+
+import (
+	"errors"
+
+	"twitterx-api/internal/apperror"
+)
+
+// Errors returned by the client. Use errors.As and errors.Is to inspect them.
+type (
+	// ValidationError reports an invalid argument
+	ValidationError = apperror.ValidationError
+	// NotFoundError reports a user or tweet that does not exist
+	NotFoundError = apperror.NotFoundError
+	// UpstreamError reports a failed or invalid upstream response
+	UpstreamError = apperror.UpstreamError
+	// RateLimitError reports that the upstream concurrency limit was hit
+	RateLimitError = apperror.RateLimitError
+)
+
+var (
+	// ErrSessionsExhausted is wrapped when a Nitter instance has no usable sessions
+	ErrSessionsExhausted = apperror.ErrSessionsExhausted
+	// ErrCircuitOpen is returned while the circuit breaker of an upstream is open
+	ErrCircuitOpen = apperror.ErrCircuitOpen
+	// ErrResponseTooLarge is wrapped when an upstream response exceeds the size cap
+	ErrResponseTooLarge = apperror.ErrResponseTooLarge
+	// ErrProtected is wrapped by a NotFoundError for a tweet or account hidden
+	// by a protected account
+	ErrProtected = apperror.ErrProtected
+	// ErrSuspended is wrapped by a NotFoundError for a suspended account
+	ErrSuspended = apperror.ErrSuspended
+)
+
+// IsNotFound reports whether err means the user or tweet does not exist
+func IsNotFound(err error) bool {
+	var notFoundErr *NotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+// ErrorClass returns a short, stable name for the kind of err, such as
+// "not_found", "timeout" or "circuit_open". It returns an empty string for nil.
+func ErrorClass(err error) string {
+	return apperror.Class(err)
+}
+
+// HTTPStatusCode returns the HTTP status code the /api endpoints of the API
+// server answer err with
+func HTTPStatusCode(err error) int {
+	return apperror.HTTPStatusCode(err)
+}
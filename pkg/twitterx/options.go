@@ -0,0 +1,114 @@
+package twitterx
+
+import (
+	"net/http"
+	"time"
+
+	"twitterx-api/internal/service"
+)
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	nitterInstances []string
+	shared          []service.Option
+	nitter          []service.Option
+	fxTwitter       []service.Option
+	transport       *TransportConfig
+	roundTripper    http.RoundTripper
+}
+
+// WithNitterInstances sets the Nitter base URLs used for timelines, in
+// failover order
+func WithNitterInstances(urls ...string) Option {
+	return func(o *options) {
+		o.nitterInstances = append(o.nitterInstances, urls...)
+	}
+}
+
+// WithFxTwitterURL points the client at a self-hosted FxTwitter API instead
+// of https://api.fxtwitter.com
+func WithFxTwitterURL(baseURL string) Option {
+	return func(o *options) {
+		o.fxTwitter = append(o.fxTwitter, service.WithFxTwitterURL(baseURL))
+	}
+}
+
+// WithTweetCacheTTL sets how long tweets are cached (0 disables caching)
+func WithTweetCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.fxTwitter = append(o.fxTwitter, service.WithTweetCacheTTL(ttl))
+	}
+}
+
+// WithUserCacheTTL sets how long user profiles are cached (0 disables caching)
+func WithUserCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.fxTwitter = append(o.fxTwitter, service.WithUserCacheTTL(ttl))
+	}
+}
+
+// WithTimelineCacheTTL sets how long timelines are cached (0 disables caching)
+func WithTimelineCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.nitter = append(o.nitter, service.WithTimelineCacheTTL(ttl))
+	}
+}
+
+// WithRetryPolicy sets how failed upstream calls are retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.shared = append(o.shared, service.WithRetryPolicy(policy))
+	}
+}
+
+// WithCircuitBreaker stops calling an upstream for openTimeout after
+// threshold consecutive failures (a threshold of 0 disables the breaker)
+func WithCircuitBreaker(threshold int, openTimeout time.Duration) Option {
+	return func(o *options) {
+		o.shared = append(o.shared, service.WithCircuitBreaker(threshold, openTimeout))
+	}
+}
+
+// WithNitterConcurrency allows at most limit simultaneous Nitter calls.
+// Up to maxQueue further calls wait for at most maxWait.
+func WithNitterConcurrency(limit, maxQueue int, maxWait time.Duration) Option {
+	return func(o *options) {
+		o.nitter = append(o.nitter, service.WithConcurrencyLimit(limit, maxQueue, maxWait))
+	}
+}
+
+// WithFxTwitterConcurrency allows at most limit simultaneous FxTwitter
+// calls. Up to maxQueue further calls wait for at most maxWait.
+func WithFxTwitterConcurrency(limit, maxQueue int, maxWait time.Duration) Option {
+	return func(o *options) {
+		o.fxTwitter = append(o.fxTwitter, service.WithConcurrencyLimit(limit, maxQueue, maxWait))
+	}
+}
+
+// WithTransport configures the pooled HTTP transport shared by all upstream
+// calls (proxy, User-Agent, compression, response size cap)
+func WithTransport(cfg TransportConfig) Option {
+	return func(o *options) {
+		o.transport = &cfg
+		o.roundTripper = nil
+	}
+}
+
+// WithRoundTripper sends upstream requests through rt instead of the
+// built-in transport
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.roundTripper = rt
+		o.transport = nil
+	}
+}
+
+// WithMetrics reports upstream calls, cache lookups and circuit breaker
+// state changes to m
+func WithMetrics(m MetricsRecorder) Option {
+	return func(o *options) {
+		o.shared = append(o.shared, service.WithMetrics(m))
+	}
+}
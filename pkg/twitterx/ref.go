@@ -0,0 +1,83 @@
+package twitterx
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"twitterx-api/internal/apperror"
+)
+
+// unknownAuthor stands in for the author in x.com/i/status/{id} links, which
+// FxTwitter resolves like any other
+const unknownAuthor = "i"
+
+// TweetRef identifies a tweet
+type TweetRef struct {
+	// Username is the author, or "i" when the reference doesn't name one
+	Username string
+	ID       string
+}
+
+// ParseTweetRef parses a tweet ID or a link to a tweet on x.com,
+// twitter.com, Nitter or one of the embed fixers
+// (https://x.com/jack/status/20, nitter.net/jack/status/20#m, jack/status/20)
+func ParseTweetRef(s string) (TweetRef, error) {
+	s = strings.TrimSpace(s)
+	if isTweetID(s) {
+		return TweetRef{Username: unknownAuthor, ID: s}, nil
+	}
+
+	raw := s
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err == nil {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		// A path without a host, like jack/status/20, parses with jack as the host
+		if !strings.Contains(s, "://") && !strings.Contains(u.Host, ".") {
+			segments = append([]string{u.Host}, segments...)
+		}
+		for i := 1; i+1 < len(segments); i++ {
+			if (segments[i] == "status" || segments[i] == "statuses") && isTweetID(segments[i+1]) {
+				username := segments[i-1]
+				if username == "web" {
+					// x.com/i/web/status/{id}
+					username = unknownAuthor
+				}
+				return TweetRef{Username: username, ID: segments[i+1]}, nil
+			}
+		}
+	}
+	return TweetRef{}, &apperror.ValidationError{Field: "tweet", Message: "not a tweet ID or link: " + s}
+}
+
+func isTweetID(s string) bool {
+	if s == "" || len(s) > 20 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// twitterEpoch is the epoch of tweet IDs in Unix milliseconds
+const twitterEpoch = 1288834974657
+
+// firstSnowflake is the first tweet ID that embeds its creation time
+const firstSnowflake = 29700859247
+
+// TweetTime returns the creation time embedded in a tweet ID. Tweets older
+// than November 2010 don't carry one and report false.
+func TweetTime(id string) (time.Time, bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n < firstSnowflake {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(n>>22) + twitterEpoch).UTC(), true
+}
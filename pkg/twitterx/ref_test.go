@@ -0,0 +1,48 @@
+package twitterx
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseTweetRef(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TweetRef
+	}{
+		{"20", TweetRef{"i", "20"}},
+		{"https://x.com/jack/status/20", TweetRef{"jack", "20"}},
+		{"https://twitter.com/jack/status/20?s=20", TweetRef{"jack", "20"}},
+		{"https://nitter.net/jack/status/20#m", TweetRef{"jack", "20"}},
+		{"fxtwitter.com/jack/status/20/photo/1", TweetRef{"jack", "20"}},
+		{"https://x.com/i/web/status/20", TweetRef{"i", "20"}},
+		{"jack/status/20", TweetRef{"jack", "20"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTweetRef(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTweetRef(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "jack", "https://x.com/jack", "https://x.com/jack/status/abc"} {
+		if _, err := ParseTweetRef(in); err == nil {
+			t.Errorf("ParseTweetRef(%q): expected an error", in)
+		}
+	}
+}
+
+func TestTweetTime(t *testing.T) {
+	want := time.Date(2025, time.October, 25, 17, 55, 11, 0, time.UTC)
+	id := strconv.FormatInt((want.UnixMilli()-twitterEpoch)<<22|12345, 10)
+	if got, ok := TweetTime(id); !ok || !got.Equal(want) {
+		t.Fatalf("TweetTime(%s) = %s, %v; want %s", id, got, ok, want)
+	}
+	if _, ok := TweetTime("20"); ok {
+		t.Fatal("expected no time for a pre-snowflake ID")
+	}
+	if _, ok := TweetTime("abc"); ok {
+		t.Fatal("expected no time for an invalid ID")
+	}
+}
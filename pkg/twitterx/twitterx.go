@@ -0,0 +1,284 @@
+// Package twitterx fetches Twitter/X profiles, timelines and tweets through
+// Nitter and FxTwitter. It is the library behind the TwitterX API server and
+// brings the same caching, retries and circuit breakers to other programs.
+//
+//	client, err := twitterx.New(twitterx.WithNitterInstances("https://nitter.example.com"))
+//	if err != nil {
+//		return err
+//	}
+//	user, err := client.GetUser(ctx, "jack")
+package twitterx
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"twitterx-api/internal/httpclient"
+	"twitterx-api/internal/service"
+)
+
+// Client composes the Nitter and FxTwitter upstreams. It is safe for
+// concurrent use.
+type Client struct {
+	nitter    *service.NitterService
+	fxTwitter *service.FxTwitterService
+	transport http.RoundTripper
+}
+
+// New creates a client. Timelines need at least one Nitter instance; users
+// and tweets are served by FxTwitter.
+func New(opts ...Option) (*Client, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := o.roundTripper
+	if transport == nil {
+		cfg := TransportConfig{Compression: true, MaxResponseSize: 10 << 20}
+		if o.transport != nil {
+			cfg = *o.transport
+		}
+		var err error
+		if transport, err = httpclient.NewTransport(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	shared := append(slices.Clone(o.shared), service.WithTransport(transport))
+	return &Client{
+		nitter:    service.NewNitterService(o.nitterInstances, slices.Concat(shared, o.nitter)...),
+		fxTwitter: service.NewFxTwitterService(slices.Concat(shared, o.fxTwitter)...),
+		transport: transport,
+	}, nil
+}
+
+// GetUser returns the profile of username
+func (c *Client) GetUser(ctx context.Context, username string) (*User, error) {
+	resp, err := c.fxTwitter.GetUserData(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// FetchUser returns the profile of username like GetUser, but always from
+// FxTwitter rather than the user cache, for callers that need it as of now
+func (c *Client) FetchUser(ctx context.Context, username string) (*User, error) {
+	resp, err := c.fxTwitter.FetchUserData(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+// GetUserWithPinnedTweet returns the profile of username with the ID of its
+// pinned tweet, which only the Nitter feed tells. The ID is read from the
+// cached timeline of username and left empty when it isn't cached, so a
+// profile never waits on Nitter.
+func (c *Client) GetUserWithPinnedTweet(ctx context.Context, username string) (*User, error) {
+	user, err := c.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	timeline, ok := c.nitter.CachedTimeline(username)
+	if !ok {
+		return user, nil
+	}
+	// The profile is shared with the cache
+	withPinned := *user
+	withPinned.PinnedTweetID = timeline.PinnedTweetID()
+	return &withPinned, nil
+}
+
+// PinnedTweetID returns the ID of the tweet pinned to the profile of
+// username, or an empty string when none is. The feed is always fetched
+// from Nitter rather than the timeline cache, so the ID is as of now.
+func (c *Client) PinnedTweetID(ctx context.Context, username string) (string, error) {
+	timeline, err := c.nitter.FetchUserTimeline(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	return timeline.PinnedTweetID(), nil
+}
+
+// GetTweet returns the tweet id posted by username
+func (c *Client) GetTweet(ctx context.Context, username, id string) (*Tweet, error) {
+	resp, err := c.fxTwitter.GetTweetData(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Tweet, nil
+}
+
+// FetchTweet returns the tweet id posted by username like GetTweet, but
+// always from FxTwitter rather than the tweet cache, for callers that need
+// counts as of now
+func (c *Client) FetchTweet(ctx context.Context, username, id string) (*Tweet, error) {
+	resp, err := c.fxTwitter.FetchTweetData(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Tweet, nil
+}
+
+// GetTimeline returns the recent tweet IDs of username, newest first
+func (c *Client) GetTimeline(ctx context.Context, username string) (*Timeline, error) {
+	return c.nitter.GetUserTimeline(ctx, username)
+}
+
+// GetTimelinePage returns the page of the timeline of username that starts
+// at cursor. Pass the NextCursor of a page to get the following, older page;
+// an empty cursor returns the first page.
+func (c *Client) GetTimelinePage(ctx context.Context, username, cursor string) (*Timeline, error) {
+	return c.nitter.GetUserTimelinePage(ctx, username, cursor)
+}
+
+// Search returns the page of tweets matching query that starts at cursor
+func (c *Client) Search(ctx context.Context, query, cursor string) (*Timeline, error) {
+	return c.nitter.Search(ctx, query, cursor)
+}
+
+// HydrateTweets fetches the tweets ids posted by username with up to
+// concurrency parallel calls and calls fn with every result in the order of
+// ids. Returning an error from fn stops the remaining fetches.
+func (c *Client) HydrateTweets(ctx context.Context, username string, ids []string, concurrency int, fn func(id string, tweet *Tweet, err error) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		tweet *Tweet
+		err   error
+		done  chan struct{}
+	}
+	results := make([]result, len(ids))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+
+	go func() {
+		sem := make(chan struct{}, max(concurrency, 1))
+		for i, id := range ids {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				defer func() { <-sem }()
+				r := &results[i]
+				r.tweet, r.err = c.GetTweet(ctx, username, id)
+				close(r.done)
+			}()
+		}
+	}()
+
+	for i := range results {
+		r := &results[i]
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := fn(ids[i], r.tweet, r.err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxThreadDepth bounds how many replies GetThread walks up
+const maxThreadDepth = 100
+
+// GetThread returns the tweet id and the replies it answers, oldest first.
+// The walk stops at the first ancestor that no longer exists.
+func (c *Client) GetThread(ctx context.Context, username, id string) ([]*Tweet, error) {
+	tweet, err := c.GetTweet(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := []*Tweet{tweet}
+	for len(thread) < maxThreadDepth && tweet.ReplyingToStatus != nil {
+		author := unknownAuthor
+		if tweet.ReplyingTo != nil && *tweet.ReplyingTo != "" {
+			author = *tweet.ReplyingTo
+		}
+		parent, err := c.GetTweet(ctx, author, *tweet.ReplyingToStatus)
+		if IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		thread = append(thread, parent)
+		tweet = parent
+	}
+	slices.Reverse(thread)
+	return thread, nil
+}
+
+// TweetCacheTTL returns how long tweets are cached
+func (c *Client) TweetCacheTTL() time.Duration {
+	return c.fxTwitter.TweetCacheTTL()
+}
+
+// UserCacheTTL returns how long user profiles are cached
+func (c *Client) UserCacheTTL() time.Duration {
+	return c.fxTwitter.UserCacheTTL()
+}
+
+// TimelineCacheTTL returns how long timelines are cached
+func (c *Client) TimelineCacheTTL() time.Duration {
+	return c.nitter.TimelineCacheTTL()
+}
+
+// Dependency is an upstream the client relies on
+type Dependency struct {
+	// Name is the Nitter instance URL, or "fxtwitter"
+	Name string
+	// Group is "nitter" or "fxtwitter"; a group is usable when any of its
+	// dependencies is
+	Group string
+	// Probe returns nil when the dependency can serve requests
+	Probe func(ctx context.Context) error
+	// CircuitState returns the state of the circuit breaker
+	CircuitState func() string
+}
+
+// Dependencies returns a probe for every Nitter instance and for FxTwitter.
+// The probes fetch probeUser, bypassing the cache; a missing user still
+// counts as healthy.
+func (c *Client) Dependencies(probeUser string) []Dependency {
+	var deps []Dependency
+	for _, instance := range c.nitter.Instances() {
+		deps = append(deps, Dependency{
+			Name:  instance,
+			Group: "nitter",
+			Probe: func(ctx context.Context) error {
+				return c.nitter.Probe(ctx, instance, probeUser)
+			},
+			CircuitState: func() string {
+				return c.nitter.BreakerState(instance)
+			},
+		})
+	}
+	deps = append(deps, Dependency{
+		Name:  "fxtwitter",
+		Group: "fxtwitter",
+		Probe: func(ctx context.Context) error {
+			return c.fxTwitter.Probe(ctx, probeUser)
+		},
+		CircuitState: c.fxTwitter.BreakerState,
+	})
+	return deps
+}
+
+// Close releases idle upstream connections
+func (c *Client) Close() {
+	if closer, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
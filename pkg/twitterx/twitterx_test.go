@@ -0,0 +1,248 @@
+package twitterx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>jack</title>
+    <item>
+      <guid>https://nitter.net/jack/status/20#m</guid>
+      <pubDate>Tue, 21 Mar 2006 20:50:14 GMT</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+func newFxTwitter(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/jack":
+			w.Write([]byte(`{"code":200,"message":"OK","user":{"screen_name":"jack","id":"12"}}`))
+		case "/jack/status/20":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"20","text":"just setting up my twttr"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientGetUserAndTweet(t *testing.T) {
+	var calls atomic.Int32
+	fx := newFxTwitter(t, &calls)
+
+	client, err := New(WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer client.Close()
+
+	user, err := client.GetUser(context.Background(), "jack")
+	if err != nil || user.ID != "12" {
+		t.Fatalf("expected user 12, got %+v, %v", user, err)
+	}
+	tweet, err := client.GetTweet(context.Background(), "jack", "20")
+	if err != nil || tweet.Text != "just setting up my twttr" {
+		t.Fatalf("expected tweet 20, got %+v, %v", tweet, err)
+	}
+
+	// Served from the cache
+	if _, err := client.GetUser(context.Background(), "JACK"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", calls.Load())
+	}
+
+	// FetchTweet bypasses the cache
+	if _, err := client.FetchTweet(context.Background(), "jack", "20"); err != nil {
+		t.Fatalf("FetchTweet: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected FetchTweet to call FxTwitter, got %d upstream calls", calls.Load())
+	}
+}
+
+func TestClientGetUserWithPinnedTweet(t *testing.T) {
+	var calls, nitterCalls atomic.Int32
+	fx := newFxTwitter(t, &calls)
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nitterCalls.Add(1)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>jack</title>` +
+			`<item><title>Pinned: hello</title><guid>https://nitter.net/jack/status/10#m</guid></item>` +
+			`<item><title>news</title><guid>https://nitter.net/jack/status/20#m</guid></item>` +
+			`</channel></rss>`))
+	}))
+	defer nitter.Close()
+
+	client, err := New(WithNitterInstances(nitter.URL), WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// Profiles don't wait on Nitter: the pinned tweet comes from a cached
+	// timeline only
+	user, err := client.GetUserWithPinnedTweet(context.Background(), "jack")
+	if err != nil || user.PinnedTweetID != "" || nitterCalls.Load() != 0 {
+		t.Fatalf("expected no pinned tweet and no Nitter call, got %+v, %v, %d calls", user, err, nitterCalls.Load())
+	}
+	if _, err := client.GetTimeline(context.Background(), "jack"); err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	user, err = client.GetUserWithPinnedTweet(context.Background(), "JACK")
+	if err != nil || user.PinnedTweetID != "10" {
+		t.Fatalf("expected pinned tweet 10, got %+v, %v", user, err)
+	}
+	// The cached profile is left alone
+	if cached, _ := client.GetUser(context.Background(), "jack"); cached.PinnedTweetID != "" {
+		t.Fatalf("the cached profile was modified: %+v", cached)
+	}
+
+	// Without Nitter the profile comes alone
+	client, err = New(WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	user, err = client.GetUserWithPinnedTweet(context.Background(), "jack")
+	if err != nil || user.ID != "12" || user.PinnedTweetID != "" {
+		t.Fatalf("expected user 12 without a pinned tweet, got %+v, %v", user, err)
+	}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	var calls atomic.Int32
+	fx := newFxTwitter(t, &calls)
+	client, err := New(WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = client.GetUser(context.Background(), "missing")
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) || !IsNotFound(err) || ErrorClass(err) != "not_found" || HTTPStatusCode(err) != http.StatusNotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	_, err = client.GetTweet(context.Background(), "", "20")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	_, err = client.GetTimeline(context.Background(), "jack")
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) {
+		t.Fatalf("expected an upstream error without Nitter instances, got %v", err)
+	}
+}
+
+func TestClientGetTimeline(t *testing.T) {
+	var failures atomic.Int32
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(testRSS))
+	}))
+	defer nitter.Close()
+
+	client, err := New(
+		WithNitterInstances(nitter.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	timeline, err := client.GetTimeline(context.Background(), "jack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(timeline.TweetIDs) != 1 || timeline.TweetIDs[0] != "20" {
+		t.Fatalf("unexpected tweet IDs %v", timeline.TweetIDs)
+	}
+	if want := time.Date(2006, time.March, 21, 20, 50, 14, 0, time.UTC); !timeline.LastModified.Equal(want) {
+		t.Fatalf("expected last modified %s, got %s", want, timeline.LastModified)
+	}
+}
+
+func TestClientDependencies(t *testing.T) {
+	var calls atomic.Int32
+	fx := newFxTwitter(t, &calls)
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer nitter.Close()
+
+	client, err := New(
+		WithNitterInstances(nitter.URL),
+		WithFxTwitterURL(fx.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithCircuitBreaker(1, time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	deps := client.Dependencies("jack")
+	if len(deps) != 2 || deps[0].Group != "nitter" || deps[1].Name != "fxtwitter" {
+		t.Fatalf("unexpected dependencies %+v", deps)
+	}
+	if err := deps[0].Probe(context.Background()); err == nil {
+		t.Fatal("expected the Nitter probe to fail")
+	}
+	if state := deps[0].CircuitState(); state != CircuitOpen {
+		t.Fatalf("expected an open circuit, got %s", state)
+	}
+	if err := deps[1].Probe(context.Background()); err != nil {
+		t.Fatalf("expected the FxTwitter probe to succeed, got %v", err)
+	}
+}
+
+func TestNewRejectsInvalidProxy(t *testing.T) {
+	_, err := New(WithTransport(TransportConfig{Proxy: "ftp://proxy"}))
+	if err == nil || !strings.Contains(err.Error(), "proxy") {
+		t.Fatalf("expected a proxy error, got %v", err)
+	}
+}
+
+func TestClientGetThread(t *testing.T) {
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jack/status/3":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"3","replying_to":"jack","replying_to_status":"2"}}`))
+		case "/jack/status/2":
+			w.Write([]byte(`{"code":200,"message":"OK","tweet":{"id":"2","replying_to":"biz","replying_to_status":"1"}}`))
+		default:
+			// The root tweet was deleted
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"NOT_FOUND"}`))
+		}
+	}))
+	defer fx.Close()
+
+	client, err := New(WithFxTwitterURL(fx.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	thread, err := client.GetThread(context.Background(), "jack", "3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(thread) != 2 || thread[0].ID != "2" || thread[1].ID != "3" {
+		t.Fatalf("unexpected thread %+v", thread)
+	}
+}
@@ -0,0 +1,55 @@
+package twitterx
+
+import (
+	"twitterx-api/internal/httpclient"
+	"twitterx-api/internal/metrics"
+	"twitterx-api/internal/models"
+	"twitterx-api/internal/service"
+)
+
+// Data types returned by the client
+type (
+	Tweet         = models.Tweet
+	Author        = models.Author
+	Media         = models.Media
+	MediaItem     = models.MediaItem
+	Photo         = models.Photo
+	Video         = models.Video
+	MosaicInfo    = models.MosaicInfo
+	ExternalMedia = models.ExternalMedia
+	Poll          = models.Poll
+	PollChoice    = models.PollChoice
+	Translation   = models.Translation
+	User          = models.User
+	Verification  = models.Verification
+	TwitterTime   = models.TwitterTime
+	Entities      = models.Entities
+	Hashtag       = models.Hashtag
+	Mention       = models.Mention
+	URLEntity     = models.URLEntity
+	Cashtag       = models.Cashtag
+	TimelineTweet = models.TimelineTweet
+
+	// Timeline lists the recent tweets of a user as published by Nitter
+	Timeline = service.Timeline
+)
+
+// Configuration types accepted by the options
+type (
+	// RetryPolicy controls how failed upstream calls are retried
+	RetryPolicy = httpclient.RetryPolicy
+	// TransportConfig configures the HTTP transport used for upstream calls
+	TransportConfig = httpclient.TransportConfig
+	// MetricsRecorder receives upstream, cache and circuit breaker metrics
+	MetricsRecorder = metrics.Recorder
+)
+
+// DefaultRetryPolicy retries twice with a short jittered backoff
+var DefaultRetryPolicy = httpclient.DefaultRetryPolicy
+
+// Circuit breaker states reported by Dependency.CircuitState
+const (
+	CircuitClosed   = httpclient.StateClosed
+	CircuitOpen     = httpclient.StateOpen
+	CircuitHalfOpen = httpclient.StateHalfOpen
+)
@@ -0,0 +1,99 @@
+package twitterx
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Watch polls the timeline of username every interval and calls fn with
+// every tweet newer than the newest one seen, oldest first. Tweets on the
+// timeline when Watch starts are not reported, and neither is the pinned
+// tweet, which Nitter lists first whatever its age. Watch returns when ctx is
+// done, when the user doesn't exist or when fn returns an error; other
+// upstream errors are retried at the next poll.
+func (c *Client) Watch(ctx context.Context, username string, interval time.Duration, fn func(*Tweet) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	newest := ""
+	started := false
+	for {
+		timeline, err := c.GetTimeline(ctx, username)
+		switch {
+		case IsNotFound(err):
+			return err
+		case err != nil:
+			// Transient failure, try again at the next poll
+		case !started:
+			newest = newestID(timeline.UnpinnedTweetIDs())
+			started = true
+		default:
+			if newest, err = c.emitNewer(ctx, username, timeline.UnpinnedTweetIDs(), newest, fn); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// emitNewer calls fn with the tweets of ids newer than newest, oldest
+// first, and returns the newest ID passed on. It stops quietly at the first
+// tweet that fails to load, so the next poll retries it.
+func (c *Client) emitNewer(ctx context.Context, username string, ids []string, newest string, fn func(*Tweet) error) (string, error) {
+	var fresh []string
+	for _, id := range ids {
+		if compareIDs(id, newest) > 0 {
+			fresh = append(fresh, id)
+		}
+	}
+	slices.SortFunc(fresh, compareIDs)
+
+	for _, id := range fresh {
+		tweet, err := c.GetTweet(ctx, username, id)
+		if IsNotFound(err) {
+			// Deleted before it could be loaded
+			newest = id
+			continue
+		}
+		if err != nil {
+			return newest, nil
+		}
+		if err := fn(tweet); err != nil {
+			return newest, err
+		}
+		newest = id
+	}
+	return newest, nil
+}
+
+// newestID returns the largest of ids, or "" for none
+func newestID(ids []string) string {
+	newest := ""
+	for _, id := range ids {
+		if compareIDs(id, newest) > 0 {
+			newest = id
+		}
+	}
+	return newest
+}
+
+// compareIDs orders tweet IDs numerically. IDs are decimal without leading
+// zeros, so a longer ID is the larger one.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
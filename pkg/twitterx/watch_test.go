@@ -0,0 +1,112 @@
+package twitterx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientWatch(t *testing.T) {
+	polls := [][]string{
+		{"20"},
+		{"20"},
+		// 9 is older than anything seen, like a retweet of an old tweet
+		{"22", "21", "20", "9"},
+	}
+	var poll atomic.Int32
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := polls[min(int(poll.Add(1))-1, len(polls)-1)]
+		var items strings.Builder
+		for _, id := range ids {
+			fmt.Fprintf(&items, "<item><guid>https://nitter.net/jack/status/%s#m</guid></item>", id)
+		}
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>jack</title>%s</channel></rss>`, items.String())
+	}))
+	defer nitter.Close()
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q}}`, id)
+	}))
+	defer fx.Close()
+
+	client, err := New(
+		WithNitterInstances(nitter.URL),
+		WithFxTwitterURL(fx.URL),
+		WithTimelineCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	stop := errors.New("stop")
+	var got []string
+	err = client.Watch(context.Background(), "jack", time.Millisecond, func(tweet *Tweet) error {
+		got = append(got, tweet.ID)
+		if len(got) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if strings.Join(got, ",") != "21,22" {
+		t.Fatalf("expected new tweets 21,22, got %v", got)
+	}
+}
+
+func TestClientWatchSkipsPinnedTweet(t *testing.T) {
+	polls := []string{
+		`<item><title>hello</title><guid>https://nitter.net/jack/status/20#m</guid></item>`,
+		`<item><title>Pinned: news</title><guid>https://nitter.net/jack/status/21#m</guid></item>` +
+			`<item><title>hello</title><guid>https://nitter.net/jack/status/20#m</guid></item>`,
+		`<item><title>Pinned: news</title><guid>https://nitter.net/jack/status/21#m</guid></item>` +
+			`<item><title>again</title><guid>https://nitter.net/jack/status/22#m</guid></item>` +
+			`<item><title>hello</title><guid>https://nitter.net/jack/status/20#m</guid></item>`,
+	}
+	var poll atomic.Int32
+	nitter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := polls[min(int(poll.Add(1))-1, len(polls)-1)]
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>jack</title>%s</channel></rss>`, items)
+	}))
+	defer nitter.Close()
+	fx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		fmt.Fprintf(w, `{"code":200,"message":"OK","tweet":{"id":%q}}`, id)
+	}))
+	defer fx.Close()
+
+	client, err := New(
+		WithNitterInstances(nitter.URL),
+		WithFxTwitterURL(fx.URL),
+		WithTimelineCacheTTL(0),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	stop := errors.New("stop")
+	var got []string
+	err = client.Watch(context.Background(), "jack", time.Millisecond, func(tweet *Tweet) error {
+		got = append(got, tweet.ID)
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if strings.Join(got, ",") != "22" {
+		t.Fatalf("expected the pinned tweet to be skipped, got %v", got)
+	}
+}
+
+func TestCompareIDs(t *testing.T) {
+	if compareIDs("9", "10") >= 0 || compareIDs("21", "20") <= 0 || compareIDs("20", "20") != 0 || compareIDs("1", "") <= 0 {
+		t.Fatal("IDs must compare numerically")
+	}
+}
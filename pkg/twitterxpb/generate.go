@@ -0,0 +1,5 @@
+// Package twitterxpb holds the protobuf messages and gRPC stubs of the
+// TwitterX API, generated from proto/twitterx/v1/twitterx.proto.
+package twitterxpb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=twitterx-api/pkg/twitterxpb --go-grpc_out=. --go-grpc_opt=module=twitterx-api/pkg/twitterxpb twitterx/v1/twitterx.proto
@@ -0,0 +1,2202 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v5.29.3
+// source: twitterx/v1/twitterx.proto
+
+package twitterxpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName    string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetUserRequest) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+type GetTweetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// screen_name of the author; optional, tweets are found by ID alone
+	ScreenName    string `protobuf:"bytes,2,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTweetRequest) Reset() {
+	*x = GetTweetRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTweetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTweetRequest) ProtoMessage() {}
+
+func (x *GetTweetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTweetRequest.ProtoReflect.Descriptor instead.
+func (*GetTweetRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetTweetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetTweetRequest) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+type BatchGetTweetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetTweetsRequest) Reset() {
+	*x = BatchGetTweetsRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetTweetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetTweetsRequest) ProtoMessage() {}
+
+func (x *BatchGetTweetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetTweetsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetTweetsRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetTweetsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetTweetsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tweets holds one result per requested ID, in request order
+	Tweets        []*TweetResult `protobuf:"bytes,1,rep,name=tweets,proto3" json:"tweets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetTweetsResponse) Reset() {
+	*x = BatchGetTweetsResponse{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetTweetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetTweetsResponse) ProtoMessage() {}
+
+func (x *BatchGetTweetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetTweetsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetTweetsResponse) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetTweetsResponse) GetTweets() []*TweetResult {
+	if x != nil {
+		return x.Tweets
+	}
+	return nil
+}
+
+type TweetResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*TweetResult_Tweet
+	//	*TweetResult_Error
+	Result        isTweetResult_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TweetResult) Reset() {
+	*x = TweetResult{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TweetResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TweetResult) ProtoMessage() {}
+
+func (x *TweetResult) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TweetResult.ProtoReflect.Descriptor instead.
+func (*TweetResult) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TweetResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TweetResult) GetResult() isTweetResult_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *TweetResult) GetTweet() *Tweet {
+	if x != nil {
+		if x, ok := x.Result.(*TweetResult_Tweet); ok {
+			return x.Tweet
+		}
+	}
+	return nil
+}
+
+func (x *TweetResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*TweetResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isTweetResult_Result interface {
+	isTweetResult_Result()
+}
+
+type TweetResult_Tweet struct {
+	Tweet *Tweet `protobuf:"bytes,2,opt,name=tweet,proto3,oneof"`
+}
+
+type TweetResult_Error struct {
+	// error is set when the tweet could not be fetched
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*TweetResult_Tweet) isTweetResult_Result() {}
+
+func (*TweetResult_Error) isTweetResult_Result() {}
+
+// Error describes a failed item of a batch
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// class is a stable error name, such as "not_found" or "timeout"
+	Class         string `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Error) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetTimelineRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	// cursor is the next_cursor of the previous page; empty for the first page
+	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTimelineRequest) Reset() {
+	*x = GetTimelineRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTimelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTimelineRequest) ProtoMessage() {}
+
+func (x *GetTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTimelineRequest.ProtoReflect.Descriptor instead.
+func (*GetTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTimelineRequest) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *GetTimelineRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type Timeline struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	TweetIds   []string               `protobuf:"bytes,2,rep,name=tweet_ids,json=tweetIds,proto3" json:"tweet_ids,omitempty"`
+	NextCursor string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// pinned_tweet_id is the tweet of tweet_ids pinned to the profile, which
+	// comes first whatever its age; empty when none is
+	PinnedTweetId string `protobuf:"bytes,4,opt,name=pinned_tweet_id,json=pinnedTweetId,proto3" json:"pinned_tweet_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Timeline) Reset() {
+	*x = Timeline{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timeline) ProtoMessage() {}
+
+func (x *Timeline) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timeline.ProtoReflect.Descriptor instead.
+func (*Timeline) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Timeline) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *Timeline) GetTweetIds() []string {
+	if x != nil {
+		return x.TweetIds
+	}
+	return nil
+}
+
+func (x *Timeline) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *Timeline) GetPinnedTweetId() string {
+	if x != nil {
+		return x.PinnedTweetId
+	}
+	return ""
+}
+
+type WatchUserRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	// poll_interval_seconds is how often the timeline is checked; it is
+	// raised to the server minimum
+	PollIntervalSeconds int32 `protobuf:"varint,2,opt,name=poll_interval_seconds,json=pollIntervalSeconds,proto3" json:"poll_interval_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *WatchUserRequest) Reset() {
+	*x = WatchUserRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUserRequest) ProtoMessage() {}
+
+func (x *WatchUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchUserRequest.ProtoReflect.Descriptor instead.
+func (*WatchUserRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchUserRequest) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *WatchUserRequest) GetPollIntervalSeconds() int32 {
+	if x != nil {
+		return x.PollIntervalSeconds
+	}
+	return 0
+}
+
+type WatchDeletionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// screen_name of a watched account; empty streams the deletions of every
+	// watched account
+	ScreenName    string `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchDeletionsRequest) Reset() {
+	*x = WatchDeletionsRequest{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchDeletionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchDeletionsRequest) ProtoMessage() {}
+
+func (x *WatchDeletionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchDeletionsRequest.ProtoReflect.Descriptor instead.
+func (*WatchDeletionsRequest) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchDeletionsRequest) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+// DeletedTweet is a tweet of a watched account that was deleted, with its
+// last known content
+type DeletedTweet struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ScreenName string                 `protobuf:"bytes,2,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// last_seen is the last time the tweet was fetched, or the time it was
+	// first listed in the feed
+	LastSeen   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	DetectedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=detected_at,json=detectedAt,proto3" json:"detected_at,omitempty"`
+	// text and entities are from the Nitter feed
+	Text     string    `protobuf:"bytes,6,opt,name=text,proto3" json:"text,omitempty"`
+	Entities *Entities `protobuf:"bytes,7,opt,name=entities,proto3" json:"entities,omitempty"`
+	// tweet is the last copy fetched from FxTwitter; unset when the tweet was
+	// deleted before it could be fetched
+	Tweet         *Tweet `protobuf:"bytes,8,opt,name=tweet,proto3" json:"tweet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletedTweet) Reset() {
+	*x = DeletedTweet{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletedTweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletedTweet) ProtoMessage() {}
+
+func (x *DeletedTweet) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletedTweet.ProtoReflect.Descriptor instead.
+func (*DeletedTweet) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeletedTweet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeletedTweet) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *DeletedTweet) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *DeletedTweet) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *DeletedTweet) GetDetectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DetectedAt
+	}
+	return nil
+}
+
+func (x *DeletedTweet) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *DeletedTweet) GetEntities() *Entities {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *DeletedTweet) GetTweet() *Tweet {
+	if x != nil {
+		return x.Tweet
+	}
+	return nil
+}
+
+type User struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ScreenName   string                 `protobuf:"bytes,1,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	Url          string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Id           string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Followers    int64                  `protobuf:"varint,4,opt,name=followers,proto3" json:"followers,omitempty"`
+	Following    int64                  `protobuf:"varint,5,opt,name=following,proto3" json:"following,omitempty"`
+	Likes        int64                  `protobuf:"varint,6,opt,name=likes,proto3" json:"likes,omitempty"`
+	MediaCount   int64                  `protobuf:"varint,7,opt,name=media_count,json=mediaCount,proto3" json:"media_count,omitempty"`
+	Tweets       int64                  `protobuf:"varint,8,opt,name=tweets,proto3" json:"tweets,omitempty"`
+	Name         string                 `protobuf:"bytes,9,opt,name=name,proto3" json:"name,omitempty"`
+	Description  string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	Location     string                 `protobuf:"bytes,11,opt,name=location,proto3" json:"location,omitempty"`
+	BannerUrl    string                 `protobuf:"bytes,12,opt,name=banner_url,json=bannerUrl,proto3" json:"banner_url,omitempty"`
+	AvatarUrl    string                 `protobuf:"bytes,13,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	Joined       string                 `protobuf:"bytes,14,opt,name=joined,proto3" json:"joined,omitempty"`
+	Protected    bool                   `protobuf:"varint,15,opt,name=protected,proto3" json:"protected,omitempty"`
+	Website      *string                `protobuf:"bytes,16,opt,name=website,proto3,oneof" json:"website,omitempty"`
+	Verification *Verification          `protobuf:"bytes,17,opt,name=verification,proto3" json:"verification,omitempty"`
+	// pinned_tweet_id is the tweet pinned to the profile; empty when none is
+	// or the timeline of the user is not cached
+	PinnedTweetId string `protobuf:"bytes,18,opt,name=pinned_tweet_id,json=pinnedTweetId,proto3" json:"pinned_tweet_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *User) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *User) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetFollowers() int64 {
+	if x != nil {
+		return x.Followers
+	}
+	return 0
+}
+
+func (x *User) GetFollowing() int64 {
+	if x != nil {
+		return x.Following
+	}
+	return 0
+}
+
+func (x *User) GetLikes() int64 {
+	if x != nil {
+		return x.Likes
+	}
+	return 0
+}
+
+func (x *User) GetMediaCount() int64 {
+	if x != nil {
+		return x.MediaCount
+	}
+	return 0
+}
+
+func (x *User) GetTweets() int64 {
+	if x != nil {
+		return x.Tweets
+	}
+	return 0
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *User) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *User) GetBannerUrl() string {
+	if x != nil {
+		return x.BannerUrl
+	}
+	return ""
+}
+
+func (x *User) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *User) GetJoined() string {
+	if x != nil {
+		return x.Joined
+	}
+	return ""
+}
+
+func (x *User) GetProtected() bool {
+	if x != nil {
+		return x.Protected
+	}
+	return false
+}
+
+func (x *User) GetWebsite() string {
+	if x != nil && x.Website != nil {
+		return *x.Website
+	}
+	return ""
+}
+
+func (x *User) GetVerification() *Verification {
+	if x != nil {
+		return x.Verification
+	}
+	return nil
+}
+
+func (x *User) GetPinnedTweetId() string {
+	if x != nil {
+		return x.PinnedTweetId
+	}
+	return ""
+}
+
+type Verification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Verified      bool                   `protobuf:"varint,1,opt,name=verified,proto3" json:"verified,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Verification) Reset() {
+	*x = Verification{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Verification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Verification) ProtoMessage() {}
+
+func (x *Verification) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Verification.ProtoReflect.Descriptor instead.
+func (*Verification) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Verification) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Verification) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type Tweet struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Url               string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Id                string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Text              string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Author            *Author                `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Replies           int64                  `protobuf:"varint,5,opt,name=replies,proto3" json:"replies,omitempty"`
+	Retweets          int64                  `protobuf:"varint,6,opt,name=retweets,proto3" json:"retweets,omitempty"`
+	Likes             int64                  `protobuf:"varint,7,opt,name=likes,proto3" json:"likes,omitempty"`
+	Views             *int64                 `protobuf:"varint,8,opt,name=views,proto3,oneof" json:"views,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	PossiblyScam      bool                   `protobuf:"varint,10,opt,name=possibly_scam,json=possiblyScam,proto3" json:"possibly_scam,omitempty"`
+	PossiblySensitive bool                   `protobuf:"varint,11,opt,name=possibly_sensitive,json=possiblySensitive,proto3" json:"possibly_sensitive,omitempty"`
+	Lang              string                 `protobuf:"bytes,12,opt,name=lang,proto3" json:"lang,omitempty"`
+	Source            string                 `protobuf:"bytes,13,opt,name=source,proto3" json:"source,omitempty"`
+	ReplyingTo        *string                `protobuf:"bytes,14,opt,name=replying_to,json=replyingTo,proto3,oneof" json:"replying_to,omitempty"`
+	ReplyingToStatus  *string                `protobuf:"bytes,15,opt,name=replying_to_status,json=replyingToStatus,proto3,oneof" json:"replying_to_status,omitempty"`
+	Media             []*MediaItem           `protobuf:"bytes,16,rep,name=media,proto3" json:"media,omitempty"`
+	Poll              *Poll                  `protobuf:"bytes,17,opt,name=poll,proto3" json:"poll,omitempty"`
+	Quote             *Tweet                 `protobuf:"bytes,18,opt,name=quote,proto3" json:"quote,omitempty"`
+	Translation       *Translation           `protobuf:"bytes,19,opt,name=translation,proto3" json:"translation,omitempty"`
+	Entities          *Entities              `protobuf:"bytes,20,opt,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Tweet) Reset() {
+	*x = Tweet{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tweet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tweet) ProtoMessage() {}
+
+func (x *Tweet) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tweet.ProtoReflect.Descriptor instead.
+func (*Tweet) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Tweet) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Tweet) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Tweet) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Tweet) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Tweet) GetReplies() int64 {
+	if x != nil {
+		return x.Replies
+	}
+	return 0
+}
+
+func (x *Tweet) GetRetweets() int64 {
+	if x != nil {
+		return x.Retweets
+	}
+	return 0
+}
+
+func (x *Tweet) GetLikes() int64 {
+	if x != nil {
+		return x.Likes
+	}
+	return 0
+}
+
+func (x *Tweet) GetViews() int64 {
+	if x != nil && x.Views != nil {
+		return *x.Views
+	}
+	return 0
+}
+
+func (x *Tweet) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Tweet) GetPossiblyScam() bool {
+	if x != nil {
+		return x.PossiblyScam
+	}
+	return false
+}
+
+func (x *Tweet) GetPossiblySensitive() bool {
+	if x != nil {
+		return x.PossiblySensitive
+	}
+	return false
+}
+
+func (x *Tweet) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *Tweet) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Tweet) GetReplyingTo() string {
+	if x != nil && x.ReplyingTo != nil {
+		return *x.ReplyingTo
+	}
+	return ""
+}
+
+func (x *Tweet) GetReplyingToStatus() string {
+	if x != nil && x.ReplyingToStatus != nil {
+		return *x.ReplyingToStatus
+	}
+	return ""
+}
+
+func (x *Tweet) GetMedia() []*MediaItem {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+func (x *Tweet) GetPoll() *Poll {
+	if x != nil {
+		return x.Poll
+	}
+	return nil
+}
+
+func (x *Tweet) GetQuote() *Tweet {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+func (x *Tweet) GetTranslation() *Translation {
+	if x != nil {
+		return x.Translation
+	}
+	return nil
+}
+
+func (x *Tweet) GetEntities() *Entities {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+type Author struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ScreenName    string                 `protobuf:"bytes,3,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,4,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	BannerUrl     *string                `protobuf:"bytes,5,opt,name=banner_url,json=bannerUrl,proto3,oneof" json:"banner_url,omitempty"`
+	Description   *string                `protobuf:"bytes,6,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Location      *string                `protobuf:"bytes,7,opt,name=location,proto3,oneof" json:"location,omitempty"`
+	Url           *string                `protobuf:"bytes,8,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	Followers     *int64                 `protobuf:"varint,9,opt,name=followers,proto3,oneof" json:"followers,omitempty"`
+	Following     *int64                 `protobuf:"varint,10,opt,name=following,proto3,oneof" json:"following,omitempty"`
+	Joined        *string                `protobuf:"bytes,11,opt,name=joined,proto3,oneof" json:"joined,omitempty"`
+	Likes         *int64                 `protobuf:"varint,12,opt,name=likes,proto3,oneof" json:"likes,omitempty"`
+	Tweets        *int64                 `protobuf:"varint,13,opt,name=tweets,proto3,oneof" json:"tweets,omitempty"`
+	Verified      bool                   `protobuf:"varint,14,opt,name=verified,proto3" json:"verified,omitempty"`
+	BlueBadge     bool                   `protobuf:"varint,15,opt,name=blue_badge,json=blueBadge,proto3" json:"blue_badge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Author) Reset() {
+	*x = Author{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Author) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Author) ProtoMessage() {}
+
+func (x *Author) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Author.ProtoReflect.Descriptor instead.
+func (*Author) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Author) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Author) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Author) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *Author) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *Author) GetBannerUrl() string {
+	if x != nil && x.BannerUrl != nil {
+		return *x.BannerUrl
+	}
+	return ""
+}
+
+func (x *Author) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Author) GetLocation() string {
+	if x != nil && x.Location != nil {
+		return *x.Location
+	}
+	return ""
+}
+
+func (x *Author) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *Author) GetFollowers() int64 {
+	if x != nil && x.Followers != nil {
+		return *x.Followers
+	}
+	return 0
+}
+
+func (x *Author) GetFollowing() int64 {
+	if x != nil && x.Following != nil {
+		return *x.Following
+	}
+	return 0
+}
+
+func (x *Author) GetJoined() string {
+	if x != nil && x.Joined != nil {
+		return *x.Joined
+	}
+	return ""
+}
+
+func (x *Author) GetLikes() int64 {
+	if x != nil && x.Likes != nil {
+		return *x.Likes
+	}
+	return 0
+}
+
+func (x *Author) GetTweets() int64 {
+	if x != nil && x.Tweets != nil {
+		return *x.Tweets
+	}
+	return 0
+}
+
+func (x *Author) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Author) GetBlueBadge() bool {
+	if x != nil {
+		return x.BlueBadge
+	}
+	return false
+}
+
+type MediaItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Format        string                 `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	ThumbnailUrl  string                 `protobuf:"bytes,6,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	Duration      *float64               `protobuf:"fixed64,7,opt,name=duration,proto3,oneof" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MediaItem) Reset() {
+	*x = MediaItem{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediaItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediaItem) ProtoMessage() {}
+
+func (x *MediaItem) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediaItem.ProtoReflect.Descriptor instead.
+func (*MediaItem) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MediaItem) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MediaItem) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *MediaItem) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *MediaItem) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *MediaItem) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *MediaItem) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *MediaItem) GetDuration() float64 {
+	if x != nil && x.Duration != nil {
+		return *x.Duration
+	}
+	return 0
+}
+
+type Poll struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalVotes    int64                  `protobuf:"varint,1,opt,name=total_votes,json=totalVotes,proto3" json:"total_votes,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	TimeRemaining string                 `protobuf:"bytes,3,opt,name=time_remaining,json=timeRemaining,proto3" json:"time_remaining,omitempty"`
+	Choices       []*PollChoice          `protobuf:"bytes,4,rep,name=choices,proto3" json:"choices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Poll) Reset() {
+	*x = Poll{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Poll) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Poll) ProtoMessage() {}
+
+func (x *Poll) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Poll.ProtoReflect.Descriptor instead.
+func (*Poll) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Poll) GetTotalVotes() int64 {
+	if x != nil {
+		return x.TotalVotes
+	}
+	return 0
+}
+
+func (x *Poll) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *Poll) GetTimeRemaining() string {
+	if x != nil {
+		return x.TimeRemaining
+	}
+	return ""
+}
+
+func (x *Poll) GetChoices() []*PollChoice {
+	if x != nil {
+		return x.Choices
+	}
+	return nil
+}
+
+type PollChoice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Percentage    int32                  `protobuf:"varint,3,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollChoice) Reset() {
+	*x = PollChoice{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollChoice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollChoice) ProtoMessage() {}
+
+func (x *PollChoice) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollChoice.ProtoReflect.Descriptor instead.
+func (*PollChoice) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PollChoice) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *PollChoice) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *PollChoice) GetPercentage() int32 {
+	if x != nil {
+		return x.Percentage
+	}
+	return 0
+}
+
+type Translation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Text           string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	SourceLang     string                 `protobuf:"bytes,2,opt,name=source_lang,json=sourceLang,proto3" json:"source_lang,omitempty"`
+	TargetLang     string                 `protobuf:"bytes,3,opt,name=target_lang,json=targetLang,proto3" json:"target_lang,omitempty"`
+	TranslationUrl string                 `protobuf:"bytes,4,opt,name=translation_url,json=translationUrl,proto3" json:"translation_url,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Translation) Reset() {
+	*x = Translation{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Translation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Translation) ProtoMessage() {}
+
+func (x *Translation) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Translation.ProtoReflect.Descriptor instead.
+func (*Translation) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Translation) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Translation) GetSourceLang() string {
+	if x != nil {
+		return x.SourceLang
+	}
+	return ""
+}
+
+func (x *Translation) GetTargetLang() string {
+	if x != nil {
+		return x.TargetLang
+	}
+	return ""
+}
+
+func (x *Translation) GetTranslationUrl() string {
+	if x != nil {
+		return x.TranslationUrl
+	}
+	return ""
+}
+
+// Hashtags, mentions, URLs and cashtags of a tweet text. Offsets count
+// Unicode code points, the end is exclusive.
+type Entities struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hashtags      []*Hashtag             `protobuf:"bytes,1,rep,name=hashtags,proto3" json:"hashtags,omitempty"`
+	Mentions      []*Mention             `protobuf:"bytes,2,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	Urls          []*UrlEntity           `protobuf:"bytes,3,rep,name=urls,proto3" json:"urls,omitempty"`
+	Cashtags      []*Cashtag             `protobuf:"bytes,4,rep,name=cashtags,proto3" json:"cashtags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entities) Reset() {
+	*x = Entities{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entities) ProtoMessage() {}
+
+func (x *Entities) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entities.ProtoReflect.Descriptor instead.
+func (*Entities) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Entities) GetHashtags() []*Hashtag {
+	if x != nil {
+		return x.Hashtags
+	}
+	return nil
+}
+
+func (x *Entities) GetMentions() []*Mention {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+func (x *Entities) GetUrls() []*UrlEntity {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *Entities) GetCashtags() []*Cashtag {
+	if x != nil {
+		return x.Cashtags
+	}
+	return nil
+}
+
+type Hashtag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hashtag) Reset() {
+	*x = Hashtag{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hashtag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hashtag) ProtoMessage() {}
+
+func (x *Hashtag) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hashtag.ProtoReflect.Descriptor instead.
+func (*Hashtag) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Hashtag) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Hashtag) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Hashtag) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type Mention struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	ScreenName    string                 `protobuf:"bytes,3,opt,name=screen_name,json=screenName,proto3" json:"screen_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mention) Reset() {
+	*x = Mention{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mention) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mention) ProtoMessage() {}
+
+func (x *Mention) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mention.ProtoReflect.Descriptor instead.
+func (*Mention) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Mention) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Mention) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Mention) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+type UrlEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	ExpandedUrl   string                 `protobuf:"bytes,4,opt,name=expanded_url,json=expandedUrl,proto3" json:"expanded_url,omitempty"`
+	DisplayUrl    string                 `protobuf:"bytes,5,opt,name=display_url,json=displayUrl,proto3" json:"display_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UrlEntity) Reset() {
+	*x = UrlEntity{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UrlEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UrlEntity) ProtoMessage() {}
+
+func (x *UrlEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UrlEntity.ProtoReflect.Descriptor instead.
+func (*UrlEntity) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UrlEntity) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *UrlEntity) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *UrlEntity) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *UrlEntity) GetExpandedUrl() string {
+	if x != nil {
+		return x.ExpandedUrl
+	}
+	return ""
+}
+
+func (x *UrlEntity) GetDisplayUrl() string {
+	if x != nil {
+		return x.DisplayUrl
+	}
+	return ""
+}
+
+type Cashtag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cashtag) Reset() {
+	*x = Cashtag{}
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cashtag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cashtag) ProtoMessage() {}
+
+func (x *Cashtag) ProtoReflect() protoreflect.Message {
+	mi := &file_twitterx_v1_twitterx_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cashtag.ProtoReflect.Descriptor instead.
+func (*Cashtag) Descriptor() ([]byte, []int) {
+	return file_twitterx_v1_twitterx_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Cashtag) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Cashtag) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Cashtag) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+var File_twitterx_v1_twitterx_proto protoreflect.FileDescriptor
+
+const file_twitterx_v1_twitterx_proto_rawDesc = "" +
+	"\n" +
+	"\x1atwitterx/v1/twitterx.proto\x12\vtwitterx.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"1\n" +
+	"\x0eGetUserRequest\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\"B\n" +
+	"\x0fGetTweetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vscreen_name\x18\x02 \x01(\tR\n" +
+	"screenName\")\n" +
+	"\x15BatchGetTweetsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"J\n" +
+	"\x16BatchGetTweetsResponse\x120\n" +
+	"\x06tweets\x18\x01 \x03(\v2\x18.twitterx.v1.TweetResultR\x06tweets\"\x7f\n" +
+	"\vTweetResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12*\n" +
+	"\x05tweet\x18\x02 \x01(\v2\x12.twitterx.v1.TweetH\x00R\x05tweet\x12*\n" +
+	"\x05error\x18\x03 \x01(\v2\x12.twitterx.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"7\n" +
+	"\x05Error\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"M\n" +
+	"\x12GetTimelineRequest\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x91\x01\n" +
+	"\bTimeline\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x12\x1b\n" +
+	"\ttweet_ids\x18\x02 \x03(\tR\btweetIds\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\x12&\n" +
+	"\x0fpinned_tweet_id\x18\x04 \x01(\tR\rpinnedTweetId\"g\n" +
+	"\x10WatchUserRequest\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x122\n" +
+	"\x15poll_interval_seconds\x18\x02 \x01(\x05R\x13pollIntervalSeconds\"8\n" +
+	"\x15WatchDeletionsRequest\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\"\xe1\x02\n" +
+	"\fDeletedTweet\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vscreen_name\x18\x02 \x01(\tR\n" +
+	"screenName\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tlast_seen\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12;\n" +
+	"\vdetected_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"detectedAt\x12\x12\n" +
+	"\x04text\x18\x06 \x01(\tR\x04text\x121\n" +
+	"\bentities\x18\a \x01(\v2\x15.twitterx.v1.EntitiesR\bentities\x12(\n" +
+	"\x05tweet\x18\b \x01(\v2\x12.twitterx.v1.TweetR\x05tweet\"\xac\x04\n" +
+	"\x04User\x12\x1f\n" +
+	"\vscreen_name\x18\x01 \x01(\tR\n" +
+	"screenName\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12\x1c\n" +
+	"\tfollowers\x18\x04 \x01(\x03R\tfollowers\x12\x1c\n" +
+	"\tfollowing\x18\x05 \x01(\x03R\tfollowing\x12\x14\n" +
+	"\x05likes\x18\x06 \x01(\x03R\x05likes\x12\x1f\n" +
+	"\vmedia_count\x18\a \x01(\x03R\n" +
+	"mediaCount\x12\x16\n" +
+	"\x06tweets\x18\b \x01(\x03R\x06tweets\x12\x12\n" +
+	"\x04name\x18\t \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12\x1a\n" +
+	"\blocation\x18\v \x01(\tR\blocation\x12\x1d\n" +
+	"\n" +
+	"banner_url\x18\f \x01(\tR\tbannerUrl\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\r \x01(\tR\tavatarUrl\x12\x16\n" +
+	"\x06joined\x18\x0e \x01(\tR\x06joined\x12\x1c\n" +
+	"\tprotected\x18\x0f \x01(\bR\tprotected\x12\x1d\n" +
+	"\awebsite\x18\x10 \x01(\tH\x00R\awebsite\x88\x01\x01\x12=\n" +
+	"\fverification\x18\x11 \x01(\v2\x19.twitterx.v1.VerificationR\fverification\x12&\n" +
+	"\x0fpinned_tweet_id\x18\x12 \x01(\tR\rpinnedTweetIdB\n" +
+	"\n" +
+	"\b_website\">\n" +
+	"\fVerification\x12\x1a\n" +
+	"\bverified\x18\x01 \x01(\bR\bverified\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"\x84\x06\n" +
+	"\x05Tweet\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12+\n" +
+	"\x06author\x18\x04 \x01(\v2\x13.twitterx.v1.AuthorR\x06author\x12\x18\n" +
+	"\areplies\x18\x05 \x01(\x03R\areplies\x12\x1a\n" +
+	"\bretweets\x18\x06 \x01(\x03R\bretweets\x12\x14\n" +
+	"\x05likes\x18\a \x01(\x03R\x05likes\x12\x19\n" +
+	"\x05views\x18\b \x01(\x03H\x00R\x05views\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12#\n" +
+	"\rpossibly_scam\x18\n" +
+	" \x01(\bR\fpossiblyScam\x12-\n" +
+	"\x12possibly_sensitive\x18\v \x01(\bR\x11possiblySensitive\x12\x12\n" +
+	"\x04lang\x18\f \x01(\tR\x04lang\x12\x16\n" +
+	"\x06source\x18\r \x01(\tR\x06source\x12$\n" +
+	"\vreplying_to\x18\x0e \x01(\tH\x01R\n" +
+	"replyingTo\x88\x01\x01\x121\n" +
+	"\x12replying_to_status\x18\x0f \x01(\tH\x02R\x10replyingToStatus\x88\x01\x01\x12,\n" +
+	"\x05media\x18\x10 \x03(\v2\x16.twitterx.v1.MediaItemR\x05media\x12%\n" +
+	"\x04poll\x18\x11 \x01(\v2\x11.twitterx.v1.PollR\x04poll\x12(\n" +
+	"\x05quote\x18\x12 \x01(\v2\x12.twitterx.v1.TweetR\x05quote\x12:\n" +
+	"\vtranslation\x18\x13 \x01(\v2\x18.twitterx.v1.TranslationR\vtranslation\x121\n" +
+	"\bentities\x18\x14 \x01(\v2\x15.twitterx.v1.EntitiesR\bentitiesB\b\n" +
+	"\x06_viewsB\x0e\n" +
+	"\f_replying_toB\x15\n" +
+	"\x13_replying_to_status\"\xb5\x04\n" +
+	"\x06Author\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vscreen_name\x18\x03 \x01(\tR\n" +
+	"screenName\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x04 \x01(\tR\tavatarUrl\x12\"\n" +
+	"\n" +
+	"banner_url\x18\x05 \x01(\tH\x00R\tbannerUrl\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x06 \x01(\tH\x01R\vdescription\x88\x01\x01\x12\x1f\n" +
+	"\blocation\x18\a \x01(\tH\x02R\blocation\x88\x01\x01\x12\x15\n" +
+	"\x03url\x18\b \x01(\tH\x03R\x03url\x88\x01\x01\x12!\n" +
+	"\tfollowers\x18\t \x01(\x03H\x04R\tfollowers\x88\x01\x01\x12!\n" +
+	"\tfollowing\x18\n" +
+	" \x01(\x03H\x05R\tfollowing\x88\x01\x01\x12\x1b\n" +
+	"\x06joined\x18\v \x01(\tH\x06R\x06joined\x88\x01\x01\x12\x19\n" +
+	"\x05likes\x18\f \x01(\x03H\aR\x05likes\x88\x01\x01\x12\x1b\n" +
+	"\x06tweets\x18\r \x01(\x03H\bR\x06tweets\x88\x01\x01\x12\x1a\n" +
+	"\bverified\x18\x0e \x01(\bR\bverified\x12\x1d\n" +
+	"\n" +
+	"blue_badge\x18\x0f \x01(\bR\tblueBadgeB\r\n" +
+	"\v_banner_urlB\x0e\n" +
+	"\f_descriptionB\v\n" +
+	"\t_locationB\x06\n" +
+	"\x04_urlB\f\n" +
+	"\n" +
+	"_followersB\f\n" +
+	"\n" +
+	"_followingB\t\n" +
+	"\a_joinedB\b\n" +
+	"\x06_likesB\t\n" +
+	"\a_tweets\"\xca\x01\n" +
+	"\tMediaItem\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\x12\x16\n" +
+	"\x06format\x18\x05 \x01(\tR\x06format\x12#\n" +
+	"\rthumbnail_url\x18\x06 \x01(\tR\fthumbnailUrl\x12\x1f\n" +
+	"\bduration\x18\a \x01(\x01H\x00R\bduration\x88\x01\x01B\v\n" +
+	"\t_duration\"\xb6\x01\n" +
+	"\x04Poll\x12\x1f\n" +
+	"\vtotal_votes\x18\x01 \x01(\x03R\n" +
+	"totalVotes\x123\n" +
+	"\aends_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12%\n" +
+	"\x0etime_remaining\x18\x03 \x01(\tR\rtimeRemaining\x121\n" +
+	"\achoices\x18\x04 \x03(\v2\x17.twitterx.v1.PollChoiceR\achoices\"X\n" +
+	"\n" +
+	"PollChoice\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x1e\n" +
+	"\n" +
+	"percentage\x18\x03 \x01(\x05R\n" +
+	"percentage\"\x8c\x01\n" +
+	"\vTranslation\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1f\n" +
+	"\vsource_lang\x18\x02 \x01(\tR\n" +
+	"sourceLang\x12\x1f\n" +
+	"\vtarget_lang\x18\x03 \x01(\tR\n" +
+	"targetLang\x12'\n" +
+	"\x0ftranslation_url\x18\x04 \x01(\tR\x0etranslationUrl\"\xcc\x01\n" +
+	"\bEntities\x120\n" +
+	"\bhashtags\x18\x01 \x03(\v2\x14.twitterx.v1.HashtagR\bhashtags\x120\n" +
+	"\bmentions\x18\x02 \x03(\v2\x14.twitterx.v1.MentionR\bmentions\x12*\n" +
+	"\x04urls\x18\x03 \x03(\v2\x16.twitterx.v1.UrlEntityR\x04urls\x120\n" +
+	"\bcashtags\x18\x04 \x03(\v2\x14.twitterx.v1.CashtagR\bcashtags\"C\n" +
+	"\aHashtag\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\"R\n" +
+	"\aMention\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x1f\n" +
+	"\vscreen_name\x18\x03 \x01(\tR\n" +
+	"screenName\"\x89\x01\n" +
+	"\tUrlEntity\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12!\n" +
+	"\fexpanded_url\x18\x04 \x01(\tR\vexpandedUrl\x12\x1f\n" +
+	"\vdisplay_url\x18\x05 \x01(\tR\n" +
+	"displayUrl\"C\n" +
+	"\aCashtag\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag2\xba\x03\n" +
+	"\bTwitterX\x129\n" +
+	"\aGetUser\x12\x1b.twitterx.v1.GetUserRequest\x1a\x11.twitterx.v1.User\x12<\n" +
+	"\bGetTweet\x12\x1c.twitterx.v1.GetTweetRequest\x1a\x12.twitterx.v1.Tweet\x12Y\n" +
+	"\x0eBatchGetTweets\x12\".twitterx.v1.BatchGetTweetsRequest\x1a#.twitterx.v1.BatchGetTweetsResponse\x12E\n" +
+	"\vGetTimeline\x12\x1f.twitterx.v1.GetTimelineRequest\x1a\x15.twitterx.v1.Timeline\x12@\n" +
+	"\tWatchUser\x12\x1d.twitterx.v1.WatchUserRequest\x1a\x12.twitterx.v1.Tweet0\x01\x12Q\n" +
+	"\x0eWatchDeletions\x12\".twitterx.v1.WatchDeletionsRequest\x1a\x19.twitterx.v1.DeletedTweet0\x01B\x1dZ\x1btwitterx-api/pkg/twitterxpbb\x06proto3"
+
+var (
+	file_twitterx_v1_twitterx_proto_rawDescOnce sync.Once
+	file_twitterx_v1_twitterx_proto_rawDescData []byte
+)
+
+func file_twitterx_v1_twitterx_proto_rawDescGZIP() []byte {
+	file_twitterx_v1_twitterx_proto_rawDescOnce.Do(func() {
+		file_twitterx_v1_twitterx_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_twitterx_v1_twitterx_proto_rawDesc), len(file_twitterx_v1_twitterx_proto_rawDesc)))
+	})
+	return file_twitterx_v1_twitterx_proto_rawDescData
+}
+
+var file_twitterx_v1_twitterx_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_twitterx_v1_twitterx_proto_goTypes = []any{
+	(*GetUserRequest)(nil),         // 0: twitterx.v1.GetUserRequest
+	(*GetTweetRequest)(nil),        // 1: twitterx.v1.GetTweetRequest
+	(*BatchGetTweetsRequest)(nil),  // 2: twitterx.v1.BatchGetTweetsRequest
+	(*BatchGetTweetsResponse)(nil), // 3: twitterx.v1.BatchGetTweetsResponse
+	(*TweetResult)(nil),            // 4: twitterx.v1.TweetResult
+	(*Error)(nil),                  // 5: twitterx.v1.Error
+	(*GetTimelineRequest)(nil),     // 6: twitterx.v1.GetTimelineRequest
+	(*Timeline)(nil),               // 7: twitterx.v1.Timeline
+	(*WatchUserRequest)(nil),       // 8: twitterx.v1.WatchUserRequest
+	(*WatchDeletionsRequest)(nil),  // 9: twitterx.v1.WatchDeletionsRequest
+	(*DeletedTweet)(nil),           // 10: twitterx.v1.DeletedTweet
+	(*User)(nil),                   // 11: twitterx.v1.User
+	(*Verification)(nil),           // 12: twitterx.v1.Verification
+	(*Tweet)(nil),                  // 13: twitterx.v1.Tweet
+	(*Author)(nil),                 // 14: twitterx.v1.Author
+	(*MediaItem)(nil),              // 15: twitterx.v1.MediaItem
+	(*Poll)(nil),                   // 16: twitterx.v1.Poll
+	(*PollChoice)(nil),             // 17: twitterx.v1.PollChoice
+	(*Translation)(nil),            // 18: twitterx.v1.Translation
+	(*Entities)(nil),               // 19: twitterx.v1.Entities
+	(*Hashtag)(nil),                // 20: twitterx.v1.Hashtag
+	(*Mention)(nil),                // 21: twitterx.v1.Mention
+	(*UrlEntity)(nil),              // 22: twitterx.v1.UrlEntity
+	(*Cashtag)(nil),                // 23: twitterx.v1.Cashtag
+	(*timestamppb.Timestamp)(nil),  // 24: google.protobuf.Timestamp
+}
+var file_twitterx_v1_twitterx_proto_depIdxs = []int32{
+	4,  // 0: twitterx.v1.BatchGetTweetsResponse.tweets:type_name -> twitterx.v1.TweetResult
+	13, // 1: twitterx.v1.TweetResult.tweet:type_name -> twitterx.v1.Tweet
+	5,  // 2: twitterx.v1.TweetResult.error:type_name -> twitterx.v1.Error
+	24, // 3: twitterx.v1.DeletedTweet.created_at:type_name -> google.protobuf.Timestamp
+	24, // 4: twitterx.v1.DeletedTweet.last_seen:type_name -> google.protobuf.Timestamp
+	24, // 5: twitterx.v1.DeletedTweet.detected_at:type_name -> google.protobuf.Timestamp
+	19, // 6: twitterx.v1.DeletedTweet.entities:type_name -> twitterx.v1.Entities
+	13, // 7: twitterx.v1.DeletedTweet.tweet:type_name -> twitterx.v1.Tweet
+	12, // 8: twitterx.v1.User.verification:type_name -> twitterx.v1.Verification
+	14, // 9: twitterx.v1.Tweet.author:type_name -> twitterx.v1.Author
+	24, // 10: twitterx.v1.Tweet.created_at:type_name -> google.protobuf.Timestamp
+	15, // 11: twitterx.v1.Tweet.media:type_name -> twitterx.v1.MediaItem
+	16, // 12: twitterx.v1.Tweet.poll:type_name -> twitterx.v1.Poll
+	13, // 13: twitterx.v1.Tweet.quote:type_name -> twitterx.v1.Tweet
+	18, // 14: twitterx.v1.Tweet.translation:type_name -> twitterx.v1.Translation
+	19, // 15: twitterx.v1.Tweet.entities:type_name -> twitterx.v1.Entities
+	24, // 16: twitterx.v1.Poll.ends_at:type_name -> google.protobuf.Timestamp
+	17, // 17: twitterx.v1.Poll.choices:type_name -> twitterx.v1.PollChoice
+	20, // 18: twitterx.v1.Entities.hashtags:type_name -> twitterx.v1.Hashtag
+	21, // 19: twitterx.v1.Entities.mentions:type_name -> twitterx.v1.Mention
+	22, // 20: twitterx.v1.Entities.urls:type_name -> twitterx.v1.UrlEntity
+	23, // 21: twitterx.v1.Entities.cashtags:type_name -> twitterx.v1.Cashtag
+	0,  // 22: twitterx.v1.TwitterX.GetUser:input_type -> twitterx.v1.GetUserRequest
+	1,  // 23: twitterx.v1.TwitterX.GetTweet:input_type -> twitterx.v1.GetTweetRequest
+	2,  // 24: twitterx.v1.TwitterX.BatchGetTweets:input_type -> twitterx.v1.BatchGetTweetsRequest
+	6,  // 25: twitterx.v1.TwitterX.GetTimeline:input_type -> twitterx.v1.GetTimelineRequest
+	8,  // 26: twitterx.v1.TwitterX.WatchUser:input_type -> twitterx.v1.WatchUserRequest
+	9,  // 27: twitterx.v1.TwitterX.WatchDeletions:input_type -> twitterx.v1.WatchDeletionsRequest
+	11, // 28: twitterx.v1.TwitterX.GetUser:output_type -> twitterx.v1.User
+	13, // 29: twitterx.v1.TwitterX.GetTweet:output_type -> twitterx.v1.Tweet
+	3,  // 30: twitterx.v1.TwitterX.BatchGetTweets:output_type -> twitterx.v1.BatchGetTweetsResponse
+	7,  // 31: twitterx.v1.TwitterX.GetTimeline:output_type -> twitterx.v1.Timeline
+	13, // 32: twitterx.v1.TwitterX.WatchUser:output_type -> twitterx.v1.Tweet
+	10, // 33: twitterx.v1.TwitterX.WatchDeletions:output_type -> twitterx.v1.DeletedTweet
+	28, // [28:34] is the sub-list for method output_type
+	22, // [22:28] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_twitterx_v1_twitterx_proto_init() }
+func file_twitterx_v1_twitterx_proto_init() {
+	if File_twitterx_v1_twitterx_proto != nil {
+		return
+	}
+	file_twitterx_v1_twitterx_proto_msgTypes[4].OneofWrappers = []any{
+		(*TweetResult_Tweet)(nil),
+		(*TweetResult_Error)(nil),
+	}
+	file_twitterx_v1_twitterx_proto_msgTypes[11].OneofWrappers = []any{}
+	file_twitterx_v1_twitterx_proto_msgTypes[13].OneofWrappers = []any{}
+	file_twitterx_v1_twitterx_proto_msgTypes[14].OneofWrappers = []any{}
+	file_twitterx_v1_twitterx_proto_msgTypes[15].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_twitterx_v1_twitterx_proto_rawDesc), len(file_twitterx_v1_twitterx_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_twitterx_v1_twitterx_proto_goTypes,
+		DependencyIndexes: file_twitterx_v1_twitterx_proto_depIdxs,
+		MessageInfos:      file_twitterx_v1_twitterx_proto_msgTypes,
+	}.Build()
+	File_twitterx_v1_twitterx_proto = out.File
+	file_twitterx_v1_twitterx_proto_goTypes = nil
+	file_twitterx_v1_twitterx_proto_depIdxs = nil
+}
@@ -0,0 +1,338 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: twitterx/v1/twitterx.proto
+
+package twitterxpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TwitterX_GetUser_FullMethodName        = "/twitterx.v1.TwitterX/GetUser"
+	TwitterX_GetTweet_FullMethodName       = "/twitterx.v1.TwitterX/GetTweet"
+	TwitterX_BatchGetTweets_FullMethodName = "/twitterx.v1.TwitterX/BatchGetTweets"
+	TwitterX_GetTimeline_FullMethodName    = "/twitterx.v1.TwitterX/GetTimeline"
+	TwitterX_WatchUser_FullMethodName      = "/twitterx.v1.TwitterX/WatchUser"
+	TwitterX_WatchDeletions_FullMethodName = "/twitterx.v1.TwitterX/WatchDeletions"
+)
+
+// TwitterXClient is the client API for TwitterX service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TwitterX serves the TwitterX API over gRPC. Messages mirror the JSON
+// responses of the REST API.
+type TwitterXClient interface {
+	// GetUser returns the profile of a user
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// GetTweet returns a tweet
+	GetTweet(ctx context.Context, in *GetTweetRequest, opts ...grpc.CallOption) (*Tweet, error)
+	// BatchGetTweets returns up to 100 tweets in request order
+	BatchGetTweets(ctx context.Context, in *BatchGetTweetsRequest, opts ...grpc.CallOption) (*BatchGetTweetsResponse, error)
+	// GetTimeline returns the recent tweet IDs of a user, newest first
+	GetTimeline(ctx context.Context, in *GetTimelineRequest, opts ...grpc.CallOption) (*Timeline, error)
+	// WatchUser streams the new tweets of a user as they are posted
+	WatchUser(ctx context.Context, in *WatchUserRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Tweet], error)
+	// WatchDeletions streams the deleted tweets of the accounts watched by the
+	// server as they are detected
+	WatchDeletions(ctx context.Context, in *WatchDeletionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeletedTweet], error)
+}
+
+type twitterXClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTwitterXClient(cc grpc.ClientConnInterface) TwitterXClient {
+	return &twitterXClient{cc}
+}
+
+func (c *twitterXClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, TwitterX_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twitterXClient) GetTweet(ctx context.Context, in *GetTweetRequest, opts ...grpc.CallOption) (*Tweet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tweet)
+	err := c.cc.Invoke(ctx, TwitterX_GetTweet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twitterXClient) BatchGetTweets(ctx context.Context, in *BatchGetTweetsRequest, opts ...grpc.CallOption) (*BatchGetTweetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetTweetsResponse)
+	err := c.cc.Invoke(ctx, TwitterX_BatchGetTweets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twitterXClient) GetTimeline(ctx context.Context, in *GetTimelineRequest, opts ...grpc.CallOption) (*Timeline, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Timeline)
+	err := c.cc.Invoke(ctx, TwitterX_GetTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twitterXClient) WatchUser(ctx context.Context, in *WatchUserRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Tweet], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TwitterX_ServiceDesc.Streams[0], TwitterX_WatchUser_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchUserRequest, Tweet]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TwitterX_WatchUserClient = grpc.ServerStreamingClient[Tweet]
+
+func (c *twitterXClient) WatchDeletions(ctx context.Context, in *WatchDeletionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeletedTweet], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TwitterX_ServiceDesc.Streams[1], TwitterX_WatchDeletions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchDeletionsRequest, DeletedTweet]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TwitterX_WatchDeletionsClient = grpc.ServerStreamingClient[DeletedTweet]
+
+// TwitterXServer is the server API for TwitterX service.
+// All implementations must embed UnimplementedTwitterXServer
+// for forward compatibility.
+//
+// TwitterX serves the TwitterX API over gRPC. Messages mirror the JSON
+// responses of the REST API.
+type TwitterXServer interface {
+	// GetUser returns the profile of a user
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// GetTweet returns a tweet
+	GetTweet(context.Context, *GetTweetRequest) (*Tweet, error)
+	// BatchGetTweets returns up to 100 tweets in request order
+	BatchGetTweets(context.Context, *BatchGetTweetsRequest) (*BatchGetTweetsResponse, error)
+	// GetTimeline returns the recent tweet IDs of a user, newest first
+	GetTimeline(context.Context, *GetTimelineRequest) (*Timeline, error)
+	// WatchUser streams the new tweets of a user as they are posted
+	WatchUser(*WatchUserRequest, grpc.ServerStreamingServer[Tweet]) error
+	// WatchDeletions streams the deleted tweets of the accounts watched by the
+	// server as they are detected
+	WatchDeletions(*WatchDeletionsRequest, grpc.ServerStreamingServer[DeletedTweet]) error
+	mustEmbedUnimplementedTwitterXServer()
+}
+
+// UnimplementedTwitterXServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTwitterXServer struct{}
+
+func (UnimplementedTwitterXServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedTwitterXServer) GetTweet(context.Context, *GetTweetRequest) (*Tweet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTweet not implemented")
+}
+func (UnimplementedTwitterXServer) BatchGetTweets(context.Context, *BatchGetTweetsRequest) (*BatchGetTweetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetTweets not implemented")
+}
+func (UnimplementedTwitterXServer) GetTimeline(context.Context, *GetTimelineRequest) (*Timeline, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTimeline not implemented")
+}
+func (UnimplementedTwitterXServer) WatchUser(*WatchUserRequest, grpc.ServerStreamingServer[Tweet]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchUser not implemented")
+}
+func (UnimplementedTwitterXServer) WatchDeletions(*WatchDeletionsRequest, grpc.ServerStreamingServer[DeletedTweet]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDeletions not implemented")
+}
+func (UnimplementedTwitterXServer) mustEmbedUnimplementedTwitterXServer() {}
+func (UnimplementedTwitterXServer) testEmbeddedByValue()                  {}
+
+// UnsafeTwitterXServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TwitterXServer will
+// result in compilation errors.
+type UnsafeTwitterXServer interface {
+	mustEmbedUnimplementedTwitterXServer()
+}
+
+func RegisterTwitterXServer(s grpc.ServiceRegistrar, srv TwitterXServer) {
+	// If the following call pancis, it indicates UnimplementedTwitterXServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TwitterX_ServiceDesc, srv)
+}
+
+func _TwitterX_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TwitterXServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TwitterX_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TwitterXServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TwitterX_GetTweet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTweetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TwitterXServer).GetTweet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TwitterX_GetTweet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TwitterXServer).GetTweet(ctx, req.(*GetTweetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TwitterX_BatchGetTweets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetTweetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TwitterXServer).BatchGetTweets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TwitterX_BatchGetTweets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TwitterXServer).BatchGetTweets(ctx, req.(*BatchGetTweetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TwitterX_GetTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TwitterXServer).GetTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TwitterX_GetTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TwitterXServer).GetTimeline(ctx, req.(*GetTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TwitterX_WatchUser_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUserRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwitterXServer).WatchUser(m, &grpc.GenericServerStream[WatchUserRequest, Tweet]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TwitterX_WatchUserServer = grpc.ServerStreamingServer[Tweet]
+
+func _TwitterX_WatchDeletions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDeletionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwitterXServer).WatchDeletions(m, &grpc.GenericServerStream[WatchDeletionsRequest, DeletedTweet]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TwitterX_WatchDeletionsServer = grpc.ServerStreamingServer[DeletedTweet]
+
+// TwitterX_ServiceDesc is the grpc.ServiceDesc for TwitterX service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TwitterX_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "twitterx.v1.TwitterX",
+	HandlerType: (*TwitterXServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUser",
+			Handler:    _TwitterX_GetUser_Handler,
+		},
+		{
+			MethodName: "GetTweet",
+			Handler:    _TwitterX_GetTweet_Handler,
+		},
+		{
+			MethodName: "BatchGetTweets",
+			Handler:    _TwitterX_BatchGetTweets_Handler,
+		},
+		{
+			MethodName: "GetTimeline",
+			Handler:    _TwitterX_GetTimeline_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUser",
+			Handler:       _TwitterX_WatchUser_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchDeletions",
+			Handler:       _TwitterX_WatchDeletions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "twitterx/v1/twitterx.proto",
+}
@@ -6,9 +6,9 @@ document.addEventListener('DOMContentLoaded', async () => {
     const profileContent = document.getElementById('profileContent');
     const errorText = document.getElementById('errorText');
 
-    // Get username from URL path
+    // Get username from URL path (/{username} or /{username}/status/{id})
     const path = window.location.pathname;
-    const username = decodeURIComponent(path.substring(1));
+    const username = decodeURIComponent(path.split('/')[1] || '');
 
     if (!username) {
         window.location.href = '/';
@@ -164,6 +164,7 @@ document.addEventListener('DOMContentLoaded', async () => {
 
             const data = await response.json();
             const tweetIDs = data.tweet_ids || [];
+            const pinned = new Set((data.tweets || []).filter(t => t.pinned).map(t => t.id));
 
             if (tweetIDs.length === 0) {
                 tweetsLoading.classList.add('d-none');
@@ -179,7 +180,8 @@ document.addEventListener('DOMContentLoaded', async () => {
                         const tweetResponse = await fetch(`/api/users/${encodeURIComponent(username)}/tweets/${id}`);
                         if (!tweetResponse.ok) return null;
                         const tweetData = await tweetResponse.json();
-                        return tweetData.tweet || null;
+                        if (!tweetData.tweet) return null;
+                        return { ...tweetData.tweet, pinned: pinned.has(id) };
                     } catch {
                         return null;
                     }
@@ -216,6 +218,14 @@ document.addEventListener('DOMContentLoaded', async () => {
         return `
             <div class="card bg-dark border-secondary mb-3">
                 <div class="card-body">
+                    ${tweet.pinned ? `
+                    <div class="text-secondary small fw-bold mb-1">
+                        <svg width="14" height="14" viewBox="0 0 24 24" fill="currentColor" class="me-1">
+                            <path d="M16 12V4h1V2H7v2h1v8l-2 2v2h5.2v6h1.6v-6H18v-2l-2-2z"/>
+                        </svg>
+                        Pinned
+                    </div>
+                    ` : ''}
                     <div class="d-flex justify-content-between align-items-start mb-2">
                         <small class="text-secondary">${date}</small>
                         <a href="${tweet.url}" target="_blank" class="text-secondary text-decoration-none">
@@ -224,7 +234,7 @@ document.addEventListener('DOMContentLoaded', async () => {
                             </svg>
                         </a>
                     </div>
-                    <p class="card-text mb-2" style="white-space: pre-wrap;">${escapeHtml(tweet.text)}</p>
+                    <p class="card-text mb-2" style="white-space: pre-wrap;">${formatText(tweet)}</p>
                     ${mediaHTML}
                     ${quoteHTML}
                     <div class="d-flex gap-4 text-secondary small mt-3">
@@ -301,7 +311,7 @@ document.addEventListener('DOMContentLoaded', async () => {
                     <span class="fw-bold">${escapeHtml(quote.author.name)}</span>
                     <span class="text-secondary">@${quote.author.screen_name}</span>
                 </div>
-                <p class="mb-0 small" style="white-space: pre-wrap;">${escapeHtml(quote.text)}</p>
+                <p class="mb-0 small" style="white-space: pre-wrap;">${formatText(quote)}</p>
             </div>
         `;
     }
@@ -340,6 +350,30 @@ document.addEventListener('DOMContentLoaded', async () => {
         }
     }
 
+    // Escapes the tweet text and links its entities. Entity offsets count
+    // code points, as Array.from splits the text.
+    function formatText(tweet) {
+        const chars = Array.from(tweet.text || '');
+        const entities = tweet.entities || {};
+        const links = [
+            ...(entities.urls || []).map(e => ({ ...e, href: e.expanded_url, display: e.display_url })),
+            ...(entities.mentions || []).map(e => ({ ...e, href: `https://x.com/${e.screen_name}` })),
+            ...(entities.hashtags || []).map(e => ({ ...e, href: `https://x.com/hashtag/${encodeURIComponent(e.tag)}` })),
+            ...(entities.cashtags || []).map(e => ({ ...e, href: `https://x.com/search?q=${encodeURIComponent('$' + e.tag)}` })),
+        ].sort((a, b) => a.start - b.start);
+
+        let html = '';
+        let last = 0;
+        for (const link of links) {
+            if (link.start < last || !/^https?:\/\//.test(link.href)) continue;
+            const display = link.display || chars.slice(link.start, link.end).join('');
+            html += escapeHtml(chars.slice(last, link.start).join(''));
+            html += `<a href="${escapeHtml(link.href).replace(/"/g, '&quot;')}" target="_blank" rel="noopener noreferrer">${escapeHtml(display)}</a>`;
+            last = link.end;
+        }
+        return html + escapeHtml(chars.slice(last).join(''));
+    }
+
     function escapeHtml(text) {
         const div = document.createElement('div');
         div.textContent = text;